@@ -0,0 +1,85 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Metrics which can be adjusted.
+const (
+	MetricLikes    = "likes"
+	MetricDislikes = "dislikes"
+)
+
+// Actions recorded in the audit log for adjustments.
+const (
+	AuditActionAdjustmentCreate = "adjustment.create"
+	AuditActionAdjustmentRevert = "adjustment.revert"
+)
+
+// Adjustment represents a manual correction of a Member's received likes or dislikes within a month.
+// Adjustments are kept apart from the recorded Reactions so they can be listed and reverted.
+type Adjustment struct {
+	ID         int        `json:"id"`
+	TeamID     string     `json:"teamID"`
+	Date       MonthYear  `json:"date"`
+	SlackUID   string     `json:"slackUID"`
+	Metric     string     `json:"metric"`
+	Delta      int        `json:"delta"`
+	Reason     string     `json:"reason"`
+	Actor      string     `json:"actor"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevertedBy string     `json:"revertedBy,omitempty"`
+	RevertedAt *time.Time `json:"revertedAt,omitempty"`
+}
+
+// Validate returns an error if the adjustment contains invalid fields.
+// This only performs basic validation.
+func (a *Adjustment) Validate() error {
+	if a.TeamID == "" {
+		return fmt.Errorf("slack team ID required %w", ErrInvalid)
+	}
+	if a.SlackUID == "" {
+		return fmt.Errorf("slack user ID required %w", ErrInvalid)
+	}
+	if _, err := a.Date.Time(); err != nil {
+		return fmt.Errorf("valid date required %w", ErrInvalid)
+	}
+	if a.Metric != MetricLikes && a.Metric != MetricDislikes {
+		return fmt.Errorf("metric must be %q or %q %w", MetricLikes, MetricDislikes, ErrInvalid)
+	}
+	if a.Delta == 0 {
+		return fmt.Errorf("non-zero delta required %w", ErrInvalid)
+	}
+	if a.Reason == "" {
+		return fmt.Errorf("reason required %w", ErrInvalid)
+	}
+	if a.Actor == "" {
+		return fmt.Errorf("actor required %w", ErrInvalid)
+	}
+	return nil
+}
+
+// AdjustmentFilter represents a filter passed to FindAdjustments().
+type AdjustmentFilter struct {
+	TeamID string
+
+	// Restricts the adjustments to a month or member if set.
+	Date     MonthYear
+	SlackUID string
+}
+
+// AdjustmentService represents a service for managing Adjustments.
+type AdjustmentService interface {
+	// CreateAdjustment records an Adjustment and applies its delta to the Member's counts.
+	// Returns ErrInvalid if the counts would become negative.
+	CreateAdjustment(ctx context.Context, a *Adjustment) error
+
+	// FindAdjustments retrieves the Adjustments of a workspace matching the filter, oldest first.
+	FindAdjustments(ctx context.Context, filter AdjustmentFilter) ([]*Adjustment, error)
+
+	// RevertAdjustment marks an Adjustment as reverted by actor and removes its delta from the Member's counts.
+	// Returns ErrNotFound if the adjustment does not exist and ErrConflict if it has already been reverted.
+	RevertAdjustment(ctx context.Context, id int, actor string) (*Adjustment, error)
+}
@@ -0,0 +1,91 @@
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditActionMonthlyUpdate      = "monthly_update.post"
+	AuditActionYearInReview       = "year_in_review.post"
+	AuditActionWorkspaceInstall   = "workspace.install"
+	AuditActionWorkspaceUninstall = "workspace.uninstall"
+	AuditActionRecompute          = "members.recompute"
+	AuditActionBackfill           = "reactions.backfill"
+	AuditActionImport             = "reactions.import"
+	AuditActionRotateKeys         = "encryption_keys.rotate"
+)
+
+// DefaultAuditLimit is the number of entries returned by FindAuditEntries() if no limit is given.
+const DefaultAuditLimit = 100
+
+// AuditEntry represents an administrative action recorded in the audit log.
+// Before and After hold the affected values as JSON, if any.
+type AuditEntry struct {
+	ID        int             `json:"id"`
+	TeamID    string          `json:"teamID,omitempty"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// NewAuditEntry returns a new AuditEntry with before and after encoded as JSON.
+// Nil values are left empty.
+func NewAuditEntry(teamID string, actor string, action string, target string, before any, after any) (*AuditEntry, error) {
+	e := &AuditEntry{TeamID: teamID, Actor: actor, Action: action, Target: target}
+	var err error
+	if before != nil {
+		if e.Before, err = json.Marshal(before); err != nil {
+			return nil, fmt.Errorf("NewAuditEntry before: %w", err)
+		}
+	}
+	if after != nil {
+		if e.After, err = json.Marshal(after); err != nil {
+			return nil, fmt.Errorf("NewAuditEntry after: %w", err)
+		}
+	}
+	return e, nil
+}
+
+// Validate returns an error if the entry contains invalid fields.
+// This only performs basic validation.
+func (e *AuditEntry) Validate() error {
+	if e.Actor == "" {
+		return fmt.Errorf("actor required %w", ErrInvalid)
+	}
+	if e.Action == "" {
+		return fmt.Errorf("action required %w", ErrInvalid)
+	}
+	return nil
+}
+
+// AuditFilter represents a filter passed to FindAuditEntries().
+type AuditFilter struct {
+	// Restricts the entries to a workspace, actor or action if set.
+	TeamID string
+	Actor  string
+	Action string
+
+	// Restricts the entries to the time range [From, To). A zero From has no lower
+	// bound, while a zero To includes everything up to now.
+	From time.Time
+	To   time.Time
+
+	// Maximum number of entries to return. Defaults to DefaultAuditLimit.
+	Limit int
+}
+
+// AuditService represents a service for recording administrative actions.
+type AuditService interface {
+	// CreateAuditEntry records an administrative action.
+	CreateAuditEntry(ctx context.Context, e *AuditEntry) error
+
+	// FindAuditEntries retrieves the entries matching the filter, the most recent first.
+	FindAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error)
+}
@@ -0,0 +1,27 @@
+package statsd
+
+import (
+	"context"
+	"time"
+)
+
+// BackfillCheckpoint records how far the backfill of a Slack channel over a range of months has progressed.
+type BackfillCheckpoint struct {
+	TeamID    string    `json:"teamID"`
+	ChannelID string    `json:"channelID"`
+	From      MonthYear `json:"from"`
+	To        MonthYear `json:"to"`
+	Cursor    string    `json:"cursor"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BackfillService represents a service for managing BackfillCheckpoints.
+type BackfillService interface {
+	// FindBackfillCheckpoint retrieves the checkpoint of a channel for the given range of months.
+	// Returns ErrNotFound if the backfill has not been started.
+	FindBackfillCheckpoint(ctx context.Context, teamID string, channelID string, from MonthYear, to MonthYear) (*BackfillCheckpoint, error)
+
+	// SaveBackfillCheckpoint creates or replaces the checkpoint of a channel.
+	SaveBackfillCheckpoint(ctx context.Context, c *BackfillCheckpoint) error
+}
@@ -0,0 +1,123 @@
+package statsd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ChannelGroups maps the names of channel groups to the IDs of the Slack channels they consist of,
+// so that a leaderboard can cover several channels, e.g. every channel of a team.
+type ChannelGroups map[string][]string
+
+// ParseChannelGroups parses channel groups in the form of `engineering=C1ZN1SE2N,C2ZN1SE2N;random=C3ZN1SE2N`.
+func ParseChannelGroups(s string) (ChannelGroups, error) {
+	groups := make(ChannelGroups)
+	for _, rawGroup := range strings.Split(s, ";") {
+		if strings.TrimSpace(rawGroup) == "" {
+			continue
+		}
+		name, rawChannels, ok := strings.Cut(rawGroup, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid channel group %q %w", rawGroup, ErrInvalid)
+		}
+		var channelIDs []string
+		for _, channelID := range strings.Split(rawChannels, ",") {
+			if channelID = strings.TrimSpace(channelID); channelID != "" {
+				channelIDs = append(channelIDs, channelID)
+			}
+		}
+		if len(channelIDs) == 0 {
+			return nil, fmt.Errorf("channel group %q requires a channel %w", name, ErrInvalid)
+		}
+		groups[name] = channelIDs
+	}
+	return groups, nil
+}
+
+// Channels returns the channels of the group named scope. If no such group exists, scope is
+// taken to be the ID of a single channel.
+func (g ChannelGroups) Channels(scope string) []string {
+	if channelIDs, ok := g[scope]; ok {
+		channelIDs = append([]string(nil), channelIDs...)
+		sort.Strings(channelIDs)
+		return channelIDs
+	}
+	return []string{scope}
+}
+
+// ChannelType represents the kind of conversation a Slack channel is.
+type ChannelType string
+
+// Kinds of Slack channels.
+const (
+	ChannelPublic  ChannelType = "public"
+	ChannelPrivate ChannelType = "private"
+	ChannelDM      ChannelType = "dm"
+	ChannelMPIM    ChannelType = "mpim"
+)
+
+// ParseChannelTypes parses a comma separated list of channel types, e.g. `public,private`.
+func ParseChannelTypes(s string) ([]ChannelType, error) {
+	var types []ChannelType
+	for _, rawType := range strings.Split(s, ",") {
+		switch typ := ChannelType(strings.TrimSpace(rawType)); typ {
+		case "":
+		case ChannelPublic, ChannelPrivate, ChannelDM, ChannelMPIM:
+			types = append(types, typ)
+		default:
+			return nil, fmt.Errorf("channel type must be %s, %s, %s or %s %w", ChannelPublic, ChannelPrivate, ChannelDM, ChannelMPIM, ErrInvalid)
+		}
+	}
+	return types, nil
+}
+
+// Reasons for which ChannelFilter rejects a channel.
+const (
+	ChannelExcluded    = "excluded"
+	ChannelNotIncluded = "not_included"
+	ChannelTypeIgnored = "type"
+
+	// The type of the channel couldn't be looked up, so the channel is treated as rejected.
+	ChannelTypeUnknown = "type_unknown"
+)
+
+// ChannelFilter decides in which channels reactions are counted. A zero filter counts every channel.
+type ChannelFilter struct {
+	// Channels whose reactions are never counted.
+	Exclude []string
+
+	// If set, only the reactions within these channels are counted, regardless of their type.
+	Include []string
+
+	// If set, only the reactions within channels of these types are counted.
+	Types []ChannelType
+}
+
+// Allows reports whether reactions within the channel are counted. If not, the reason is returned.
+// The type of the channel is only looked up by calling channelType if the filter restricts types
+// and the channel isn't included explicitly.
+func (f ChannelFilter) Allows(channelID string, channelType func() (ChannelType, error)) (ok bool, reason string, err error) {
+	if slices.Contains(f.Exclude, channelID) {
+		return false, ChannelExcluded, nil
+	}
+	if len(f.Include) > 0 {
+		if !slices.Contains(f.Include, channelID) {
+			return false, ChannelNotIncluded, nil
+		}
+		return true, "", nil
+	}
+	if len(f.Types) == 0 {
+		return true, "", nil
+	}
+	typ, err := channelType()
+	if err != nil {
+		return false, "", err
+	}
+	if !slices.Contains(f.Types, typ) {
+		return false, ChannelTypeIgnored, nil
+	}
+	return true, "", nil
+}
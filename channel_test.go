@@ -0,0 +1,96 @@
+package statsd_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+func TestParseChannelGroups(t *testing.T) {
+	// Ensure groups are parsed and resolved to their channels.
+	t.Run("OK", func(t *testing.T) {
+		groups, err := statsd.ParseChannelGroups("engineering=C2ZN1SE2N, C1ZN1SE2N; random=C3ZN1SE2N;")
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := groups.Channels("engineering"), []string{"C1ZN1SE2N", "C2ZN1SE2N"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Channels=%v, want %v", got, want)
+		} else if got, want := groups.Channels("C9ZN1SE2N"), []string{"C9ZN1SE2N"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Channels=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure no groups are configured by an empty string.
+	t.Run("Empty", func(t *testing.T) {
+		if groups, err := statsd.ParseChannelGroups(""); err != nil {
+			t.Fatal(err)
+		} else if len(groups) != 0 {
+			t.Fatalf("unexpected groups: %v", groups)
+		}
+	})
+
+	// Ensure malformed groups are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		for _, s := range []string{"engineering", "=C1ZN1SE2N", "engineering=,"} {
+			if _, err := statsd.ParseChannelGroups(s); !errors.Is(err, statsd.ErrInvalid) {
+				t.Fatalf("%q: unexpected error: %#v", s, err)
+			}
+		}
+	})
+}
+
+func TestChannelFilter_Allows(t *testing.T) {
+	types := map[string]statsd.ChannelType{
+		"C1ZN1SE2N": statsd.ChannelPublic,
+		"C2ZN1SE2N": statsd.ChannelPrivate,
+		"D1ZN1SE2N": statsd.ChannelDM,
+	}
+	for _, tt := range []struct {
+		name      string
+		filter    statsd.ChannelFilter
+		channelID string
+		ok        bool
+		reason    string
+	}{
+		{"Zero", statsd.ChannelFilter{}, "D1ZN1SE2N", true, ""},
+		{"Excluded", statsd.ChannelFilter{Exclude: []string{"C1ZN1SE2N"}}, "C1ZN1SE2N", false, statsd.ChannelExcluded},
+		{"ExcludedOverInclude", statsd.ChannelFilter{Include: []string{"C1ZN1SE2N"}, Exclude: []string{"C1ZN1SE2N"}}, "C1ZN1SE2N", false, statsd.ChannelExcluded},
+		{"NotIncluded", statsd.ChannelFilter{Include: []string{"C1ZN1SE2N"}}, "C2ZN1SE2N", false, statsd.ChannelNotIncluded},
+		{"IncludedOverType", statsd.ChannelFilter{Include: []string{"C2ZN1SE2N"}, Types: []statsd.ChannelType{statsd.ChannelPublic}}, "C2ZN1SE2N", true, ""},
+		{"Type", statsd.ChannelFilter{Types: []statsd.ChannelType{statsd.ChannelPublic, statsd.ChannelPrivate}}, "C2ZN1SE2N", true, ""},
+		{"TypeIgnored", statsd.ChannelFilter{Types: []statsd.ChannelType{statsd.ChannelPublic}}, "D1ZN1SE2N", false, statsd.ChannelTypeIgnored},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason, err := tt.filter.Allows(tt.channelID, func() (statsd.ChannelType, error) {
+				return types[tt.channelID], nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			} else if ok != tt.ok || reason != tt.reason {
+				t.Fatalf("Allows=%v, %q, want %v, %q", ok, reason, tt.ok, tt.reason)
+			}
+		})
+	}
+
+	// Ensure the channel type is only looked up if the filter restricts types.
+	t.Run("NoLookup", func(t *testing.T) {
+		lookup := func() (statsd.ChannelType, error) { return "", errors.New("unexpected lookup") }
+		if ok, _, err := (statsd.ChannelFilter{Exclude: []string{"C9ZN1SE2N"}}).Allows("C1ZN1SE2N", lookup); err != nil {
+			t.Fatal(err)
+		} else if !ok {
+			t.Fatal("expected channel to be allowed")
+		}
+	})
+}
+
+func TestParseChannelTypes(t *testing.T) {
+	if types, err := statsd.ParseChannelTypes("public, mpim"); err != nil {
+		t.Fatal(err)
+	} else if got, want := types, []statsd.ChannelType{statsd.ChannelPublic, statsd.ChannelMPIM}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("types=%v, want %v", got, want)
+	}
+	if _, err := statsd.ParseChannelTypes("group"); !errors.Is(err, statsd.ErrInvalid) {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
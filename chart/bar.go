@@ -0,0 +1,62 @@
+package chart
+
+import (
+	"image"
+	"strconv"
+)
+
+// Layout of bar charts in pixels.
+const (
+	barChartWidth  = 640
+	barHeight      = 24
+	barGap         = 8
+	barLabelLength = 16
+)
+
+// Bar represents a labelled value of a bar chart.
+type Bar struct {
+	Label string
+	Value int
+}
+
+// BarChart renders bars as horizontal bars below each other, their lengths in proportion to the
+// highest value. Labels longer than 16 characters are truncated.
+func BarChart(title string, bars []Bar) image.Image {
+	lineHeight := glyphHeight*textScale + lineGap
+	labels := make([]string, len(bars))
+	var labelWidth, valueWidth, highest int
+	for i, bar := range bars {
+		labels[i] = truncate(bar.Label, barLabelLength)
+		labelWidth = max(labelWidth, textWidth(labels[i], textScale))
+		valueWidth = max(valueWidth, textWidth(strconv.Itoa(bar.Value), textScale))
+		highest = max(highest, bar.Value)
+	}
+	left := margin + labelWidth + lineGap
+	length := barChartWidth - left - lineGap - valueWidth - margin
+
+	height := 2*margin + lineHeight + len(bars)*(barHeight+barGap)
+	img := newCanvas(barChartWidth, height)
+	drawText(img, margin, margin, title, textScale, textColor)
+
+	top := margin + lineHeight
+	textOffset := (barHeight - glyphHeight*textScale) / 2
+	for i, bar := range bars {
+		y := top + i*(barHeight+barGap)
+		drawText(img, margin, y+textOffset, labels[i], textScale, textColor)
+		var w int
+		if highest > 0 && bar.Value > 0 {
+			w = max(1, length*bar.Value/highest)
+			fillRect(img, image.Rect(left, y, left+w, y+barHeight), fullColor)
+		}
+		drawText(img, left+w+lineGap, y+textOffset, strconv.Itoa(bar.Value), textScale, mutedColor)
+	}
+	return img
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
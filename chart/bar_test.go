@@ -0,0 +1,24 @@
+package chart_test
+
+import (
+	"testing"
+
+	"github.com/ddritzenhoff/statsd/chart"
+)
+
+func TestBarChart(t *testing.T) {
+	// Ensure bars are drawn in proportion to the highest value and long labels are truncated.
+	t.Run("OK", func(t *testing.T) {
+		AssertGolden(t, "bar", chart.BarChart("Most likes received, September 2024", []chart.Bar{
+			{Label: "ada.lovelace", Value: 42},
+			{Label: "grace_hopper", Value: 35},
+			{Label: "linus", Value: 12},
+			{Label: "a-very-long-user-name-indeed", Value: 0},
+		}))
+	})
+
+	// Ensure a chart without bars only shows its title.
+	t.Run("Empty", func(t *testing.T) {
+		AssertGolden(t, "bar_empty", chart.BarChart("Most likes received, September 2024", nil))
+	})
+}
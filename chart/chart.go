@@ -0,0 +1,55 @@
+// Package chart renders statsd statistics as PNG images using only the standard library, so
+// that they can be attached to Slack posts.
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// Colors shared by every chart.
+var (
+	backgroundColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	textColor       = color.RGBA{0x1d, 0x1c, 0x1d, 0xff}
+	mutedColor      = color.RGBA{0x61, 0x60, 0x61, 0xff}
+	emptyColor      = color.RGBA{0xeb, 0xed, 0xf0, 0xff}
+	fullColor       = color.RGBA{0x21, 0x6e, 0x39, 0xff}
+)
+
+// Layout shared by every chart in pixels.
+const (
+	margin    = 16
+	textScale = 2
+	lineGap   = 8
+)
+
+// EncodePNG writes img to w as a PNG image.
+func EncodePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+// newCanvas returns an image of the given size filled with the background color.
+func newCanvas(width int, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Bounds(), backgroundColor)
+	return img
+}
+
+// fillRect fills r with c.
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// shade returns the color between emptyColor and fullColor in proportion to n of highest.
+func shade(n int, highest int) color.RGBA {
+	if highest <= 0 || n <= 0 {
+		return emptyColor
+	}
+	mix := func(a, b uint8) uint8 {
+		return uint8(int(a) + (int(b)-int(a))*n/highest)
+	}
+	return color.RGBA{mix(emptyColor.R, fullColor.R), mix(emptyColor.G, fullColor.G), mix(emptyColor.B, fullColor.B), 0xff}
+}
@@ -0,0 +1,55 @@
+package chart_test
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd/chart"
+)
+
+// update rewrites the golden images with the rendered ones: go test ./chart -update
+var update = flag.Bool("update", false, "update golden images")
+
+// AssertGolden fails the test unless img matches the golden image testdata/<name>.png pixel by pixel.
+func AssertGolden(tb testing.TB, name string, img image.Image) {
+	tb.Helper()
+	path := filepath.Join("testdata", name+".png")
+	var buf bytes.Buffer
+	if err := chart.EncodePNG(&buf, img); err != nil {
+		tb.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if got, want := img.Bounds(), want.Bounds(); got != want {
+		tb.Fatalf("Bounds=%v, want %v", got, want)
+	}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r0, g0, b0, a0 := img.At(x, y).RGBA()
+			r1, g1, b1, a1 := want.At(x, y).RGBA()
+			if r0 != r1 || g0 != g1 || b0 != b1 || a0 != a1 {
+				tb.Fatalf("pixel (%d, %d) differs from %s, rerun with -update if the change is intended", x, y, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+// Layout of the heatmap in pixels.
+const (
+	heatmapCell = 24
+	heatmapGap  = 2
+)
+
+// heatmapWeekdays labels the rows of the heatmap, starting with Monday like statsd.Heatmap.
+var heatmapWeekdays = [7]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// Heatmap renders the reactions of a heatmap as a grid of weekdays by hours, each cell shaded
+// in proportion to the busiest hour.
+func Heatmap(h *statsd.Heatmap) image.Image {
+	lineHeight := glyphHeight*textScale + lineGap
+	labelWidth := textWidth("Mon", textScale) + lineGap
+	gridWidth := 24*(heatmapCell+heatmapGap) - heatmapGap
+	gridHeight := 7*(heatmapCell+heatmapGap) - heatmapGap
+
+	width := 2*margin + labelWidth + gridWidth
+	height := 2*margin + 2*lineHeight + gridHeight + lineHeight
+	img := newCanvas(width, height)
+
+	drawText(img, margin, margin, "Reactions by hour, "+h.Period.Title(), textScale, textColor)
+	drawText(img, margin, margin+lineHeight, fmt.Sprintf("%d reactions, at most %d within an hour", h.Total(), h.Max()), textScale, mutedColor)
+
+	top := margin + 2*lineHeight
+	left := margin + labelWidth
+	highest := h.Max()
+	for day, hours := range h.Counts {
+		y := top + day*(heatmapCell+heatmapGap)
+		drawText(img, margin, y+(heatmapCell-glyphHeight*textScale)/2, heatmapWeekdays[day], textScale, mutedColor)
+		for hour, n := range hours {
+			x := left + hour*(heatmapCell+heatmapGap)
+			fillRect(img, image.Rect(x, y, x+heatmapCell, y+heatmapCell), shade(n, highest))
+		}
+	}
+	// Every third hour is labelled below the grid.
+	for hour := 0; hour < 24; hour += 3 {
+		label := fmt.Sprintf("%02d", hour)
+		x := left + hour*(heatmapCell+heatmapGap) + (heatmapCell-textWidth(label, textScale))/2
+		drawText(img, x, top+gridHeight+lineGap, label, textScale, mutedColor)
+	}
+	return img
+}
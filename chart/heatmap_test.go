@@ -0,0 +1,36 @@
+package chart_test
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/chart"
+)
+
+func TestHeatmap(t *testing.T) {
+	// Ensure only the busiest hour is drawn in the darkest shade.
+	p, err := statsd.ParsePeriod("05-2006", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &statsd.Heatmap{TeamID: "T1ZN1SE2N", Period: p}
+	h.Add(time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC), 4)
+	h.Add(time.Date(2006, time.May, 16, 10, 0, 0, 0, time.UTC), 2)
+
+	img := chart.Heatmap(h)
+	var darkest int
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == (color.RGBA{0x21, 0x6e, 0x39, 0xff}) {
+				darkest++
+			}
+		}
+	}
+	if got, want := darkest, 24*24; got != want {
+		t.Fatalf("darkest pixels=%d, want %d", got, want)
+	}
+	AssertGolden(t, "heatmap", img)
+}
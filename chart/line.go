@@ -0,0 +1,135 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+)
+
+// Layout of line charts in pixels.
+const (
+	lineChartWidth  = 640
+	lineChartHeight = 200
+	lineWidth       = 3
+	legendSwatch    = 14
+)
+
+// seriesColors are the colors of the series of a line chart in order. They repeat if there are
+// more series.
+var seriesColors = []color.RGBA{
+	fullColor,
+	{0xcf, 0x22, 0x2e, 0xff},
+	{0x09, 0x69, 0xda, 0xff},
+	{0xbf, 0x87, 0x00, 0xff},
+}
+
+// Series represents a named line of a line chart with a value for each label.
+type Series struct {
+	Name   string
+	Values []int
+}
+
+// LineChart renders series as lines over labels spread evenly along the horizontal axis, which
+// starts at zero. Labels are skipped as needed to keep them apart.
+func LineChart(title string, labels []string, series []Series) image.Image {
+	lineHeight := glyphHeight*textScale + lineGap
+	highest := 1
+	for _, s := range series {
+		for _, v := range s.Values {
+			highest = max(highest, v)
+		}
+	}
+	axisWidth := textWidth(strconv.Itoa(highest), textScale) + lineGap
+
+	width := lineChartWidth
+	height := 2*margin + 2*lineHeight + lineChartHeight + lineHeight
+	img := newCanvas(width, height)
+	drawText(img, margin, margin, title, textScale, textColor)
+
+	// Legend
+	x := margin
+	for i, s := range series {
+		c := seriesColors[i%len(seriesColors)]
+		fillRect(img, image.Rect(x, margin+lineHeight, x+legendSwatch, margin+lineHeight+legendSwatch), c)
+		x += legendSwatch + lineGap
+		drawText(img, x, margin+lineHeight, s.Name, textScale, textColor)
+		x += textWidth(s.Name, textScale) + 2*lineGap
+	}
+
+	// Axes
+	top := margin + 2*lineHeight
+	bottom := top + lineChartHeight
+	left := margin + axisWidth
+	right := width - margin
+	fillRect(img, image.Rect(left, top, left+1, bottom+1), mutedColor)
+	fillRect(img, image.Rect(left, bottom, right, bottom+1), mutedColor)
+	fillRect(img, image.Rect(left, top, right, top+1), emptyColor)
+	drawText(img, margin, top, strconv.Itoa(highest), textScale, mutedColor)
+	drawText(img, margin, bottom-glyphHeight*textScale, "0", textScale, mutedColor)
+	if len(labels) == 0 {
+		return img
+	}
+
+	// Points are inset by a step so that the first and last ones don't touch the edges. The step is
+	// at least a pixel, otherwise labels could never be kept apart by skipping them.
+	step := max((right-left)/(len(labels)+1), 1)
+	pointX := func(i int) int { return left + (i+1)*step }
+	pointY := func(v int) int { return bottom - v*lineChartHeight/highest }
+
+	labelStep := 1
+	for _, label := range labels {
+		for textWidth(label, textScale)+lineGap > labelStep*step {
+			labelStep++
+		}
+	}
+	for i := 0; i < len(labels); i += labelStep {
+		drawText(img, pointX(i)-textWidth(labels[i], textScale)/2, bottom+lineGap, labels[i], textScale, mutedColor)
+	}
+
+	for i, s := range series {
+		c := seriesColors[i%len(seriesColors)]
+		for j := 0; j < len(s.Values) && j < len(labels); j++ {
+			if j > 0 {
+				drawLine(img, pointX(j-1), pointY(s.Values[j-1]), pointX(j), pointY(s.Values[j]), c)
+			}
+			fillRect(img, image.Rect(pointX(j)-lineWidth, pointY(s.Values[j])-lineWidth, pointX(j)+lineWidth+1, pointY(s.Values[j])+lineWidth+1), c)
+		}
+	}
+	return img
+}
+
+// drawLine draws a line of lineWidth pixels from (x0, y0) to (x1, y1) using Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0 int, y0 int, x1 int, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		fillRect(img, image.Rect(x0-lineWidth/2, y0-lineWidth/2, x0+lineWidth-lineWidth/2, y0+lineWidth-lineWidth/2), c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
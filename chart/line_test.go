@@ -0,0 +1,39 @@
+package chart_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd/chart"
+)
+
+func TestLineChart(t *testing.T) {
+	// Ensure each series is drawn as a line over the labels.
+	t.Run("OK", func(t *testing.T) {
+		AssertGolden(t, "line", chart.LineChart("Reactions by month", []string{"Oct", "Nov", "Dec", "Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep"}, []chart.Series{
+			{Name: "Likes", Values: []int{30, 42, 25, 50, 61, 58, 70, 65, 40, 38, 72, 90}},
+			{Name: "Dislikes", Values: []int{5, 8, 3, 10, 12, 9, 15, 7, 4, 6, 11, 14}},
+		}))
+	})
+
+	// Ensure labels are skipped rather than overlapping when they don't fit.
+	t.Run("LongLabels", func(t *testing.T) {
+		labels := []string{"09-2023", "10-2023", "11-2023", "12-2023", "01-2024", "02-2024", "03-2024", "04-2024", "05-2024", "06-2024", "07-2024", "08-2024"}
+		AssertGolden(t, "line_long_labels", chart.LineChart("Reactions by month", labels, []chart.Series{
+			{Name: "Likes", Values: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}},
+		}))
+	})
+
+	// Ensure more labels than the chart is wide still render instead of looping forever.
+	t.Run("ManyLabels", func(t *testing.T) {
+		labels := make([]string, 2000)
+		values := make([]int, len(labels))
+		for i := range labels {
+			labels[i], values[i] = strconv.Itoa(i), i
+		}
+		img := chart.LineChart("Reactions by day", labels, []chart.Series{{Name: "Likes", Values: values}})
+		if img.Bounds().Empty() {
+			t.Fatal("expected image")
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// AdjustCommand represents a command for manually adjusting a member's likes or dislikes.
+type AdjustCommand struct{}
+
+// Run parses the command line flags and applies the adjustment.
+func (c *AdjustCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd adjust", flag.ContinueOnError)
+	rawTeam := fs.String("team", "", "team ID of the workspace (default the only installed workspace)")
+	rawMonth := fs.String("month", "", "month to adjust, e.g. 09-2024")
+	member := fs.String("member", "", "Slack user ID of the member")
+	metric := fs.String("metric", statsd.MetricLikes, "metric to adjust, likes or dislikes")
+	delta := fs.Int("delta", 0, "signed change of the metric")
+	reason := fs.String("reason", "", "why the adjustment is made")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rawMonth == "" {
+		return fmt.Errorf("adjust: -month is required")
+	}
+	month, err := statsd.NewMonthYearString(*rawMonth)
+	if err != nil {
+		return fmt.Errorf("adjust -month: %w", err)
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	teamID, err := findTeamID(ctx, sqlite.NewWorkspaceService(db), *rawTeam)
+	if err != nil {
+		return fmt.Errorf("adjust: %w", err)
+	}
+
+	a := &statsd.Adjustment{
+		TeamID:   teamID,
+		Date:     month,
+		SlackUID: *member,
+		Metric:   *metric,
+		Delta:    *delta,
+		Reason:   *reason,
+		Actor:    cliActor(),
+	}
+	if err := sqlite.NewAdjustmentService(db).CreateAdjustment(ctx, a); err != nil {
+		return fmt.Errorf("adjust: %w", err)
+	}
+	fmt.Printf("recorded adjustment %d: %+d %s for %s in %s\n", a.ID, a.Delta, a.Metric, a.SlackUID, a.Date)
+	return recordAudit(ctx, db, teamID, statsd.AuditActionAdjustmentCreate, strconv.Itoa(a.ID), nil, a)
+}
+
+// AdjustmentsCommand represents a command for listing the manual adjustments of a workspace.
+type AdjustmentsCommand struct{}
+
+// Run parses the command line flags and prints the matching adjustments, the oldest first.
+func (c *AdjustmentsCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd adjustments", flag.ContinueOnError)
+	rawTeam := fs.String("team", "", "team ID of the workspace (default the only installed workspace)")
+	rawMonth := fs.String("month", "", "only list adjustments of this month, e.g. 09-2024")
+	member := fs.String("member", "", "only list adjustments of this member")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	filter := statsd.AdjustmentFilter{SlackUID: *member}
+	if *rawMonth != "" {
+		month, err := statsd.NewMonthYearString(*rawMonth)
+		if err != nil {
+			return fmt.Errorf("adjustments -month: %w", err)
+		}
+		filter.Date = month
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if filter.TeamID, err = findTeamID(ctx, sqlite.NewWorkspaceService(db), *rawTeam); err != nil {
+		return fmt.Errorf("adjustments: %w", err)
+	}
+
+	adjustments, err := sqlite.NewAdjustmentService(db).FindAdjustments(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tMONTH\tMEMBER\tMETRIC\tDELTA\tREASON\tACTOR\tTIME\tREVERTED BY")
+	for _, a := range adjustments {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%+d\t%s\t%s\t%s\t%s\n", a.ID, a.Date, a.SlackUID, a.Metric, a.Delta, a.Reason, a.Actor, a.CreatedAt.Format(time.RFC3339), a.RevertedBy)
+	}
+	return w.Flush()
+}
+
+// RevertAdjustmentCommand represents a command for reverting a manual adjustment.
+type RevertAdjustmentCommand struct{}
+
+// Run parses the command line flags and reverts the adjustment.
+func (c *RevertAdjustmentCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd revert-adjustment", flag.ContinueOnError)
+	id := fs.Int("id", 0, "ID of the adjustment")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return fmt.Errorf("revert-adjustment: -id is required")
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	a, err := sqlite.NewAdjustmentService(db).RevertAdjustment(ctx, *id, cliActor())
+	if err != nil {
+		return fmt.Errorf("revert-adjustment: %w", err)
+	}
+	fmt.Printf("reverted adjustment %d: %+d %s for %s in %s\n", a.ID, a.Delta, a.Metric, a.SlackUID, a.Date)
+	return recordAudit(ctx, db, a.TeamID, statsd.AuditActionAdjustmentRevert, strconv.Itoa(a.ID), nil, a)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// AuditCommand represents a command for listing the audit log.
+type AuditCommand struct{}
+
+// Run parses the command line flags and prints the matching audit log entries, the most recent first.
+func (c *AuditCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd audit", flag.ContinueOnError)
+	team := fs.String("team", "", "only list entries of this workspace")
+	actor := fs.String("actor", "", "only list entries of this actor, e.g. cli:alice")
+	action := fs.String("action", "", "only list entries of this action, e.g. members.recompute")
+	rawFrom := fs.String("from", "", "only list entries at or after this time (RFC 3339)")
+	rawTo := fs.String("to", "", "only list entries before this time (RFC 3339)")
+	limit := fs.Int("limit", statsd.DefaultAuditLimit, "maximum number of entries")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter := statsd.AuditFilter{TeamID: *team, Actor: *actor, Action: *action, Limit: *limit}
+	var err error
+	if *rawFrom != "" {
+		if filter.From, err = time.Parse(time.RFC3339, *rawFrom); err != nil {
+			return fmt.Errorf("audit -from: %w", err)
+		}
+	}
+	if *rawTo != "" {
+		if filter.To, err = time.Parse(time.RFC3339, *rawTo); err != nil {
+			return fmt.Errorf("audit -to: %w", err)
+		}
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	entries, err := sqlite.NewAuditService(db).FindAuditEntries(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTEAM\tACTOR\tACTION\tTARGET\tBEFORE\tAFTER")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.CreatedAt.Format(time.RFC3339), e.TeamID, e.Actor, e.Action, e.Target, e.Before, e.After)
+	}
+	return w.Flush()
+}
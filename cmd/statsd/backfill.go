@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/http"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// BackfillCommand represents a command for recording the reactions of past months from the Slack history.
+type BackfillCommand struct{}
+
+// Run parses the command line flags and backfills the requested range of months.
+func (c *BackfillCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd backfill", flag.ContinueOnError)
+	rawTeam := fs.String("team", "", "team ID of the workspace (default the only installed workspace)")
+	rawFrom := fs.String("from", "", "first month to backfill, e.g. 01-2023")
+	rawTo := fs.String("to", "", "last month to backfill, e.g. 09-2024 (default current month)")
+	rawChannels := fs.String("channels", "", "comma-separated channel IDs (default all channels the bot is a member of)")
+	recompute := fs.Bool("recompute", false, "rebuild the counts of months which were counted before their reactions were recorded")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *rawFrom == "" {
+		return fmt.Errorf("backfill: -from is required")
+	}
+	from, err := statsd.NewMonthYearString(*rawFrom)
+	if err != nil {
+		return fmt.Errorf("backfill -from: %w", err)
+	}
+	to := statsd.NewMonthYear(time.Now())
+	if *rawTo != "" {
+		if to, err = statsd.NewMonthYearString(*rawTo); err != nil {
+			return fmt.Errorf("backfill -to: %w", err)
+		}
+	}
+	var channelIDs []string
+	if *rawChannels != "" {
+		channelIDs = strings.Split(*rawChannels, ",")
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	workspaceService := sqlite.NewWorkspaceService(db)
+	teamID, err := findTeamID(ctx, workspaceService, *rawTeam)
+	if err != nil {
+		return fmt.Errorf("backfill: %w", err)
+	}
+
+	filter, err := envChannelFilter()
+	if err != nil {
+		return fmt.Errorf("backfill: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	backfiller := http.NewBackfiller(logger, sqlite.NewReactionService(db), sqlite.NewMemberService(db), sqlite.NewBackfillService(db), workspaceService, filter)
+	if err := backfiller.Backfill(ctx, teamID, from, to, channelIDs, *recompute); err != nil {
+		return err
+	}
+	return recordAudit(ctx, db, teamID, statsd.AuditActionBackfill, *rawChannels, nil, map[string]any{"from": from, "to": to, "recompute": *recompute})
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// ForgetCommand represents a command for permanently erasing everything recorded about a member.
+type ForgetCommand struct{}
+
+// Run parses the command line flags and erases the member's rows.
+func (c *ForgetCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd forget", flag.ContinueOnError)
+	rawTeam := fs.String("team", "", "team ID of the workspace (default the only installed workspace)")
+	member := fs.String("member", "", "Slack user ID of the member")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *member == "" {
+		return fmt.Errorf("forget: -member is required")
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	teamID, err := findTeamID(ctx, sqlite.NewWorkspaceService(db), *rawTeam)
+	if err != nil {
+		return fmt.Errorf("forget: %w", err)
+	}
+
+	result, err := sqlite.NewPrivacyService(db).ForgetMember(ctx, teamID, *member)
+	if err != nil {
+		return fmt.Errorf("forget: %w", err)
+	}
+	fmt.Printf("erased %d member rows, %d reactions, %d liked weeks, %d adjustments and %d opt-outs and redacted %d audit log entries\n", result.Members, result.Reactions, result.LikedWeeks, result.Adjustments, result.OptOuts, result.AuditEntries)
+	return recordAudit(ctx, db, teamID, statsd.AuditActionForget, result.Pseudonym, nil, result)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// GraphCommand represents a command for exporting the graph of who reacted to whom.
+type GraphCommand struct{}
+
+// Run parses the command line flags and writes the reaction graph of a period to stdout.
+func (c *GraphCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd graph", flag.ContinueOnError)
+	rawTeam := fs.String("team", "", "team ID of the workspace (default the only installed workspace)")
+	rawPeriod := fs.String("period", string(statsd.PeriodAllTime), "period to cover, e.g. 2024, 2024-Q3, 09-2024 or ytd")
+	format := fs.String("format", statsd.GraphFormatDOT, "output format: dot, graphml or json")
+	isolated := fs.Bool("isolated", false, "only list the members who neither reacted to nor received a reaction from anyone else")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	period, err := statsd.ParsePeriod(*rawPeriod, time.Now())
+	if err != nil {
+		return fmt.Errorf("graph -period: %w", err)
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	teamID, err := findTeamID(ctx, sqlite.NewWorkspaceService(db), *rawTeam)
+	if err != nil {
+		return fmt.Errorf("graph: %w", err)
+	}
+
+	g, err := sqlite.NewGraphService(db).FindReactionGraph(ctx, teamID, period)
+	if err != nil {
+		return fmt.Errorf("graph: %w", err)
+	}
+	if *isolated {
+		for _, uid := range g.Isolated() {
+			fmt.Println(uid)
+		}
+		return nil
+	}
+	if err := g.Encode(os.Stdout, *format); err != nil {
+		return fmt.Errorf("graph: %w", err)
+	}
+	return nil
+}
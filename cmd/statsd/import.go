@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/slackexport"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// ImportCommand represents a command for recording the reactions within a Slack export archive.
+type ImportCommand struct{}
+
+// Run parses the command line flags and imports the given archive.
+func (c *ImportCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd import", flag.ContinueOnError)
+	rawTeam := fs.String("team", "", "team ID of the exported workspace (default the only installed workspace)")
+	archive := fs.String("archive", "", "path to the Slack export ZIP archive")
+	recompute := fs.Bool("recompute", false, "rebuild the counts of months which were counted before their reactions were recorded")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *archive == "" {
+		return fmt.Errorf("import: -archive is required")
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	teamID, err := findTeamID(ctx, sqlite.NewWorkspaceService(db), *rawTeam)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	filter, err := envChannelFilter()
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	result, err := slackexport.NewImporter(logger, sqlite.NewReactionService(db), sqlite.NewMemberService(db), filter).ImportFile(ctx, teamID, *archive, *recompute)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("recorded %d reactions, skipped %d already recorded and %d others\n", result.Recorded, result.Duplicates, result.Skipped)
+	return recordAudit(ctx, db, teamID, statsd.AuditActionImport, filepath.Base(*archive), nil, result)
+}
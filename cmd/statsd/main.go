@@ -3,14 +3,23 @@ package main
 import (
 	"context"
 	_ "embed"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"os/user"
+	"strings"
+	"time"
+	_ "time/tzdata" // Embeds the time zones for STATSD_TIME_ZONE as the image may not ship them.
 
+	"github.com/ddritzenhoff/statsd"
 	"github.com/ddritzenhoff/statsd/http"
 	"github.com/ddritzenhoff/statsd/sqlite"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 const (
@@ -26,6 +35,21 @@ func main() {
 	signal.Notify(c, os.Interrupt)
 	go func() { <-c; cancel() }()
 
+	// Months are determined within the time zone of the organisation.
+	if err := loadTimeZone(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Execute a subcommand if one is given. Otherwise, run the server.
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	m := &Main{}
 
 	// Execute program.
@@ -41,11 +65,44 @@ func main() {
 	m.Close()
 }
 
+// runCommand executes the subcommand with the given name.
+func runCommand(ctx context.Context, name string, args []string) error {
+	switch name {
+	case "backfill":
+		return (&BackfillCommand{}).Run(ctx, args)
+	case "import":
+		return (&ImportCommand{}).Run(ctx, args)
+	case "recompute":
+		return (&RecomputeCommand{}).Run(ctx, args)
+	case "audit":
+		return (&AuditCommand{}).Run(ctx, args)
+	case "rotate-key":
+		return (&RotateKeyCommand{}).Run(ctx, args)
+	case "adjust":
+		return (&AdjustCommand{}).Run(ctx, args)
+	case "adjustments":
+		return (&AdjustmentsCommand{}).Run(ctx, args)
+	case "revert-adjustment":
+		return (&RevertAdjustmentCommand{}).Run(ctx, args)
+	case "forget":
+		return (&ForgetCommand{}).Run(ctx, args)
+	case "prune":
+		return (&PruneCommand{}).Run(ctx, args)
+	case "graph":
+		return (&GraphCommand{}).Run(ctx, args)
+	default:
+		return fmt.Errorf("unknown command: %s", name)
+	}
+}
+
 // Main represents the program.
 type Main struct {
 	// SQLite database used by SQLite service implementations.
 	DB *sqlite.DB
 
+	// Tracer provider exporting spans via OTLP. Nil if tracing is disabled.
+	TracerProvider *sdktrace.TracerProvider
+
 	// HTTP server for handling HTTP communication.
 	// SQLite services are attached to it before running.
 	HTTPServer *http.Server
@@ -55,21 +112,69 @@ type Main struct {
 func (m *Main) Run(ctx context.Context) error {
 	signingSecret := os.Getenv("SLACK_SIGNING_SECRET")
 	botSigningKey := os.Getenv("SLACK_BOT_SIGNING_KEY")
+	oauth := http.OAuthConfig{
+		ClientID:     os.Getenv("SLACK_CLIENT_ID"),
+		ClientSecret: os.Getenv("SLACK_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("SLACK_REDIRECT_URL"),
+	}
 
-	m.DB = sqlite.NewDB(DSN)
-	if err := m.DB.Open(); err != nil {
-		return fmt.Errorf("db open: %w", err)
+	var err error
+	if m.TracerProvider, err = newTracerProvider(ctx); err != nil {
+		return err
+	}
+	if m.DB, err = openDB(DSN); err != nil {
+		return err
 	}
 
 	memberService := sqlite.NewMemberService(m.DB)
 	leaderboardService := sqlite.NewLeaderboardService(m.DB)
+	reactionService := sqlite.NewReactionService(m.DB)
+	workspaceService := sqlite.NewWorkspaceService(m.DB)
+	auditService := sqlite.NewAuditService(m.DB)
+	privacyService := sqlite.NewPrivacyService(m.DB)
+	heatmapService := sqlite.NewHeatmapService(m.DB)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	slackService, err := http.NewSlackService(logger, memberService, leaderboardService, signingSecret, botSigningKey)
+	prometheus.MustRegister(sqlite.NewMonthlyTotalsCollector(logger, m.DB))
+
+	// A bot token from the environment installs its workspace without going through OAuth.
+	if botSigningKey != "" {
+		workspace, err := http.RegisterWorkspace(ctx, workspaceService, botSigningKey)
+		if err != nil {
+			return fmt.Errorf("Run: %w", err)
+		}
+		logger.Info("registered workspace", slog.String("team", workspace.TeamID))
+	}
+
+	channelGroups, err := statsd.ParseChannelGroups(os.Getenv("STATSD_CHANNEL_GROUPS"))
+	if err != nil {
+		return fmt.Errorf("Run STATSD_CHANNEL_GROUPS: %w", err)
+	}
+	filter, err := channelFilter(channelGroups)
+	if err != nil {
+		return fmt.Errorf("Run: %w", err)
+	}
+
+	slackService, err := http.NewSlackService(logger, memberService, leaderboardService, reactionService, workspaceService, auditService, privacyService, heatmapService, sqlite.NewStreakService(m.DB), channelGroups, filter, signingSecret, oauth)
 	if err != nil {
 		return fmt.Errorf("Run NewSlackService: %w", err)
 	}
 
-	m.HTTPServer = http.NewServer(logger, HTTPAddr, slackService)
+	healthChecker := http.NewHealthChecker(m.DB, workspaceService, auditService, os.Getenv("STATSD_READYZ_CHECK_SLACK") == "true")
+	admin := http.NewAdmin(logger, os.Getenv("STATSD_ADMIN_TOKEN"), auditService, sqlite.NewAdjustmentService(m.DB), privacyService, leaderboardService, sqlite.NewGraphService(m.DB), heatmapService, channelGroups)
+	// Enforce the retention policy in the background if one is configured.
+	policy, err := retentionPolicy()
+	if err != nil {
+		return fmt.Errorf("Run: %w", err)
+	}
+	interval, err := pruneInterval()
+	if err != nil {
+		return fmt.Errorf("Run: %w", err)
+	}
+	if policy.ReactionMonths > 0 || policy.AuditMonths > 0 {
+		go runPruner(ctx, logger, m.DB, policy, interval)
+	}
+
+	m.HTTPServer = http.NewServer(logger, HTTPAddr, slackService, healthChecker, admin)
 	if err := m.HTTPServer.Open(); err != nil {
 		return fmt.Errorf("Run: %w", err)
 	}
@@ -77,6 +182,133 @@ func (m *Main) Run(ctx context.Context) error {
 	return nil
 }
 
+// channelFilter returns the channels in which reactions are counted as configured by
+// STATSD_INCLUDE_CHANNELS and STATSD_EXCLUDE_CHANNELS, comma separated lists of channel IDs
+// or channel groups, and STATSD_CHANNEL_TYPES, a comma separated list of channel types.
+func channelFilter(groups statsd.ChannelGroups) (statsd.ChannelFilter, error) {
+	var filter statsd.ChannelFilter
+	for name, v := range map[string]*[]string{
+		"STATSD_INCLUDE_CHANNELS": &filter.Include,
+		"STATSD_EXCLUDE_CHANNELS": &filter.Exclude,
+	} {
+		for _, scope := range strings.Split(os.Getenv(name), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				*v = append(*v, groups.Channels(scope)...)
+			}
+		}
+	}
+	types, err := statsd.ParseChannelTypes(os.Getenv("STATSD_CHANNEL_TYPES"))
+	if err != nil {
+		return filter, fmt.Errorf("STATSD_CHANNEL_TYPES: %w", err)
+	}
+	filter.Types = types
+	return filter, nil
+}
+
+// envChannelFilter returns the channel filter configured by the environment, resolving the
+// channel groups given by STATSD_CHANNEL_GROUPS.
+func envChannelFilter() (statsd.ChannelFilter, error) {
+	groups, err := statsd.ParseChannelGroups(os.Getenv("STATSD_CHANNEL_GROUPS"))
+	if err != nil {
+		return statsd.ChannelFilter{}, fmt.Errorf("STATSD_CHANNEL_GROUPS: %w", err)
+	}
+	return channelFilter(groups)
+}
+
+// openDB opens the database at dsn, using the encryption keys from the environment to protect secrets.
+func openDB(dsn string) (*sqlite.DB, error) {
+	keys, err := encryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+	db := sqlite.NewDB(dsn)
+	db.EncryptionKeys = keys
+	if err := db.Open(); err != nil {
+		return nil, fmt.Errorf("db open: %w", err)
+	}
+	return db, nil
+}
+
+// encryptionKeys returns the base64 encoded keys given by STATSD_ENCRYPTION_KEY as a comma-separated
+// list, or by the file at STATSD_ENCRYPTION_KEY_FILE with one key per line. The first key is used to
+// encrypt new secrets, while the others are only used for decryption until the keys are rotated.
+func encryptionKeys() ([][]byte, error) {
+	var rawKeys []string
+	if path := os.Getenv("STATSD_ENCRYPTION_KEY_FILE"); path != "" {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("STATSD_ENCRYPTION_KEY_FILE: %w", err)
+		}
+		rawKeys = strings.Split(string(buf), "\n")
+	} else if rawKey := os.Getenv("STATSD_ENCRYPTION_KEY"); rawKey != "" {
+		rawKeys = strings.Split(rawKey, ",")
+	}
+
+	var keys [][]byte
+	for _, rawKey := range rawKeys {
+		rawKey = strings.TrimSpace(rawKey)
+		if rawKey == "" || strings.HasPrefix(rawKey, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// loadTimeZone sets the time zone of the organisation from STATSD_TIME_ZONE, e.g. America/Los_Angeles.
+// UTC is used if it is not set.
+func loadTimeZone() error {
+	name := os.Getenv("STATSD_TIME_ZONE")
+	if name == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("STATSD_TIME_ZONE: %w", err)
+	}
+	statsd.TimeZone = loc
+	return nil
+}
+
+// findTeamID returns teamID if given. Otherwise, it returns the team ID of the only installed workspace.
+func findTeamID(ctx context.Context, ws statsd.WorkspaceService, teamID string) (string, error) {
+	if teamID != "" {
+		return teamID, nil
+	}
+	workspaces, err := ws.FindWorkspaces(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(workspaces) != 1 {
+		return "", errors.New("-team is required when more than one workspace is installed")
+	}
+	return workspaces[0].TeamID, nil
+}
+
+// recordAudit records an administrative action taken through the command line.
+func recordAudit(ctx context.Context, db *sqlite.DB, teamID string, action string, target string, before any, after any) error {
+	e, err := statsd.NewAuditEntry(teamID, cliActor(), action, target, before, after)
+	if err != nil {
+		return err
+	}
+	if err := sqlite.NewAuditService(db).CreateAuditEntry(ctx, e); err != nil {
+		return fmt.Errorf("record audit entry: %w", err)
+	}
+	return nil
+}
+
+// cliActor returns the actor recorded for commands, which is the user running them.
+func cliActor() string {
+	if u, err := user.Current(); err == nil {
+		return "cli:" + u.Username
+	}
+	return "cli:" + os.Getenv("USER")
+}
+
 // Close gracefully closes open http server and database connections.
 func (m *Main) Close() error {
 	if m.HTTPServer != nil {
@@ -89,5 +321,10 @@ func (m *Main) Close() error {
 			return err
 		}
 	}
+	if m.TracerProvider != nil {
+		if err := m.TracerProvider.Shutdown(context.Background()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// DefaultPruneInterval is the time between runs of the retention policy unless STATSD_PRUNE_INTERVAL is set.
+const DefaultPruneInterval = 24 * time.Hour
+
+// PruneCommand represents a command for deleting the data which is older than the retention policy allows.
+type PruneCommand struct{}
+
+// Run parses the command line flags and prunes the database once.
+func (c *PruneCommand) Run(ctx context.Context, args []string) error {
+	policy, err := retentionPolicy()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("statsd prune", flag.ContinueOnError)
+	fs.IntVar(&policy.ReactionMonths, "reaction-months", policy.ReactionMonths, "months of reactions to keep, including the current one (0 keeps them forever)")
+	fs.IntVar(&policy.AuditMonths, "audit-months", policy.AuditMonths, "months of audit log entries to keep, including the current one (0 keeps them forever)")
+	fs.IntVar(&policy.BatchSize, "batch-size", policy.BatchSize, "maximum number of rows deleted at once")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := db.Prune(ctx, policy)
+	if err != nil {
+		return fmt.Errorf("prune: %w", err)
+	}
+	fmt.Printf("pruned %d reactions and %d audit entries\n", result.Reactions, result.AuditEntries)
+	return recordAudit(ctx, db, "", statsd.AuditActionPrune, "", nil, result)
+}
+
+// retentionPolicy returns the retention policy configured by STATSD_RETENTION_REACTION_MONTHS,
+// STATSD_RETENTION_AUDIT_MONTHS and STATSD_PRUNE_BATCH_SIZE. Unset values keep data forever.
+func retentionPolicy() (statsd.RetentionPolicy, error) {
+	policy := statsd.RetentionPolicy{BatchSize: statsd.DefaultPruneBatchSize}
+	for name, v := range map[string]*int{
+		"STATSD_RETENTION_REACTION_MONTHS": &policy.ReactionMonths,
+		"STATSD_RETENTION_AUDIT_MONTHS":    &policy.AuditMonths,
+		"STATSD_PRUNE_BATCH_SIZE":          &policy.BatchSize,
+	} {
+		raw := os.Getenv(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return policy, fmt.Errorf("%s must be a non-negative number", name)
+		}
+		*v = n
+	}
+	return policy, nil
+}
+
+// pruneInterval returns the time between runs of the retention policy configured by STATSD_PRUNE_INTERVAL.
+func pruneInterval() (time.Duration, error) {
+	raw := os.Getenv("STATSD_PRUNE_INTERVAL")
+	if raw == "" {
+		return DefaultPruneInterval, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("STATSD_PRUNE_INTERVAL must be a positive duration")
+	}
+	return d, nil
+}
+
+// runPruner enforces the retention policy every interval until ctx is canceled.
+func runPruner(ctx context.Context, logger *slog.Logger, db *sqlite.DB, policy statsd.RetentionPolicy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := db.Prune(ctx, policy)
+		if err != nil {
+			logger.Error("prune", slog.String("error", err.Error()))
+		} else {
+			logger.Info("pruned", slog.Int("reactions", result.Reactions), slog.Int("auditEntries", result.AuditEntries))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// RecomputeCommand represents a command for rebuilding the monthly counts of members from the recorded reactions.
+type RecomputeCommand struct{}
+
+// Run parses the command line flags, recomputes the requested month and prints the changed counts.
+func (c *RecomputeCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd recompute", flag.ContinueOnError)
+	rawTeam := fs.String("team", "", "team ID of the workspace (default the only installed workspace)")
+	rawMonth := fs.String("month", "", "month to recompute, e.g. 09-2024")
+	dryRun := fs.Bool("dry-run", false, "report the changes without saving them")
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rawMonth == "" {
+		return fmt.Errorf("recompute: -month is required")
+	}
+	month, err := statsd.NewMonthYearString(*rawMonth)
+	if err != nil {
+		return fmt.Errorf("recompute -month: %w", err)
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	teamID, err := findTeamID(ctx, sqlite.NewWorkspaceService(db), *rawTeam)
+	if err != nil {
+		return fmt.Errorf("recompute: %w", err)
+	}
+
+	diffs, err := sqlite.NewMemberService(db).RecomputeMembers(ctx, teamID, month, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "MEMBER\tLIKES\tDISLIKES")
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%s\t%d -> %d\t%d -> %d\n", d.SlackUID, d.OldReceivedLikes, d.NewReceivedLikes, d.OldReceivedDislikes, d.NewReceivedDislikes)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Printf("dry run: %d members would change\n", len(diffs))
+		return nil
+	}
+	fmt.Printf("%d members changed\n", len(diffs))
+
+	// Record the counts of the changed members before and after the recompute.
+	type counts struct {
+		ReceivedLikes    int `json:"receivedLikes"`
+		ReceivedDislikes int `json:"receivedDislikes"`
+	}
+	before, after := make(map[string]counts), make(map[string]counts)
+	for _, d := range diffs {
+		before[d.SlackUID] = counts{d.OldReceivedLikes, d.OldReceivedDislikes}
+		after[d.SlackUID] = counts{d.NewReceivedLikes, d.NewReceivedDislikes}
+	}
+	return recordAudit(ctx, db, teamID, statsd.AuditActionRecompute, month.String(), before, after)
+}
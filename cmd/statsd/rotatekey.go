@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+// RotateKeyCommand represents a command for re-encrypting the stored secrets with the primary encryption key.
+type RotateKeyCommand struct{}
+
+// Run parses the command line flags and rewraps every secret with the first configured encryption key.
+func (c *RotateKeyCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("statsd rotate-key", flag.ContinueOnError)
+	dsn := fs.String("dsn", DSN, "path to the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	n, err := db.RotateEncryptionKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("rotate-key: %w", err)
+	}
+	fmt.Printf("rewrapped %d secrets with the primary encryption key\n", n)
+	return recordAudit(ctx, db, "", statsd.AuditActionRotateKeys, "", nil, map[string]int{"secrets": n})
+}
@@ -5,3 +5,4 @@ import "errors"
 // Application error codes.
 var ErrNotFound = errors.New("not found")
 var ErrInvalid = errors.New("invalid")
+var ErrConflict = errors.New("conflict")
@@ -0,0 +1,202 @@
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Formats a ReactionGraph can be encoded in.
+const (
+	GraphFormatGraphML = "graphml"
+	GraphFormatDOT     = "dot"
+	GraphFormatJSON    = "json"
+)
+
+// ReactionEdge represents the reactions one member left on the messages of another over a period.
+type ReactionEdge struct {
+	ReactorUID string `json:"reactorUID"`
+	AuthorUID  string `json:"authorUID"`
+	Likes      int    `json:"likes"`
+	Dislikes   int    `json:"dislikes"`
+}
+
+// Weight returns the number of reactions along the edge.
+func (e *ReactionEdge) Weight() int {
+	return e.Likes + e.Dislikes
+}
+
+// ReactionGraph represents who reacted to whom within a workspace over a Period. Members holds
+// every known member of the workspace, so members without any edge are isolated.
+type ReactionGraph struct {
+	TeamID  string         `json:"teamID"`
+	Period  Period         `json:"period"`
+	Members []string       `json:"members"`
+	Edges   []ReactionEdge `json:"edges"`
+}
+
+// Isolated returns the members who neither reacted to nor received a reaction from anyone else.
+func (g *ReactionGraph) Isolated() []string {
+	connected := make(map[string]bool)
+	for _, e := range g.Edges {
+		if e.ReactorUID != e.AuthorUID {
+			connected[e.ReactorUID] = true
+			connected[e.AuthorUID] = true
+		}
+	}
+	var isolated []string
+	for _, m := range g.Members {
+		if !connected[m] {
+			isolated = append(isolated, m)
+		}
+	}
+	return isolated
+}
+
+// Encode writes the graph to w in the given format.
+// Returns ErrInvalid if the format is unknown.
+func (g *ReactionGraph) Encode(w io.Writer, format string) error {
+	switch format {
+	case GraphFormatGraphML:
+		return g.encodeGraphML(w)
+	case GraphFormatDOT:
+		return g.encodeDOT(w)
+	case GraphFormatJSON:
+		return g.encodeJSON(w)
+	}
+	return fmt.Errorf("graph format must be %s, %s or %s %w", GraphFormatGraphML, GraphFormatDOT, GraphFormatJSON, ErrInvalid)
+}
+
+// encodeDOT writes the graph in the DOT language of Graphviz.
+func (g *ReactionGraph) encodeDOT(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "digraph %q {\n", g.TeamID+" "+g.Period.String()); err != nil {
+		return err
+	}
+	for _, m := range g.Members {
+		if _, err := fmt.Fprintf(w, "  %q;\n", m); err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges {
+		if _, err := fmt.Fprintf(w, "  %q -> %q [weight=%d, likes=%d, dislikes=%d];\n", e.ReactorUID, e.AuthorUID, e.Weight(), e.Likes, e.Dislikes); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// encodeJSON writes the graph as an adjacency map from reactors to the authors they reacted to.
+// Members without outgoing edges map to an empty object.
+func (g *ReactionGraph) encodeJSON(w io.Writer) error {
+	type counts struct {
+		Weight   int `json:"weight"`
+		Likes    int `json:"likes"`
+		Dislikes int `json:"dislikes"`
+	}
+	adjacency := make(map[string]map[string]counts)
+	for _, m := range g.Members {
+		adjacency[m] = make(map[string]counts)
+	}
+	for _, e := range g.Edges {
+		if adjacency[e.ReactorUID] == nil {
+			adjacency[e.ReactorUID] = make(map[string]counts)
+		}
+		adjacency[e.ReactorUID][e.AuthorUID] = counts{Weight: e.Weight(), Likes: e.Likes, Dislikes: e.Dislikes}
+	}
+	return json.NewEncoder(w).Encode(struct {
+		TeamID    string                       `json:"teamID"`
+		Period    string                       `json:"period"`
+		Adjacency map[string]map[string]counts `json:"adjacency"`
+	}{g.TeamID, g.Period.String(), adjacency})
+}
+
+// encodeGraphML writes the graph in GraphML, with the counts of each edge as data.
+func (g *ReactionGraph) encodeGraphML(w io.Writer) error {
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value int    `xml:",chardata"`
+	}
+	type node struct {
+		ID string `xml:"id,attr"`
+	}
+	type edge struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+		Data   []data `xml:"data"`
+	}
+	type key struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+	type graph struct {
+		ID          string `xml:"id,attr"`
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []node `xml:"node"`
+		Edges       []edge `xml:"edge"`
+	}
+	doc := struct {
+		XMLName xml.Name `xml:"graphml"`
+		XMLNS   string   `xml:"xmlns,attr"`
+		Keys    []key    `xml:"key"`
+		Graph   graph    `xml:"graph"`
+	}{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []key{
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+			{ID: "likes", For: "edge", AttrName: "likes", AttrType: "int"},
+			{ID: "dislikes", For: "edge", AttrName: "dislikes", AttrType: "int"},
+		},
+		Graph: graph{ID: g.TeamID + " " + g.Period.String(), EdgeDefault: "directed"},
+	}
+
+	// Every endpoint must be declared as a node.
+	nodes := make(map[string]bool)
+	for _, m := range g.Members {
+		nodes[m] = true
+	}
+	for _, e := range g.Edges {
+		nodes[e.ReactorUID] = true
+		nodes[e.AuthorUID] = true
+	}
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{ID: id})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{
+			Source: e.ReactorUID,
+			Target: e.AuthorUID,
+			Data:   []data{{"weight", e.Weight()}, {"likes", e.Likes}, {"dislikes", e.Dislikes}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// GraphService represents a service for analysing who reacts to whom.
+type GraphService interface {
+	// FindReactionGraph retrieves the graph of reactions from reactors to authors within a
+	// workspace over a period, counted from the recorded Reactions. Members who opted out are
+	// left out entirely. The all-time period covers the Reactions which haven't been pruned;
+	// ErrInvalid is returned if any other period starts before they have been pruned.
+	FindReactionGraph(ctx context.Context, teamID string, period Period) (*ReactionGraph, error)
+}
@@ -0,0 +1,107 @@
+package statsd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+// newReactionGraph returns a graph of three members, one of whom is isolated.
+func newReactionGraph(tb testing.TB) *statsd.ReactionGraph {
+	tb.Helper()
+	p, err := statsd.ParsePeriod("2024", time.Now())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &statsd.ReactionGraph{
+		TeamID:  "T1ZN1SE2N",
+		Period:  p,
+		Members: []string{"U1ZN1SE2N", "U2ZN1SE2N", "U3ZN1SE2N"},
+		Edges: []statsd.ReactionEdge{
+			{ReactorUID: "U1ZN1SE2N", AuthorUID: "U2ZN1SE2N", Likes: 3, Dislikes: 1},
+			{ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Likes: 2},
+			{ReactorUID: "U3ZN1SE2N", AuthorUID: "U3ZN1SE2N", Likes: 1},
+		},
+	}
+}
+
+func TestReactionGraph_Isolated(t *testing.T) {
+	// Ensure reactions to one's own messages don't connect a member.
+	if got, want := newReactionGraph(t).Isolated(), []string{"U3ZN1SE2N"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Isolated=%v, want %v", got, want)
+	}
+}
+
+func TestReactionGraph_Encode(t *testing.T) {
+	// Ensure edges are written as weighted DOT edges.
+	t.Run("DOT", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := newReactionGraph(t).Encode(&buf, statsd.GraphFormatDOT); err != nil {
+			t.Fatal(err)
+		} else if !strings.HasPrefix(buf.String(), `digraph "T1ZN1SE2N 2024" {`) {
+			t.Fatalf("unexpected output: %s", buf.String())
+		} else if !strings.Contains(buf.String(), `"U1ZN1SE2N" -> "U2ZN1SE2N" [weight=4, likes=3, dislikes=1];`) {
+			t.Fatalf("missing edge: %s", buf.String())
+		}
+	})
+
+	// Ensure the output is well-formed GraphML with every node and edge.
+	t.Run("GraphML", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := newReactionGraph(t).Encode(&buf, statsd.GraphFormatGraphML); err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"graph>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"graph>edge"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(doc.Nodes), 3; got != want {
+			t.Fatalf("len(Nodes)=%v, want %v", got, want)
+		} else if got, want := len(doc.Edges), 3; got != want {
+			t.Fatalf("len(Edges)=%v, want %v", got, want)
+		} else if doc.Edges[0].Source != "U1ZN1SE2N" || doc.Edges[0].Target != "U2ZN1SE2N" {
+			t.Fatalf("unexpected edge: %#v", doc.Edges[0])
+		}
+	})
+
+	// Ensure every member is listed within the adjacency map.
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := newReactionGraph(t).Encode(&buf, statsd.GraphFormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			Adjacency map[string]map[string]struct {
+				Weight int `json:"weight"`
+			} `json:"adjacency"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(doc.Adjacency), 3; got != want {
+			t.Fatalf("len(Adjacency)=%v, want %v", got, want)
+		} else if got, want := doc.Adjacency["U1ZN1SE2N"]["U2ZN1SE2N"].Weight, 4; got != want {
+			t.Fatalf("Weight=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure unknown formats are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		if err := newReactionGraph(t).Encode(&bytes.Buffer{}, "svg"); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package statsd
+
+import (
+	"context"
+	"time"
+)
+
+// Heatmap represents the number of reactions within a workspace over a Period by weekday and
+// hour of the day within TimeZone. Counts is indexed by the weekday, starting with Monday, and
+// the hour.
+type Heatmap struct {
+	TeamID     string     `json:"teamID"`
+	Period     Period     `json:"period"`
+	ChannelIDs []string   `json:"channelIDs,omitempty"`
+	Counts     [7][24]int `json:"counts"`
+}
+
+// Add counts n reactions at t.
+func (h *Heatmap) Add(t time.Time, n int) {
+	t = t.In(TimeZone)
+	h.Counts[(int(t.Weekday())+6)%7][t.Hour()] += n
+}
+
+// Max returns the highest number of reactions within a single hour.
+func (h *Heatmap) Max() int {
+	var v int
+	for _, hours := range h.Counts {
+		for _, n := range hours {
+			v = max(v, n)
+		}
+	}
+	return v
+}
+
+// Total returns the number of reactions within the heatmap.
+func (h *Heatmap) Total() int {
+	var v int
+	for _, hours := range h.Counts {
+		for _, n := range hours {
+			v += n
+		}
+	}
+	return v
+}
+
+// HeatmapService represents a service for analysing when members react.
+type HeatmapService interface {
+	// FindHeatmap retrieves the Heatmap of the recorded Reactions within a workspace over a period.
+	// The reactions may be restricted to the given channels; all channels are included if none
+	// are given. Reactions to members who opted out of the totals are left out. The all-time period
+	// covers the Reactions which haven't been pruned; ErrInvalid is returned if any other period
+	// starts before they have been pruned.
+	FindHeatmap(ctx context.Context, teamID string, channelIDs []string, period Period) (*Heatmap, error)
+}
@@ -0,0 +1,29 @@
+package statsd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+func TestHeatmap_Add(t *testing.T) {
+	// Ensure reactions are counted by weekday and hour within the configured time zone.
+	MustSetTimeZone(t, "America/Los_Angeles")
+	var h statsd.Heatmap
+	// Monday at 06:30 UTC is still Sunday evening in Los Angeles.
+	h.Add(time.Date(2024, time.March, 4, 6, 30, 0, 0, time.UTC), 2)
+	// Monday at 17:00 UTC is Monday morning.
+	h.Add(time.Date(2024, time.March, 4, 17, 0, 0, 0, time.UTC), 3)
+	h.Add(time.Date(2024, time.March, 11, 16, 0, 0, 0, time.UTC), 1)
+
+	if got, want := h.Counts[6][22], 2; got != want {
+		t.Fatalf("Counts[Sunday][22]=%d, want %d", got, want)
+	} else if got, want := h.Counts[0][9], 4; got != want {
+		t.Fatalf("Counts[Monday][9]=%d, want %d", got, want)
+	} else if got, want := h.Max(), 4; got != want {
+		t.Fatalf("Max=%d, want %d", got, want)
+	} else if got, want := h.Total(), 6; got != want {
+		t.Fatalf("Total=%d, want %d", got, want)
+	}
+}
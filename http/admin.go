@@ -0,0 +1,468 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/chart"
+	"github.com/go-chi/chi/v5"
+)
+
+// AdminActor is the actor recorded in the audit log for changes made through the admin API.
+const AdminActor = "admin-api"
+
+// AdminUserHeader optionally names the operator behind an admin API request. It is
+// appended to AdminActor so the audit log and adjustments record who made a change.
+const AdminUserHeader = "X-Statsd-Admin"
+
+// Admin represents the administrative API used by operators. All requests must
+// authenticate with the configured token as a bearer token.
+type Admin struct {
+	// Services used by Admin
+	AuditService       statsd.AuditService
+	AdjustmentService  statsd.AdjustmentService
+	PrivacyService     statsd.PrivacyService
+	LeaderboardService statsd.LeaderboardService
+	GraphService       statsd.GraphService
+	HeatmapService     statsd.HeatmapService
+
+	// Dependencies
+	logger        *slog.Logger
+	token         string
+	channelGroups statsd.ChannelGroups
+}
+
+// NewAdmin returns a new instance of Admin. The admin API is disabled if token is empty.
+func NewAdmin(logger *slog.Logger, token string, as statsd.AuditService, adjs statsd.AdjustmentService, ps statsd.PrivacyService, ls statsd.LeaderboardService, gs statsd.GraphService, hs statsd.HeatmapService, groups statsd.ChannelGroups) *Admin {
+	return &Admin{
+		logger:             logger,
+		token:              token,
+		channelGroups:      groups,
+		AuditService:       as,
+		AdjustmentService:  adjs,
+		PrivacyService:     ps,
+		LeaderboardService: ls,
+		GraphService:       gs,
+		HeatmapService:     hs,
+	}
+}
+
+// Authenticate is a middleware which rejects requests without the admin token.
+func (a *Admin) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.token == "" {
+			http.NotFound(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HandleAuditLog lists the audit log.
+//
+// The entries may be filtered with the query parameters `team`, `actor`, `action`, `from` and
+// `to` (RFC 3339 timestamps) and `limit`.
+func (a *Admin) HandleAuditLog(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	filter := statsd.AuditFilter{TeamID: query.Get("team"), Actor: query.Get("actor"), Action: query.Get("action")}
+	var err error
+	if v := query.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid from")
+			return fmt.Errorf("HandleAuditLog: %w", err)
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid to")
+			return fmt.Errorf("HandleAuditLog: %w", err)
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return fmt.Errorf("HandleAuditLog: %w", err)
+		}
+	}
+
+	entries, err := a.AuditService.FindAuditEntries(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleAuditLog: %w", err)
+	}
+	writeJSON(w, http.StatusOK, entries)
+	return nil
+}
+
+// HandleLeaderboard returns the leaderboard of a workspace over a period.
+//
+// The workspace is given by the query parameter `team` and the period by `period`, which accepts
+// anything statsd.ParsePeriod does, such as `ytd` for the year to date. It defaults to `all-time`.
+// The leaderboard may be restricted to the reactions within a channel or channel group with `channel`.
+func (a *Admin) HandleLeaderboard(w http.ResponseWriter, r *http.Request) error {
+	teamID, period, err := teamPeriod(r, statsd.NewAllTimePeriod())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	}
+
+	var leaderboard *statsd.Leaderboard
+	if scope := r.URL.Query().Get("channel"); scope != "" {
+		leaderboard, err = a.LeaderboardService.FindChannelLeaderboard(r.Context(), teamID, a.channelGroups.Channels(scope), period)
+	} else {
+		leaderboard, err = a.LeaderboardService.FindPeriodLeaderboard(r.Context(), teamID, period)
+	}
+	if errors.Is(err, statsd.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "no members found")
+		return nil
+	} else if errors.Is(err, statsd.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleLeaderboard: %w", err)
+	}
+	writeJSON(w, http.StatusOK, leaderboard)
+	return nil
+}
+
+// HandleTrend compares the members of a workspace who received the most likes over a period, and the
+// reactions received within the workspace, with the previous period.
+//
+// The workspace is given by the query parameter `team`, the period by `period` and the channel or
+// channel group by `channel` as for HandleLeaderboard, except that the period defaults to the last
+// completed month and can't be `all-time`. The number of top members is given by `limit` (default 5).
+func (a *Admin) HandleTrend(w http.ResponseWriter, r *http.Request) error {
+	lastMonth, err := statsd.NewPeriod(statsd.PeriodMonth, time.Now())
+	if err != nil {
+		return fmt.Errorf("HandleTrend: %w", err)
+	}
+	teamID, period, err := teamPeriod(r, lastMonth.Prev())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	} else if period.Kind == statsd.PeriodAllTime {
+		writeError(w, http.StatusBadRequest, "invalid period")
+		return nil
+	}
+	query := r.URL.Query()
+	limit := 5
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > 100 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return nil
+		}
+	}
+	var channelIDs []string
+	if scope := query.Get("channel"); scope != "" {
+		channelIDs = a.channelGroups.Channels(scope)
+	}
+
+	trend, err := a.LeaderboardService.FindLeaderboardTrend(r.Context(), teamID, channelIDs, period, limit)
+	if errors.Is(err, statsd.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleTrend: %w", err)
+	}
+	writeJSON(w, http.StatusOK, trend)
+	return nil
+}
+
+// HandleGraph exports the graph of who reacted to whom within a workspace over a period.
+//
+// The workspace is given by the query parameter `team`, the period by `period` as for
+// HandleLeaderboard and the format by `format`: `json` (default), `graphml` or `dot`.
+func (a *Admin) HandleGraph(w http.ResponseWriter, r *http.Request) error {
+	teamID, period, err := teamPeriod(r, statsd.NewAllTimePeriod())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	}
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = statsd.GraphFormatJSON
+	}
+	contentType, ok := map[string]string{
+		statsd.GraphFormatJSON:    "application/json",
+		statsd.GraphFormatGraphML: "application/graphml+xml",
+		statsd.GraphFormatDOT:     "text/vnd.graphviz",
+	}[format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid format")
+		return nil
+	}
+
+	g, err := a.GraphService.FindReactionGraph(r.Context(), teamID, period)
+	if errors.Is(err, statsd.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleGraph: %w", err)
+	}
+	w.Header().Set("Content-Type", contentType)
+	if err := g.Encode(w, format); err != nil {
+		return fmt.Errorf("HandleGraph: %w", err)
+	}
+	return nil
+}
+
+// HandleHeatmap returns the number of reactions within a workspace by weekday and hour.
+//
+// The workspace is given by the query parameter `team`, the period by `period` and the channel or
+// channel group by `channel` as for HandleLeaderboard. The heatmap is returned as JSON unless
+// `format` is `png`, in which case it is rendered as an image.
+func (a *Admin) HandleHeatmap(w http.ResponseWriter, r *http.Request) error {
+	teamID, period, err := teamPeriod(r, statsd.NewAllTimePeriod())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	}
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "png" {
+		writeError(w, http.StatusBadRequest, "invalid format")
+		return nil
+	}
+	var channelIDs []string
+	if scope := query.Get("channel"); scope != "" {
+		channelIDs = a.channelGroups.Channels(scope)
+	}
+
+	h, err := a.HeatmapService.FindHeatmap(r.Context(), teamID, channelIDs, period)
+	if errors.Is(err, statsd.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleHeatmap: %w", err)
+	}
+	if format != "png" {
+		writeJSON(w, http.StatusOK, h)
+		return nil
+	}
+	w.Header().Set("Content-Type", "image/png")
+	if err := chart.EncodePNG(w, chart.Heatmap(h)); err != nil {
+		return fmt.Errorf("HandleHeatmap: %w", err)
+	}
+	return nil
+}
+
+// HandleAdjustments lists the adjustments of a workspace.
+//
+// The workspace is given by the query parameter `team` and the adjustments may be
+// restricted with `month` (MM-YYYY) and `member`.
+func (a *Admin) HandleAdjustments(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	filter := statsd.AdjustmentFilter{TeamID: query.Get("team"), SlackUID: query.Get("member")}
+	if filter.TeamID == "" {
+		writeError(w, http.StatusBadRequest, "team required")
+		return nil
+	}
+	if v := query.Get("month"); v != "" {
+		date, err := statsd.NewMonthYearString(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid month")
+			return fmt.Errorf("HandleAdjustments: %w", err)
+		}
+		filter.Date = date
+	}
+
+	adjustments, err := a.AdjustmentService.FindAdjustments(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleAdjustments: %w", err)
+	}
+	writeJSON(w, http.StatusOK, adjustments)
+	return nil
+}
+
+// HandleCreateAdjustment applies a manual adjustment given as a JSON body with the fields
+// `teamID`, `date`, `slackUID`, `metric`, `delta` and `reason`.
+func (a *Admin) HandleCreateAdjustment(w http.ResponseWriter, r *http.Request) error {
+	var adj statsd.Adjustment
+	if err := json.NewDecoder(r.Body).Decode(&adj); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body")
+		return fmt.Errorf("HandleCreateAdjustment: %w", err)
+	}
+	adj.ID, adj.RevertedBy, adj.RevertedAt = 0, "", nil
+	adj.Actor = adminActor(r)
+
+	if err := a.AdjustmentService.CreateAdjustment(r.Context(), &adj); errors.Is(err, statsd.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleCreateAdjustment: %w", err)
+	}
+	a.audit(r.Context(), adj.TeamID, adj.Actor, statsd.AuditActionAdjustmentCreate, strconv.Itoa(adj.ID), nil, adj)
+	writeJSON(w, http.StatusCreated, adj)
+	return nil
+}
+
+// HandleRevertAdjustment reverts the adjustment given by the URL parameter `id`.
+func (a *Admin) HandleRevertAdjustment(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return fmt.Errorf("HandleRevertAdjustment: %w", err)
+	}
+
+	actor := adminActor(r)
+	adj, err := a.AdjustmentService.RevertAdjustment(r.Context(), id, actor)
+	switch {
+	case errors.Is(err, statsd.ErrNotFound):
+		writeError(w, http.StatusNotFound, "adjustment not found")
+		return nil
+	case errors.Is(err, statsd.ErrConflict):
+		writeError(w, http.StatusConflict, "adjustment already reverted")
+		return nil
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleRevertAdjustment: %w", err)
+	}
+	a.audit(r.Context(), adj.TeamID, actor, statsd.AuditActionAdjustmentRevert, strconv.Itoa(adj.ID), nil, adj)
+	writeJSON(w, http.StatusOK, adj)
+	return nil
+}
+
+// HandleOptOuts lists the members of the workspace given by the query parameter `team` who opted out.
+func (a *Admin) HandleOptOuts(w http.ResponseWriter, r *http.Request) error {
+	teamID := r.URL.Query().Get("team")
+	if teamID == "" {
+		writeError(w, http.StatusBadRequest, "team required")
+		return nil
+	}
+	optOuts, err := a.PrivacyService.FindOptOuts(r.Context(), teamID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleOptOuts: %w", err)
+	}
+	writeJSON(w, http.StatusOK, optOuts)
+	return nil
+}
+
+// HandleCreateOptOut opts a member out given as a JSON body with the fields `teamID`, `slackUID`
+// and `countTotals`.
+func (a *Admin) HandleCreateOptOut(w http.ResponseWriter, r *http.Request) error {
+	var o statsd.OptOut
+	if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body")
+		return fmt.Errorf("HandleCreateOptOut: %w", err)
+	}
+	if err := a.PrivacyService.OptOut(r.Context(), &o); errors.Is(err, statsd.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleCreateOptOut: %w", err)
+	}
+	a.audit(r.Context(), o.TeamID, adminActor(r), statsd.AuditActionOptOut, o.SlackUID, nil, o)
+	writeJSON(w, http.StatusOK, o)
+	return nil
+}
+
+// HandleDeleteOptOut opts the member given by the query parameters `team` and `member` back in.
+func (a *Admin) HandleDeleteOptOut(w http.ResponseWriter, r *http.Request) error {
+	teamID, slackUID := r.URL.Query().Get("team"), r.URL.Query().Get("member")
+	if teamID == "" {
+		writeError(w, http.StatusBadRequest, "team required")
+		return nil
+	} else if slackUID == "" {
+		writeError(w, http.StatusBadRequest, "member required")
+		return nil
+	}
+	err := a.PrivacyService.OptIn(r.Context(), teamID, slackUID)
+	if errors.Is(err, statsd.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "opt-out not found")
+		return nil
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleDeleteOptOut: %w", err)
+	}
+	a.audit(r.Context(), teamID, adminActor(r), statsd.AuditActionOptIn, slackUID, nil, nil)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// HandleForget permanently erases the member given by the query parameters `team` and `member`.
+func (a *Admin) HandleForget(w http.ResponseWriter, r *http.Request) error {
+	teamID, slackUID := r.URL.Query().Get("team"), r.URL.Query().Get("member")
+	result, err := a.PrivacyService.ForgetMember(r.Context(), teamID, slackUID)
+	if errors.Is(err, statsd.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return fmt.Errorf("HandleForget: %w", err)
+	}
+	a.audit(r.Context(), teamID, adminActor(r), statsd.AuditActionForget, result.Pseudonym, nil, result)
+	writeJSON(w, http.StatusOK, result)
+	return nil
+}
+
+// audit records an administrative action. Failures are logged but don't fail the action,
+// which has already taken place.
+func (a *Admin) audit(ctx context.Context, teamID, actor, action, target string, before, after any) {
+	e, err := statsd.NewAuditEntry(teamID, actor, action, target, before, after)
+	if err == nil {
+		err = a.AuditService.CreateAuditEntry(ctx, e)
+	}
+	if err != nil {
+		a.logger.Error("record audit entry", slog.String("action", action), slog.String("error", err.Error()))
+	}
+}
+
+// adminActor returns the actor of an admin API request.
+func adminActor(r *http.Request) string {
+	if user := r.Header.Get(AdminUserHeader); user != "" {
+		return AdminActor + ":" + user
+	}
+	return AdminActor
+}
+
+// writeJSON writes v as JSON with the given status code.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// teamPeriod returns the workspace given by the query parameter `team` and the period given by
+// `period`, which defaults to defaultPeriod. The error describes which of them is missing or invalid.
+func teamPeriod(r *http.Request, defaultPeriod statsd.Period) (teamID string, period statsd.Period, err error) {
+	query := r.URL.Query()
+	if teamID = query.Get("team"); teamID == "" {
+		return "", statsd.Period{}, errors.New("team required")
+	}
+	rawPeriod := query.Get("period")
+	if rawPeriod == "" {
+		return teamID, defaultPeriod, nil
+	}
+	if period, err = statsd.ParsePeriod(rawPeriod, time.Now()); err != nil {
+		return "", statsd.Period{}, errors.New("invalid period")
+	}
+	return teamID, period, nil
+}
+
+// writeError writes an error message as JSON with the given status code.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
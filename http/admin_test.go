@@ -0,0 +1,182 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	statsdhttp "github.com/ddritzenhoff/statsd/http"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// AdminToken is the token the admin API of NewAdminServer is protected with.
+const AdminToken = "s3cr3t"
+
+func TestAdmin_Authenticate(t *testing.T) {
+	// Ensure requests bearing the admin token are served.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewAdminServer(t, db, AdminToken)
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, NewAdminRequest(http.MethodGet, "/admin/audit-log", AdminToken))
+		if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure requests without the admin token are rejected.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewAdminServer(t, db, AdminToken)
+
+		for _, r := range []*http.Request{
+			NewAdminRequest(http.MethodGet, "/admin/audit-log", ""),
+			NewAdminRequest(http.MethodGet, "/admin/audit-log", "wrong"),
+			NewAdminRequest(http.MethodPost, "/admin/forget?team=T1ZN1SE2N&member=U1ZN1SE2N", "wrong"),
+		} {
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, r)
+			if got, want := w.Code, http.StatusUnauthorized; got != want {
+				t.Fatalf("%s: Code=%v, want %v", r.URL.Path, got, want)
+			} else if got, want := ErrorMessage(t, w), "invalid admin token"; got != want {
+				t.Fatalf("error=%v, want %v", got, want)
+			}
+		}
+	})
+
+	// Ensure the admin API doesn't exist if no token is configured.
+	t.Run("NoToken", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewAdminServer(t, db, "")
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, NewAdminRequest(http.MethodGet, "/admin/audit-log", ""))
+		if got, want := w.Code, http.StatusNotFound; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+	})
+}
+
+func TestAdmin_Period(t *testing.T) {
+	// Ensure a missing team or an invalid period is rejected by every handler which reads a period.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewAdminServer(t, db, AdminToken)
+
+		for _, path := range []string{"/admin/leaderboard", "/admin/trend", "/admin/graph", "/admin/heatmap"} {
+			for _, tt := range []struct {
+				query string
+				err   string
+			}{
+				{"?period=05-2006", "team required"},
+				{"?team=T1ZN1SE2N&period=13-2006", "invalid period"},
+				{"?team=T1ZN1SE2N&period=2006-W54", "invalid period"},
+				{"?team=T1ZN1SE2N&period=yesterday", "invalid period"},
+			} {
+				w := httptest.NewRecorder()
+				s.ServeHTTP(w, NewAdminRequest(http.MethodGet, path+tt.query, AdminToken))
+				if got, want := w.Code, http.StatusBadRequest; got != want {
+					t.Fatalf("%s%s: Code=%v, want %v", path, tt.query, got, want)
+				} else if got, want := ErrorMessage(t, w), tt.err; got != want {
+					t.Fatalf("%s%s: error=%v, want %v", path, tt.query, got, want)
+				}
+			}
+		}
+	})
+}
+
+func TestAdmin_HandleDeleteOptOut(t *testing.T) {
+	// Ensure a member is opted back in.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewAdminServer(t, db, AdminToken)
+		if err := sqlite.NewPrivacyService(db).OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U1ZN1SE2N"}); err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, NewAdminRequest(http.MethodDelete, "/admin/opt-outs?team=T1ZN1SE2N&member=U1ZN1SE2N", AdminToken))
+		if got, want := w.Code, http.StatusNoContent; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+		if optOuts, err := sqlite.NewPrivacyService(db).FindOptOuts(context.Background(), "T1ZN1SE2N"); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(optOuts), 0; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a missing team or member is rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewAdminServer(t, db, AdminToken)
+
+		for _, tt := range []struct {
+			query string
+			err   string
+		}{
+			{"?member=U1ZN1SE2N", "team required"},
+			{"?team=T1ZN1SE2N", "member required"},
+			{"?team=T1ZN1SE2N&member=", "member required"},
+		} {
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, NewAdminRequest(http.MethodDelete, "/admin/opt-outs"+tt.query, AdminToken))
+			if got, want := w.Code, http.StatusBadRequest; got != want {
+				t.Fatalf("%s: Code=%v, want %v", tt.query, got, want)
+			} else if got, want := ErrorMessage(t, w), tt.err; got != want {
+				t.Fatalf("%s: error=%v, want %v", tt.query, got, want)
+			}
+		}
+	})
+}
+
+// NewAdminServer returns a new server whose admin API is backed by db and protected with token.
+func NewAdminServer(tb testing.TB, db *sqlite.DB, token string) *statsdhttp.Server {
+	tb.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	admin := statsdhttp.NewAdmin(
+		logger,
+		token,
+		sqlite.NewAuditService(db),
+		sqlite.NewAdjustmentService(db),
+		sqlite.NewPrivacyService(db),
+		sqlite.NewLeaderboardService(db),
+		sqlite.NewGraphService(db),
+		sqlite.NewHeatmapService(db),
+		nil,
+	)
+	return statsdhttp.NewServer(logger, "", nil, statsdhttp.NewHealthChecker(db, nil, sqlite.NewAuditService(db), false), admin)
+}
+
+// NewAdminRequest returns a request to the admin API bearing token, if any.
+func NewAdminRequest(method string, target string, token string) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+// ErrorMessage returns the error message of a JSON error response. Fatal on error.
+func ErrorMessage(tb testing.TB, w *httptest.ResponseRecorder) string {
+	tb.Helper()
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		tb.Fatal(err)
+	}
+	return body.Error
+}
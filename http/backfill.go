@@ -0,0 +1,354 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/slack-go/slack"
+)
+
+// backfillPageSize is the number of messages requested per conversations.history page.
+const backfillPageSize = 200
+
+// Backfiller replays the reaction history of Slack channels into the ReactionService.
+type Backfiller struct {
+	// Services used by Backfiller
+	ReactionService  statsd.ReactionService
+	MemberService    statsd.MemberService
+	BackfillService  statsd.BackfillService
+	WorkspaceService statsd.WorkspaceService
+
+	// Dependencies
+	logger        *slog.Logger
+	channelFilter statsd.ChannelFilter
+}
+
+// NewBackfiller creates a new instance of Backfiller.
+func NewBackfiller(logger *slog.Logger, rs statsd.ReactionService, ms statsd.MemberService, bs statsd.BackfillService, ws statsd.WorkspaceService, filter statsd.ChannelFilter) *Backfiller {
+	return &Backfiller{
+		logger:           logger,
+		channelFilter:    filter,
+		ReactionService:  rs,
+		MemberService:    ms,
+		BackfillService:  bs,
+		WorkspaceService: ws,
+	}
+}
+
+// backfill represents the state of a single Backfill run.
+type backfill struct {
+	*Backfiller
+	teamID string
+	client *slack.Client
+
+	// Types of the conversations listed by memberChannelIDs.
+	channelTypes map[string]statsd.ChannelType
+}
+
+// Backfill records the reactions of all messages posted within a workspace between the start of from and the end of to.
+//
+// If no channel IDs are given, every conversation the bot is a member of is backfilled. Channels
+// rejected by the channel filter are skipped, as they are for live events. Progress is
+// checkpointed after every page of history, so an interrupted backfill resumes where it left off.
+//
+// The history doesn't include when a reaction was left, so reactions are dated by the message they
+// were left on. A reaction left after the end of the month its message was posted in counts towards
+// the month of the message, unlike with live counting, and streaks and the heatmap place it at the
+// time of the message.
+//
+// Months whose counts aren't backed by recorded reactions, such as those counted before reactions
+// were recorded, would be counted twice. Unless recompute is set, Backfill returns ErrInvalid for
+// them. Otherwise their counts are rebuilt from the recorded reactions once the backfill is done.
+func (bf *Backfiller) Backfill(ctx context.Context, teamID string, from statsd.MonthYear, to statsd.MonthYear, channelIDs []string, recompute bool) error {
+	workspace, err := bf.WorkspaceService.FindWorkspace(ctx, teamID)
+	if err != nil {
+		return fmt.Errorf("Backfill: %w", err)
+	}
+	b := &backfill{Backfiller: bf, teamID: workspace.TeamID, client: newSlackClient(workspace.BotToken), channelTypes: make(map[string]statsd.ChannelType)}
+
+	oldest, err := from.Time()
+	if err != nil {
+		return fmt.Errorf("Backfill: %w", err)
+	}
+	latest, err := to.Time()
+	if err != nil {
+		return fmt.Errorf("Backfill: %w", err)
+	}
+	latest = latest.AddDate(0, 1, 0)
+	if !oldest.Before(latest) {
+		return fmt.Errorf("Backfill: from must not be after to %w", statsd.ErrInvalid)
+	}
+
+	var months []statsd.MonthYear
+	for t := oldest; t.Before(latest); t = t.AddDate(0, 1, 0) {
+		months = append(months, statsd.NewMonthYear(t))
+	}
+	counted, err := statsd.UnbackedMonths(ctx, b.MemberService, b.teamID, months)
+	if err != nil {
+		return fmt.Errorf("Backfill: %w", err)
+	}
+	if len(counted) > 0 && !recompute {
+		return fmt.Errorf("Backfill: counts of %v aren't backed by recorded reactions and must be recomputed %w", counted, statsd.ErrInvalid)
+	}
+
+	if len(channelIDs) == 0 {
+		if channelIDs, err = b.memberChannelIDs(ctx); err != nil {
+			return fmt.Errorf("Backfill: %w", err)
+		}
+	}
+
+	for _, channelID := range channelIDs {
+		if ok, err := b.allowChannel(ctx, channelID); err != nil {
+			return fmt.Errorf("Backfill %s: %w", channelID, err)
+		} else if !ok {
+			continue
+		}
+		if err := b.backfillChannel(ctx, channelID, from, to, oldest, latest); err != nil {
+			return fmt.Errorf("Backfill %s: %w", channelID, err)
+		}
+	}
+
+	for _, month := range counted {
+		diffs, err := b.MemberService.RecomputeMembers(ctx, b.teamID, month, false)
+		if err != nil {
+			return fmt.Errorf("Backfill RecomputeMembers: %w", err)
+		}
+		b.logger.Info("recomputed month", slog.String("month", month.String()), slog.Int("changed", len(diffs)))
+	}
+	return nil
+}
+
+// memberChannelIDs returns the IDs of all conversations the bot is a member of.
+func (b *backfill) memberChannelIDs(ctx context.Context) ([]string, error) {
+	var channelIDs []string
+	params := &slack.GetConversationsParameters{
+		Limit: backfillPageSize,
+		Types: []string{"public_channel", "private_channel", "mpim", "im"},
+	}
+	for {
+		var channels []slack.Channel
+		var cursor string
+		err := withRetry(ctx, func() (err error) {
+			channels, cursor, err = b.client.GetConversationsContext(ctx, params)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("memberChannelIDs GetConversations: %w", err)
+		}
+		for _, c := range channels {
+			if c.IsMember || c.IsIM {
+				channelIDs = append(channelIDs, c.ID)
+				b.channelTypes[c.ID] = conversationType(&c)
+			}
+		}
+		if cursor == "" {
+			return channelIDs, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// allowChannel reports whether reactions within a channel are counted according to the channel filter.
+func (b *backfill) allowChannel(ctx context.Context, channelID string) (bool, error) {
+	ok, reason, err := b.channelFilter.Allows(channelID, func() (statsd.ChannelType, error) {
+		return b.channelType(ctx, channelID)
+	})
+	if err != nil {
+		return false, fmt.Errorf("allowChannel: %w", err)
+	}
+	if !ok {
+		b.logger.Info("skipping filtered channel", slog.String("channel", channelID), slog.String("reason", reason))
+	}
+	return ok, nil
+}
+
+// channelType returns the type of a channel, looking it up through the Slack API unless it was listed
+// by memberChannelIDs.
+func (b *backfill) channelType(ctx context.Context, channelID string) (statsd.ChannelType, error) {
+	if typ, ok := b.channelTypes[channelID]; ok {
+		return typ, nil
+	}
+	var channel *slack.Channel
+	err := withRetry(ctx, func() (err error) {
+		channel, err = b.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channelID})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("GetConversationInfo: %w", err)
+	}
+	return conversationType(channel), nil
+}
+
+// backfillChannel pages through the history of a single channel, resuming from its checkpoint.
+func (b *backfill) backfillChannel(ctx context.Context, channelID string, from statsd.MonthYear, to statsd.MonthYear, oldest time.Time, latest time.Time) error {
+	checkpoint, err := b.BackfillService.FindBackfillCheckpoint(ctx, b.teamID, channelID, from, to)
+	if errors.Is(err, statsd.ErrNotFound) {
+		checkpoint = &statsd.BackfillCheckpoint{TeamID: b.teamID, ChannelID: channelID, From: from, To: to}
+	} else if err != nil {
+		return err
+	}
+	if checkpoint.Done {
+		b.logger.Info("channel already backfilled", slog.String("channel", channelID))
+		return nil
+	}
+
+	b.logger.Info("backfilling channel", slog.String("channel", channelID), slog.String("from", from.String()), slog.String("to", to.String()))
+	for {
+		params := &slack.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Cursor:    checkpoint.Cursor,
+			Limit:     backfillPageSize,
+			Oldest:    formatTimestamp(oldest),
+			Latest:    formatTimestamp(latest),
+		}
+		var history *slack.GetConversationHistoryResponse
+		err := withRetry(ctx, func() (err error) {
+			history, err = b.client.GetConversationHistoryContext(ctx, params)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("GetConversationHistory: %w", err)
+		}
+
+		for _, msg := range history.Messages {
+			if err := b.backfillMessage(ctx, channelID, msg); err != nil {
+				return err
+			}
+			if msg.ReplyCount > 0 {
+				if err := b.backfillReplies(ctx, channelID, msg.Timestamp); err != nil {
+					return err
+				}
+			}
+		}
+
+		checkpoint.Cursor = history.ResponseMetaData.NextCursor
+		checkpoint.Done = !history.HasMore || checkpoint.Cursor == ""
+		if err := b.BackfillService.SaveBackfillCheckpoint(ctx, checkpoint); err != nil {
+			return err
+		}
+		if checkpoint.Done {
+			b.logger.Info("backfilled channel", slog.String("channel", channelID))
+			return nil
+		}
+	}
+}
+
+// backfillReplies records the reactions of all replies within a thread.
+func (b *backfill) backfillReplies(ctx context.Context, channelID string, threadTS string) error {
+	params := &slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: threadTS,
+		Limit:     backfillPageSize,
+	}
+	for {
+		var replies []slack.Message
+		var hasMore bool
+		var cursor string
+		err := withRetry(ctx, func() (err error) {
+			replies, hasMore, cursor, err = b.client.GetConversationRepliesContext(ctx, params)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("GetConversationReplies: %w", err)
+		}
+		for _, reply := range replies {
+			// The parent message is part of every page and was already recorded from the history.
+			if reply.Timestamp == threadTS {
+				continue
+			}
+			if err := b.backfillMessage(ctx, channelID, reply); err != nil {
+				return err
+			}
+		}
+		if !hasMore || cursor == "" {
+			return nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// backfillMessage records every counted reaction on a message as if it had been received as a live event.
+func (b *backfill) backfillMessage(ctx context.Context, channelID string, msg slack.Message) error {
+	if len(msg.Reactions) == 0 {
+		return nil
+	}
+	reactedAt, err := statsd.ParseTimestamp(msg.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	for _, reaction := range msg.Reactions {
+		if !statsd.IsCountedReaction(reaction.Name) {
+			continue
+		}
+
+		// conversations.history truncates the list of users on popular reactions.
+		users := reaction.Users
+		if reaction.Count > len(users) {
+			if users, err = b.reactionUsers(ctx, channelID, msg.Timestamp, reaction.Name); err != nil {
+				return err
+			}
+		}
+
+		for _, user := range users {
+			err := countReaction(ctx, b.logger, b.ReactionService, &statsd.Reaction{
+				TeamID:     b.teamID,
+				Date:       statsd.NewMonthYear(reactedAt),
+				ChannelID:  channelID,
+				MessageTS:  msg.Timestamp,
+				ReactorUID: user,
+				AuthorUID:  msg.User,
+				Name:       reaction.Name,
+				ReactedAt:  reactedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reactionUsers returns the full list of users who left the named reaction on a message.
+func (b *backfill) reactionUsers(ctx context.Context, channelID string, messageTS string, name string) ([]string, error) {
+	var reactions []slack.ItemReaction
+	err := withRetry(ctx, func() (err error) {
+		reactions, err = b.client.GetReactionsContext(ctx, slack.NewRefToMessage(channelID, messageTS), slack.GetReactionsParameters{Full: true})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetReactions: %w", err)
+	}
+	for _, reaction := range reactions {
+		if reaction.Name == name {
+			return reaction.Users, nil
+		}
+	}
+	return nil, nil
+}
+
+// withRetry calls fn until it no longer fails due to Slack rate limiting, waiting as long as Slack asks in between.
+func withRetry(ctx context.Context, fn func() error) error {
+	for {
+		err := fn()
+		var rateLimitedErr *slack.RateLimitedError
+		if !errors.As(err, &rateLimitedErr) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rateLimitedErr.RetryAfter):
+		}
+	}
+}
+
+// formatTimestamp converts a time.Time into a Slack timestamp.
+func formatTimestamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10) + ".000000"
+}
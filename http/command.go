@@ -0,0 +1,128 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/slack-go/slack"
+)
+
+// commandUsage describes the subcommands of the /statsd slash command.
+const commandUsage = "Usage:\n" +
+	"• `/statsd me` shows your streaks of months in the top members and of weeks receiving likes.\n" +
+	"• `/statsd optout` hides you from leaderboards and monthly updates. Your reactions still count towards the workspace totals.\n" +
+	"• `/statsd optout all` also leaves your reactions out of the workspace totals.\n" +
+	"• `/statsd optin` shows you in leaderboards again.\n" +
+	"• `/statsd forgetme` permanently erases everything recorded about you."
+
+// HandleCommand handles the /statsd slash command.
+//
+// The command is answered with an ephemeral message so only the invoking user sees it.
+func (s *Slack) HandleCommand(w http.ResponseWriter, r *http.Request) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return fmt.Errorf("HandleCommand: %w", err)
+	}
+	sv, err := slack.NewSecretsVerifier(r.Header, s.signingSecret)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return fmt.Errorf("HandleCommand: %w", err)
+	}
+	if _, err := sv.Write(body); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return fmt.Errorf("HandleCommand: %w", err)
+	}
+	if err := sv.Ensure(); err != nil {
+		signatureFailuresTotal.Inc()
+		w.WriteHeader(http.StatusUnauthorized)
+		return fmt.Errorf("HandleCommand: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	cmd, err := slack.SlashCommandParse(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return fmt.Errorf("HandleCommand: %w", err)
+	}
+
+	actor := "slack:" + cmd.UserID
+	text := strings.Join(strings.Fields(cmd.Text), " ")
+	switch text {
+	case "me":
+		streaks, err := s.StreakService.FindMemberStreaks(r.Context(), cmd.TeamID, cmd.UserID, time.Now())
+		if err != nil {
+			writeCommandResponse(w, "Sorry, looking up your streaks failed. Please try again later.")
+			return fmt.Errorf("HandleCommand FindMemberStreaks: %w", err)
+		}
+		writeCommandResponse(w, fmt.Sprintf("Your streaks:\n• Top %d: %s\n• Likes received: %s",
+			statsd.StreakTopMembers, describeStreak(streaks.TopMonths, "month"), describeStreak(streaks.LikedWeeks, "week")))
+	case "optout", "optout all":
+		o := &statsd.OptOut{TeamID: cmd.TeamID, SlackUID: cmd.UserID, CountTotals: text == "optout"}
+		if err := s.PrivacyService.OptOut(r.Context(), o); err != nil {
+			writeCommandResponse(w, "Sorry, opting you out failed. Please try again later.")
+			return fmt.Errorf("HandleCommand OptOut: %w", err)
+		}
+		s.audit(r.Context(), cmd.TeamID, actor, statsd.AuditActionOptOut, cmd.UserID, nil, o)
+		if o.CountTotals {
+			writeCommandResponse(w, "You no longer appear in leaderboards or monthly updates. Your reactions still count towards the workspace totals.")
+		} else {
+			writeCommandResponse(w, "You no longer appear in leaderboards, monthly updates or the workspace totals.")
+		}
+	case "optin":
+		err := s.PrivacyService.OptIn(r.Context(), cmd.TeamID, cmd.UserID)
+		if errors.Is(err, statsd.ErrNotFound) {
+			writeCommandResponse(w, "You haven't opted out.")
+			return nil
+		} else if err != nil {
+			writeCommandResponse(w, "Sorry, opting you in failed. Please try again later.")
+			return fmt.Errorf("HandleCommand OptIn: %w", err)
+		}
+		s.audit(r.Context(), cmd.TeamID, actor, statsd.AuditActionOptIn, cmd.UserID, nil, nil)
+		writeCommandResponse(w, "You appear in leaderboards and monthly updates again.")
+	case "forgetme":
+		result, err := s.PrivacyService.ForgetMember(r.Context(), cmd.TeamID, cmd.UserID)
+		if err != nil {
+			writeCommandResponse(w, "Sorry, erasing your data failed. Please try again later.")
+			return fmt.Errorf("HandleCommand ForgetMember: %w", err)
+		}
+		// The member asked to be forgotten, so they are only named by their pseudonym.
+		s.audit(r.Context(), cmd.TeamID, "slack:"+result.Pseudonym, statsd.AuditActionForget, result.Pseudonym, nil, result)
+		s.logger.Info("forgot member", slog.String("team", cmd.TeamID))
+		writeCommandResponse(w, "Everything recorded about you has been erased. New reactions are recorded again unless you opt out.")
+	default:
+		writeCommandResponse(w, commandUsage)
+	}
+	return nil
+}
+
+// describeStreak describes the current and the longest run of a streak of months or weeks.
+func describeStreak(streak statsd.Streak, unit string) string {
+	if streak.Longest == 0 {
+		return "none yet."
+	}
+	current := "not running"
+	if streak.Current > 0 {
+		current = plural(streak.Current, unit) + " running"
+	}
+	return fmt.Sprintf("%s. Longest: %s, up to %s.", current, plural(streak.Longest, unit), streak.LongestEnd.Title())
+}
+
+// plural returns n followed by unit, which is pluralised unless n is 1.
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// writeCommandResponse answers a slash command with an ephemeral message.
+func writeCommandResponse(w http.ResponseWriter, text string) {
+	writeJSON(w, http.StatusOK, &slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: text})
+}
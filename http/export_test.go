@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SetSlackAPI serves the calls to the Slack Web API, including the OAuth exchange, with handler until the end of the test.
+func SetSlackAPI(tb testing.TB, handler http.Handler) {
+	tb.Helper()
+	srv := httptest.NewServer(handler)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	prev := slackHTTPClient
+	slackHTTPClient = &http.Client{Transport: &slackTransport{next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})}}
+	tb.Cleanup(func() {
+		slackHTTPClient = prev
+		srv.Close()
+	})
+}
+
+// roundTripperFunc is an adapter to allow the use of ordinary functions as http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// FormatGrowth exposes formatGrowth for testing.
+var FormatGrowth = formatGrowth
+
+// SlackAPIErrors returns the value of statsd_slack_api_errors_total for method.
+func SlackAPIErrors(tb testing.TB, method string) float64 {
+	tb.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != "statsd_slack_api_errors_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "method" && l.GetValue() == method {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
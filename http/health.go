@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+// HealthCheckTimeout is the time given to the readiness checks to complete.
+const HealthCheckTimeout = 5 * time.Second
+
+// Statuses reported by health checks.
+const (
+	healthOK      = "ok"
+	healthError   = "error"
+	healthUnknown = "unknown"
+)
+
+// Database represents the database whose status is reported by the readiness check.
+type Database interface {
+	// SchemaVersion returns the version of the database schema and the latest version known to this build.
+	SchemaVersion(ctx context.Context) (version int, latest int, err error)
+}
+
+// HealthReport represents the status of statsd and its dependencies.
+type HealthReport struct {
+	Status string                  `json:"status"`
+	Checks map[string]*HealthCheck `json:"checks,omitempty"`
+}
+
+// HealthCheck represents the status of a single dependency.
+type HealthCheck struct {
+	Status  string         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// HealthChecker checks the dependencies statsd needs to serve requests.
+type HealthChecker struct {
+	WorkspaceService statsd.WorkspaceService
+	AuditService     statsd.AuditService
+
+	// Dependencies
+	db         Database
+	checkSlack bool
+}
+
+// NewHealthChecker returns a new instance of HealthChecker. If checkSlack is set, the bot
+// token of every installed workspace is verified with Slack's auth.test on every check.
+func NewHealthChecker(db Database, ws statsd.WorkspaceService, as statsd.AuditService, checkSlack bool) *HealthChecker {
+	return &HealthChecker{
+		WorkspaceService: ws,
+		AuditService:     as,
+		db:               db,
+		checkSlack:       checkSlack,
+	}
+}
+
+// Check returns the status of the database, the last monthly update and, if enabled, the Slack
+// tokens. The report is healthy only if every check is.
+func (hc *HealthChecker) Check(ctx context.Context) *HealthReport {
+	report := &HealthReport{Status: healthOK, Checks: make(map[string]*HealthCheck)}
+	report.Checks["database"] = hc.checkDatabase(ctx)
+	report.Checks["monthlyUpdate"] = hc.checkMonthlyUpdate(ctx)
+	if hc.checkSlack {
+		report.Checks["slack"] = hc.checkSlackTokens(ctx)
+	}
+	for _, c := range report.Checks {
+		if c.Status == healthError {
+			report.Status = healthError
+		}
+	}
+	return report
+}
+
+// checkDatabase verifies the database is reachable and all migrations have been applied.
+func (hc *HealthChecker) checkDatabase(ctx context.Context) *HealthCheck {
+	version, latest, err := hc.db.SchemaVersion(ctx)
+	if err != nil {
+		return &HealthCheck{Status: healthError, Error: err.Error()}
+	}
+	c := &HealthCheck{Status: healthOK, Details: map[string]any{"schemaVersion": version, "latestSchemaVersion": latest}}
+	if version != latest {
+		c.Status = healthError
+		c.Error = fmt.Sprintf("schema version %d, want %d", version, latest)
+	}
+	return c
+}
+
+// checkMonthlyUpdate reports when the monthly update was last published according to the audit log.
+// The update is triggered externally, so a missing run is reported without affecting readiness.
+func (hc *HealthChecker) checkMonthlyUpdate(ctx context.Context) *HealthCheck {
+	entries, err := hc.AuditService.FindAuditEntries(ctx, statsd.AuditFilter{Action: statsd.AuditActionMonthlyUpdate, Limit: 1})
+	if err != nil {
+		return &HealthCheck{Status: healthUnknown, Error: err.Error()}
+	} else if len(entries) == 0 {
+		return &HealthCheck{Status: healthUnknown}
+	}
+	return &HealthCheck{Status: healthOK, Details: map[string]any{"lastSuccess": entries[0].CreatedAt}}
+}
+
+// checkSlackTokens verifies the bot token of every installed workspace is still valid.
+func (hc *HealthChecker) checkSlackTokens(ctx context.Context) *HealthCheck {
+	workspaces, err := hc.WorkspaceService.FindWorkspaces(ctx)
+	if err != nil {
+		return &HealthCheck{Status: healthError, Error: err.Error()}
+	}
+	for _, w := range workspaces {
+		if _, err := newSlackClient(w.BotToken).AuthTestContext(ctx); err != nil {
+			return &HealthCheck{Status: healthError, Error: fmt.Sprintf("%s: %s", w.TeamID, err)}
+		}
+	}
+	return &HealthCheck{Status: healthOK, Details: map[string]any{"workspaces": len(workspaces)}}
+}
+
+// handleHealthz reports that the process is alive. It doesn't check any dependencies so
+// that an unavailable dependency doesn't get the process restarted.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &HealthReport{Status: healthOK})
+}
+
+// handleReadyz reports whether statsd is ready to serve requests. It responds with
+// 503 Service Unavailable if a dependency is unhealthy.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), HealthCheckTimeout)
+	defer cancel()
+	report := s.healthChecker.Check(ctx)
+
+	code := http.StatusOK
+	if report.Status != healthOK {
+		s.logger.Error("not ready", slog.Any("checks", report.Checks))
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
@@ -0,0 +1,141 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	statsdhttp "github.com/ddritzenhoff/statsd/http"
+	"github.com/ddritzenhoff/statsd/sqlite"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestHealthChecker_Check(t *testing.T) {
+	// Ensure a migrated database is healthy and the last monthly update is read from the audit log.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		as := sqlite.NewAuditService(db)
+		hc := statsdhttp.NewHealthChecker(db, nil, as, false)
+
+		if report := hc.Check(context.Background()); report.Status != "ok" {
+			t.Fatalf("Status=%v, want ok", report.Status)
+		} else if got, want := report.Checks["monthlyUpdate"].Status, "unknown"; got != want {
+			t.Fatalf("monthlyUpdate=%v, want %v", got, want)
+		}
+
+		e := &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "http:127.0.0.1", Action: statsd.AuditActionMonthlyUpdate}
+		if err := as.CreateAuditEntry(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+		if err := as.CreateAuditEntry(context.Background(), &statsd.AuditEntry{Actor: "cli:alice", Action: statsd.AuditActionRecompute}); err != nil {
+			t.Fatal(err)
+		}
+		if c := hc.Check(context.Background()).Checks["monthlyUpdate"]; c.Status != "ok" {
+			t.Fatalf("monthlyUpdate=%v, want ok", c.Status)
+		} else if got, want := c.Details["lastSuccess"], e.CreatedAt; got != want {
+			t.Fatalf("lastSuccess=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure an unreachable database makes the report unhealthy.
+	t.Run("ErrDatabase", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		hc := statsdhttp.NewHealthChecker(&Database{err: errors.New("database is locked")}, nil, sqlite.NewAuditService(db), false)
+
+		if report := hc.Check(context.Background()); report.Status != "error" {
+			t.Fatalf("Status=%v, want error", report.Status)
+		} else if got, want := report.Checks["database"].Error, "database is locked"; got != want {
+			t.Fatalf("Error=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a database which isn't fully migrated makes the report unhealthy.
+	t.Run("ErrSchemaVersion", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		hc := statsdhttp.NewHealthChecker(&Database{version: 1, latest: 2}, nil, sqlite.NewAuditService(db), false)
+
+		if report := hc.Check(context.Background()); report.Status != "error" {
+			t.Fatalf("Status=%v, want error", report.Status)
+		}
+	})
+}
+
+func TestServer_Readyz(t *testing.T) {
+	// Ensure readiness is reported with 200 OK if every dependency is healthy.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewServer(statsdhttp.NewHealthChecker(db, nil, sqlite.NewAuditService(db), false))
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+		var report statsdhttp.HealthReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Status, "ok"; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a database failure is reported with 503 Service Unavailable.
+	t.Run("ErrDatabase", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewServer(statsdhttp.NewHealthChecker(&Database{err: errors.New("database is locked")}, nil, sqlite.NewAuditService(db), false))
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if got, want := w.Code, http.StatusServiceUnavailable; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+	})
+}
+
+// Database represents a test implementation of http.Database.
+type Database struct {
+	version int
+	latest  int
+	err     error
+}
+
+// SchemaVersion returns the configured versions or error.
+func (db *Database) SchemaVersion(ctx context.Context) (int, int, error) {
+	return db.version, db.latest, db.err
+}
+
+// MustOpenDB returns a new, open in-memory DB. Fatal on error.
+func MustOpenDB(tb testing.TB) *sqlite.DB {
+	tb.Helper()
+	db := sqlite.NewDB("file::memory:?cache=shared")
+	db.EncryptionKeys = [][]byte{[]byte("0123456789abcdef0123456789abcdef")}
+	if err := db.Open(); err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+// MustCloseDB closes the DB. Fatal on error.
+func MustCloseDB(tb testing.TB, db *sqlite.DB) {
+	tb.Helper()
+	if err := db.Close(); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// NewServer returns a new server which checks its readiness with hc.
+func NewServer(hc *statsdhttp.HealthChecker) *statsdhttp.Server {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return statsdhttp.NewServer(logger, "", nil, hc, statsdhttp.NewAdmin(logger, "", nil, nil, nil, nil, nil, nil, nil))
+}
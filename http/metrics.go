@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "statsd_http_requests_total",
+		Help: "HTTP requests handled by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "statsd_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	eventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "statsd_events_total",
+		Help: "Slack events handled by type and outcome.",
+	}, []string{"type", "outcome"})
+
+	eventsFilteredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "statsd_events_filtered_total",
+		Help: "Reaction events which weren't counted because of the channel filter by reason.",
+	}, []string{"reason"})
+
+	reactionsCountedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "statsd_reactions_counted_total",
+		Help: "Reactions added to or removed from the counts by metric.",
+	}, []string{"metric", "action"})
+
+	signatureFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "statsd_signature_verification_failures_total",
+		Help: "Slack requests rejected because their signature couldn't be verified.",
+	})
+
+	slackAPIDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "statsd_slack_api_duration_seconds",
+		Help:    "Latency of Slack Web API calls by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	slackAPIErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "statsd_slack_api_errors_total",
+		Help: "Slack Web API calls which failed, were rate limited or answered with ok false by method.",
+	}, []string{"method"})
+)
+
+// Event outcomes recorded by statsd_events_total.
+const (
+	outcomeOK           = "ok"
+	outcomeError        = "error"
+	outcomeIgnored      = "ignored"
+	outcomeFiltered     = "filtered"
+	outcomeUnauthorized = "unauthorized"
+)
+
+// instrument is a middleware which records the number and latency of requests by their chi route pattern.
+func instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" {
+			route = "unmatched"
+		}
+		code := ww.Status()
+		if code == 0 {
+			code = http.StatusOK
+		}
+		requestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(code)).Inc()
+		requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// metricName returns the metric a reaction counts towards.
+func metricName(reaction string) string {
+	if reaction == statsd.ThumbsDown {
+		return statsd.MetricDislikes
+	}
+	return statsd.MetricLikes
+}
+
+// slackHTTPClient is used for all calls to the Slack Web API so their latency and errors are recorded.
+var slackHTTPClient = &http.Client{Transport: &slackTransport{next: http.DefaultTransport}}
+
+// newSlackClient returns a Slack client authenticated with token which records its calls.
+func newSlackClient(token string) *slack.Client {
+	return slack.New(token, slack.OptionHTTPClient(slackHTTPClient))
+}
+
+// slackTransport traces Slack Web API calls and records their latency and errors by their method, e.g. chat.postMessage.
+// Slack reports most errors with a 200 response whose body has ok set to false, so the body of JSON responses is inspected
+// and put back for the Slack client.
+type slackTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *slackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := strings.TrimPrefix(req.URL.Path, "/api/")
+	ctx, span := tracer.Start(req.Context(), "slack."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("slack.method", method),
+	))
+	defer span.End()
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	slackAPIDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		slackAPIErrorsTotal.WithLabelValues(method).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if resp.StatusCode >= http.StatusBadRequest {
+		slackAPIErrorsTotal.WithLabelValues(method).Inc()
+		span.SetStatus(codes.Error, resp.Status)
+	} else if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		buf, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			slackAPIErrorsTotal.WithLabelValues(method).Inc()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(buf))
+
+		var body struct {
+			OK    *bool  `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(buf, &body); err == nil && body.OK != nil && !*body.OK {
+			slackAPIErrorsTotal.WithLabelValues(method).Inc()
+			span.SetStatus(codes.Error, body.Error)
+		}
+	}
+	return resp, err
+}
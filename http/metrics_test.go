@@ -0,0 +1,37 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	statsdhttp "github.com/ddritzenhoff/statsd/http"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestSlackTransport(t *testing.T) {
+	// Ensure Slack API calls answered with 200 OK but ok false are counted as errors and their error still reaches the caller.
+	t.Run("ErrNotOK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ws := sqlite.NewWorkspaceService(db)
+		if err := ws.SaveWorkspace(context.Background(), &statsd.Workspace{TeamID: "T1ZN1SE2N", TeamName: "Acme", BotUserID: "U0ZN1SE2N", BotToken: "xoxb-1"}); err != nil {
+			t.Fatal(err)
+		}
+		statsdhttp.SetSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+		}))
+
+		before := statsdhttp.SlackAPIErrors(t, "auth.test")
+		hc := statsdhttp.NewHealthChecker(db, ws, sqlite.NewAuditService(db), true)
+		if c := hc.Check(context.Background()).Checks["slack"]; c.Status != "error" {
+			t.Fatalf("Status=%v, want error", c.Status)
+		} else if got, want := c.Error, "T1ZN1SE2N: invalid_auth"; got != want {
+			t.Fatalf("Error=%v, want %v", got, want)
+		} else if got, want := statsdhttp.SlackAPIErrors(t, "auth.test")-before, 1.0; got != want {
+			t.Fatalf("errors=%v, want %v", got, want)
+		}
+	})
+}
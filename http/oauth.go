@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/slack-go/slack"
+)
+
+// botScopes are the OAuth scopes requested for the bot token of an installation.
+var botScopes = []string{
+	"channels:history",
+	"channels:read",
+	"chat:write",
+	"files:write",
+	"groups:history",
+	"groups:read",
+	"im:history",
+	"im:read",
+	"mpim:history",
+	"mpim:read",
+	"reactions:read",
+	"users:read",
+}
+
+// oauthStateCookie is the name of the cookie which protects the OAuth flow against cross-site request forgery.
+const oauthStateCookie = "statsd_oauth_state"
+
+// OAuthConfig represents the credentials of the Slack app used to install statsd into workspaces.
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// HandleInstall redirects the user to Slack to install statsd into their workspace.
+func (s *Slack) HandleInstall(w http.ResponseWriter, r *http.Request) error {
+	if s.oauth.ClientID == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return errors.New("HandleInstall: no OAuth client ID configured")
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return fmt.Errorf("HandleInstall: %w", err)
+	}
+	state := hex.EncodeToString(buf)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/slack/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := url.Values{}
+	query.Set("client_id", s.oauth.ClientID)
+	query.Set("scope", strings.Join(botScopes, ","))
+	query.Set("redirect_uri", s.oauth.RedirectURL)
+	query.Set("state", state)
+	http.Redirect(w, r, "https://slack.com/oauth/v2/authorize?"+query.Encode(), http.StatusFound)
+	return nil
+}
+
+// HandleOAuthCallback completes an installation by exchanging the code granted by Slack for a bot token.
+func (s *Slack) HandleOAuthCallback(w http.ResponseWriter, r *http.Request) error {
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(r.URL.Query().Get("state"))) != 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		return errors.New("HandleOAuthCallback: invalid state")
+	}
+	if errCode := r.URL.Query().Get("error"); errCode != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return fmt.Errorf("HandleOAuthCallback: installation denied: %s", errCode)
+	}
+
+	resp, err := slack.GetOAuthV2ResponseContext(r.Context(), slackHTTPClient, s.oauth.ClientID, s.oauth.ClientSecret, r.URL.Query().Get("code"), s.oauth.RedirectURL)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return fmt.Errorf("HandleOAuthCallback GetOAuthV2Response: %w", err)
+	}
+
+	workspace := &statsd.Workspace{
+		TeamID:    resp.Team.ID,
+		TeamName:  resp.Team.Name,
+		BotUserID: resp.BotUserID,
+		BotToken:  resp.AccessToken,
+	}
+	if err := s.WorkspaceService.SaveWorkspace(r.Context(), workspace); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return fmt.Errorf("HandleOAuthCallback SaveWorkspace: %w", err)
+	}
+	s.audit(r.Context(), workspace.TeamID, "slack:"+resp.AuthedUser.ID, statsd.AuditActionWorkspaceInstall, workspace.TeamID, nil, workspace)
+	s.logger.Info("installed workspace", slog.String("team", workspace.TeamID), slog.String("name", workspace.TeamName))
+
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Path: "/slack/", MaxAge: -1})
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "statsd has been installed to %s.\n", workspace.TeamName)
+	return nil
+}
+
+// RegisterWorkspace installs the workspace a bot token belongs to. It allows deployments
+// configured with a single bot token to keep working alongside OAuth installations.
+func RegisterWorkspace(ctx context.Context, ws statsd.WorkspaceService, botToken string) (*statsd.Workspace, error) {
+	resp, err := newSlackClient(botToken).AuthTestContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("RegisterWorkspace AuthTest: %w", err)
+	}
+	workspace := &statsd.Workspace{
+		TeamID:    resp.TeamID,
+		TeamName:  resp.Team,
+		BotUserID: resp.UserID,
+		BotToken:  botToken,
+	}
+	if err := ws.SaveWorkspace(ctx, workspace); err != nil {
+		return nil, fmt.Errorf("RegisterWorkspace: %w", err)
+	}
+	return workspace, nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // ShutdownTimeout is the time given for outstanding requests to finish before shutdown.
@@ -22,14 +23,21 @@ type Server struct {
 	server *http.Server
 	router chi.Router
 
+	// Base context of all requests. It is canceled once the shutdown timeout
+	// has passed so that outstanding requests abort their queries.
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	// Dependencies
-	addr         string
-	slackService Slacker
-	logger       *slog.Logger
+	addr          string
+	slackService  Slacker
+	healthChecker *HealthChecker
+	admin         *Admin
+	logger        *slog.Logger
 }
 
 // NewServer creates a new instance of Server.
-func NewServer(logger *slog.Logger, serverAddr string, ss Slacker) *Server {
+func NewServer(logger *slog.Logger, serverAddr string, ss Slacker, hc *HealthChecker, admin *Admin) *Server {
 	s := &Server{
 		server: &http.Server{},
 		router: chi.NewRouter(),
@@ -37,15 +45,42 @@ func NewServer(logger *slog.Logger, serverAddr string, ss Slacker) *Server {
 	// inject dependences
 	s.addr = serverAddr
 	s.slackService = ss
+	s.healthChecker = hc
+	s.admin = admin
 	s.logger = logger
 
 	// create routes and attach handlers
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.server.BaseContext = func(net.Listener) context.Context { return s.ctx }
 	s.server.Handler = http.HandlerFunc(s.router.ServeHTTP)
+	s.router.Use(traceRequests, instrument)
 	s.router.NotFound(s.handleNotFound)
+	s.router.Handle("/metrics", promhttp.Handler())
 	s.router.Get("/ping", s.handlePing)
+	s.router.Get("/healthz", s.handleHealthz)
+	s.router.Get("/readyz", s.handleReadyz)
 	s.router.Post("/events", s.handleEvents)
 	s.router.Route("/slack/", func(r chi.Router) {
 		r.Post("/monthly-update", s.handleMonthlyUpdate)
+		r.Post("/year-in-review", s.handleYearInReview)
+		r.Post("/commands", s.handleCommand)
+		r.Get("/install", s.handleInstall)
+		r.Get("/oauth/callback", s.handleOAuthCallback)
+	})
+	s.router.Route("/admin/", func(r chi.Router) {
+		r.Use(s.admin.Authenticate)
+		r.Get("/audit-log", s.handleAuditLog)
+		r.Get("/leaderboard", s.handleLeaderboard)
+		r.Get("/trend", s.handleTrend)
+		r.Get("/graph", s.handleGraph)
+		r.Get("/heatmap", s.handleHeatmap)
+		r.Get("/adjustments", s.handleAdjustments)
+		r.Post("/adjustments", s.handleCreateAdjustment)
+		r.Post("/adjustments/{id}/revert", s.handleRevertAdjustment)
+		r.Get("/opt-outs", s.handleOptOuts)
+		r.Post("/opt-outs", s.handleCreateOptOut)
+		r.Delete("/opt-outs", s.handleDeleteOptOut)
+		r.Post("/forget", s.handleForget)
 	})
 	return s
 }
@@ -61,13 +96,20 @@ func (s *Server) Open() (err error) {
 	return nil
 }
 
-// Close gracefully shuts down the server.
+// Close gracefully shuts down the server. Requests which are still outstanding
+// after ShutdownTimeout have their context canceled.
 func (s *Server) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
 	defer cancel()
+	defer s.cancel()
 	return s.server.Shutdown(ctx)
 }
 
+// ServeHTTP routes a request to its handler, which allows the server to be used without listening.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
 // handleMonthlyUpdate generates and monthly slack summary and publishes it.
 func (s *Server) handleMonthlyUpdate(w http.ResponseWriter, r *http.Request) {
 	err := s.slackService.HandleMonthlyUpdate(w, r)
@@ -77,6 +119,135 @@ func (s *Server) handleMonthlyUpdate(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// handleYearInReview generates the annual slack summary and publishes it.
+func (s *Server) handleYearInReview(w http.ResponseWriter, r *http.Request) {
+	err := s.slackService.HandleYearInReview(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleCommand handles the /statsd slash command.
+func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	err := s.slackService.HandleCommand(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleInstall redirects to Slack to install statsd into a workspace.
+func (s *Server) handleInstall(w http.ResponseWriter, r *http.Request) {
+	err := s.slackService.HandleInstall(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleOAuthCallback completes the installation of statsd into a workspace.
+func (s *Server) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	err := s.slackService.HandleOAuthCallback(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleAuditLog lists the audit log.
+func (s *Server) handleAuditLog(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleAuditLog(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleAdjustments lists the manual adjustments of a workspace.
+func (s *Server) handleAdjustments(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleAdjustments(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleCreateAdjustment applies a manual adjustment.
+func (s *Server) handleCreateAdjustment(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleCreateAdjustment(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleRevertAdjustment reverts a manual adjustment.
+func (s *Server) handleRevertAdjustment(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleRevertAdjustment(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleOptOuts lists the members who opted out.
+func (s *Server) handleOptOuts(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleOptOuts(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleCreateOptOut opts a member out.
+func (s *Server) handleCreateOptOut(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleCreateOptOut(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleDeleteOptOut opts a member back in.
+func (s *Server) handleDeleteOptOut(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleDeleteOptOut(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleLeaderboard returns the leaderboard of a workspace over a period.
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleLeaderboard(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleTrend compares the top members of a workspace with the previous period.
+func (s *Server) handleTrend(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleTrend(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleGraph exports the graph of who reacted to whom.
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleGraph(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleHeatmap returns the reactions of a workspace by weekday and hour.
+func (s *Server) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleHeatmap(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// handleForget permanently erases a member.
+func (s *Server) handleForget(w http.ResponseWriter, r *http.Request) {
+	err := s.admin.HandleForget(w, r)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
 // handleEvents handles Slack push events.
 func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	err := s.slackService.HandleEvents(w, r)
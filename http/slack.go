@@ -1,28 +1,39 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/chart"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
 const (
-	ThumbsUp   = "+1"
-	ThumbsDown = "-1"
+	ThumbsUp   = statsd.ThumbsUp
+	ThumbsDown = statsd.ThumbsDown
 )
 
 // Slacker represents a service for handling Slack push events.
 type Slacker interface {
 	HandleEvents(w http.ResponseWriter, r *http.Request) error
 	HandleMonthlyUpdate(w http.ResponseWriter, r *http.Request) error
+	HandleYearInReview(w http.ResponseWriter, r *http.Request) error
+	HandleInstall(w http.ResponseWriter, r *http.Request) error
+	HandleOAuthCallback(w http.ResponseWriter, r *http.Request) error
+	HandleCommand(w http.ResponseWriter, r *http.Request) error
 }
 
 // Slack represents a service for handling specific Slack events.
@@ -30,60 +41,490 @@ type Slack struct {
 	// Services used by Slack
 	LeaderboardService statsd.LeaderboardService
 	MemberService      statsd.MemberService
-	client             *slack.Client
+	ReactionService    statsd.ReactionService
+	WorkspaceService   statsd.WorkspaceService
+	AuditService       statsd.AuditService
+	PrivacyService     statsd.PrivacyService
+	HeatmapService     statsd.HeatmapService
+	StreakService      statsd.StreakService
 
 	// Dependencies
 	logger        *slog.Logger
 	signingSecret string
+	oauth         OAuthConfig
+	channelGroups statsd.ChannelGroups
+	channelFilter statsd.ChannelFilter
+
+	// Types of the channels looked up so far, keyed by team and channel ID.
+	channelTypes sync.Map
 }
 
 // NewSlackService creates a new instance of slackService.
-func NewSlackService(logger *slog.Logger, ms statsd.MemberService, ls statsd.LeaderboardService, signingSecret string, botSigningKey string) (Slacker, error) {
+func NewSlackService(logger *slog.Logger, ms statsd.MemberService, ls statsd.LeaderboardService, rs statsd.ReactionService, ws statsd.WorkspaceService, as statsd.AuditService, ps statsd.PrivacyService, hs statsd.HeatmapService, ss statsd.StreakService, groups statsd.ChannelGroups, filter statsd.ChannelFilter, signingSecret string, oauth OAuthConfig) (Slacker, error) {
 	return &Slack{
 		logger:             logger,
 		MemberService:      ms,
 		LeaderboardService: ls,
-		client:             slack.New(botSigningKey),
+		ReactionService:    rs,
+		WorkspaceService:   ws,
+		AuditService:       as,
+		PrivacyService:     ps,
+		HeatmapService:     hs,
+		StreakService:      ss,
 		signingSecret:      signingSecret,
+		oauth:              oauth,
+		channelGroups:      groups,
+		channelFilter:      filter,
 	}, nil
 }
 
-// HandleMonthlyUpdate sends a summary of the recorded metrics into Slack.
+// HandleMonthlyUpdate sends a summary of the recorded metrics over a period into Slack.
 //
-// Expecting x-www-form-urlencoded payload in the form of `channel=<channelID>&date=<month>-<year>`.
+// Expecting x-www-form-urlencoded payload in the form of `team=<teamID>&channel=<channelID>&date=<month>-<year>`.
 // I.e. to represent October 2023, the key=value combination would be `date=10-2023`.
+// Instead of a date, any period accepted by statsd.ParsePeriod may be given as `period=<period>`,
+// e.g. `period=2023-W42`, `period=2023-Q4` or `period=ytd` for the year to date, or the last completed week, month, quarter or year
+// as `last=<week|month|quarter|year>`, which allows reports to be scheduled.
+// The leaderboard may be restricted to the reactions within a channel or channel group with
+// `scope=<channelID|group>`, or within the channel the update is posted into with `scope=here`.
+// A heatmap of the reactions by weekday and hour is attached with `heatmap=true`, and charts of
+// the members with the most likes and of the reactions over the last twelve months with `charts=true`.
+// The team may be omitted if only a single workspace is installed.
 func (s *Slack) HandleMonthlyUpdate(w http.ResponseWriter, r *http.Request) error {
 	err := r.ParseForm()
 	if err != nil {
 		return err
 	}
 
+	workspace, err := s.findWorkspace(r.Context(), r.PostForm.Get("team"))
+	if err != nil {
+		return err
+	}
 	channelID := r.PostForm.Get("channel")
 	if channelID == "" {
 		return errors.New("no channel value provided within the form")
 	}
-	rawDate := r.PostForm.Get("date")
-	if rawDate == "" {
-		return errors.New("no date value provided within the form")
+	period, err := formPeriod(r)
+	if err != nil {
+		return err
+	}
+
+	title := period.Title()
+	if period.Kind == statsd.PeriodMonth {
+		title = "the month of " + title
+	}
+	var leaderboard *statsd.Leaderboard
+	var channelIDs []string
+	scope := r.PostForm.Get("scope")
+	if scope == "here" {
+		scope = channelID
+	}
+	if scope != "" {
+		channelIDs = s.channelGroups.Channels(scope)
+		leaderboard, err = s.LeaderboardService.FindChannelLeaderboard(r.Context(), workspace.TeamID, channelIDs, period)
+		if _, ok := s.channelGroups[scope]; ok {
+			title += " in " + scope
+		} else {
+			title += fmt.Sprintf(" in <#%s>", scope)
+		}
+	} else {
+		leaderboard, err = s.LeaderboardService.FindPeriodLeaderboard(r.Context(), workspace.TeamID, period)
+	}
+	if err != nil {
+		return err
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Slack member activity for %s", title), false, false),
+			nil,
+			nil,
+		),
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("- most likes received: <@%s> with %d likes", leaderboard.MostReceivedLikesMember.SlackUID, leaderboard.MostReceivedLikesMember.ReceivedLikes), false, false),
+			nil,
+			nil,
+		),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", "- hottest takes "+hottestTakes(leaderboard.MostControversialMember), false, false),
+			nil,
+			nil,
+		),
+	}
+	blocks = append(blocks, s.trendBlocks(r.Context(), workspace, channelIDs, period)...)
+	blocks = append(blocks, s.messageBlocks(r.Context(), workspace, channelIDs, period)...)
+	if scope == "" {
+		blocks = append(blocks, s.streakBlocks(r.Context(), workspace, period)...)
+	}
+
+	msg := slack.NewBlockMessage(blocks...)
+
+	_, _, err = newSlackClient(workspace.BotToken).PostMessageContext(r.Context(), channelID, slack.MsgOptionBlocks(msg.Blocks.BlockSet...))
+	if err != nil {
+		return fmt.Errorf("HandleMonthlyUpdate PostMessage: %w", err)
+	}
+	if attach, _ := strconv.ParseBool(r.PostForm.Get("charts")); attach {
+		s.uploadCharts(r.Context(), workspace, channelID, channelIDs, period)
+	}
+	if attach, _ := strconv.ParseBool(r.PostForm.Get("heatmap")); attach {
+		s.uploadHeatmap(r.Context(), workspace, channelID, channelIDs, period)
+	}
+
+	s.audit(r.Context(), workspace.TeamID, "http:"+r.RemoteAddr, statsd.AuditActionMonthlyUpdate, channelID, nil, map[string]any{
+		"period":            period.String(),
+		"scope":             scope,
+		"mostLikes":         leaderboard.MostReceivedLikesMember.SlackUID,
+		"mostLikesCount":    leaderboard.MostReceivedLikesMember.ReceivedLikes,
+		"mostDislikes":      leaderboard.MostReceivedDislikesMember.SlackUID,
+		"mostDislikesCount": leaderboard.MostReceivedDislikesMember.ReceivedDislikes,
+		"mostControversial": leaderboard.MostControversialMember.SlackUID,
+	})
+	s.logger.Info("published update", slog.String("team", workspace.TeamID), slog.String("period", period.String()), slog.String("scope", scope))
+	return nil
+}
+
+// hottestTakes describes the most controversial member of a leaderboard.
+func hottestTakes(m statsd.Member) string {
+	if m.SlackUID == "" {
+		return "(most controversial): nobody received both likes and dislikes"
+	}
+	return fmt.Sprintf("(most controversial): <@%s> with %d likes and %d dislikes", m.SlackUID, m.ReceivedLikes, m.ReceivedDislikes)
+}
+
+// trendMembers is the number of top members compared with the previous period in updates.
+const trendMembers = 5
+
+// trendBlocks returns the blocks comparing the top members and the reactions within the workspace
+// with the previous period. The comparison is optional, so failures are logged and leave it out.
+// The all-time period has no previous period to compare with.
+func (s *Slack) trendBlocks(ctx context.Context, workspace *statsd.Workspace, channelIDs []string, period statsd.Period) []slack.Block {
+	if period.Kind == statsd.PeriodAllTime {
+		return nil
+	}
+	trend, err := s.LeaderboardService.FindLeaderboardTrend(ctx, workspace.TeamID, channelIDs, period, trendMembers)
+	if err != nil {
+		s.logger.Error("find leaderboard trend", slog.String("team", workspace.TeamID), slog.String("error", err.Error()))
+		return nil
+	} else if len(trend.TopMembers) == 0 {
+		return nil
+	}
+
+	lines := []string{
+		fmt.Sprintf("- compared with %s: %d likes (%s) and %d dislikes (%s)", trend.PrevPeriod.Title(), trend.ReceivedLikes, formatGrowth(trend.LikesGrowth), trend.ReceivedDislikes, formatGrowth(trend.DislikesGrowth)),
+	}
+	top := make([]string, len(trend.TopMembers))
+	for i, m := range trend.TopMembers {
+		change := fmt.Sprintf("%+d", m.LikesDelta)
+		if m.NewEntrant {
+			change += ", new"
+		}
+		top[i] = fmt.Sprintf("%d. <@%s> with %d likes (%s)", m.Rank, m.SlackUID, m.ReceivedLikes, change)
+	}
+	lines = append(lines, "- top members: "+strings.Join(top, ", "))
+	if entrants := trend.NewEntrants(); len(entrants) > 0 {
+		mentions := make([]string, len(entrants))
+		for i, m := range entrants {
+			mentions[i] = fmt.Sprintf("<@%s>", m.SlackUID)
+		}
+		lines = append(lines, fmt.Sprintf("- new in the top %d: %s", trendMembers, strings.Join(mentions, ", ")))
+	}
+
+	blocks := make([]slack.Block, len(lines))
+	for i, line := range lines {
+		blocks[i] = slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", line, false, false), nil, nil)
+	}
+	return blocks
+}
+
+// streakBlocks returns the blocks recognising the members with the longest current streaks up to the
+// end of a period. Streaks span the whole workspace, so they're left out of updates scoped to channels.
+// They're optional, so failures are logged and leave them out.
+func (s *Slack) streakBlocks(ctx context.Context, workspace *statsd.Workspace, period statsd.Period) []slack.Block {
+	at := time.Now()
+	if period.Kind != statsd.PeriodAllTime {
+		at = period.End.Add(-time.Nanosecond)
+	}
+	streaks, err := s.StreakService.FindStreaks(ctx, workspace.TeamID, at)
+	if err != nil {
+		s.logger.Error("find streaks", slog.String("team", workspace.TeamID), slog.String("error", err.Error()))
+		return nil
+	}
+
+	var blocks []slack.Block
+	for _, kind := range []struct {
+		streak   func(m *statsd.MemberStreaks) statsd.Streak
+		describe func(slackUID string, n int) string
+	}{
+		{
+			func(m *statsd.MemberStreaks) statsd.Streak { return m.TopMonths },
+			func(slackUID string, n int) string {
+				return fmt.Sprintf("- longest top %d streak: <@%s> for %d months running", statsd.StreakTopMembers, slackUID, n)
+			},
+		},
+		{
+			func(m *statsd.MemberStreaks) statsd.Streak { return m.LikedWeeks },
+			func(slackUID string, n int) string {
+				return fmt.Sprintf("- most consistently appreciated: <@%s> received likes %d weeks running", slackUID, n)
+			},
+		},
+	} {
+		var leader *statsd.MemberStreaks
+		var record int
+		for _, m := range streaks {
+			if leader == nil || kind.streak(m).Current > kind.streak(leader).Current {
+				leader = m
+			}
+			record = max(record, kind.streak(m).Longest)
+		}
+		// A single month or week is no streak yet.
+		if leader == nil || kind.streak(leader).Current < 2 {
+			continue
+		}
+		text := kind.describe(leader.SlackUID, kind.streak(leader).Current)
+		if kind.streak(leader).Current == record {
+			text += ", the longest yet"
+		}
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil))
+	}
+	return blocks
+}
+
+// formatGrowth formats a growth in percent with its sign. Growth from nothing is undefined.
+func formatGrowth(g *float64) string {
+	if g == nil {
+		return "up from none"
+	} else if *g == 0 {
+		return "no change"
+	}
+	return fmt.Sprintf("%+.0f%%", *g)
+}
+
+// messageBlocks returns the blocks presenting the most liked and the most controversial messages
+// over a period. The messages are optional, so failures are logged and leave them out.
+func (s *Slack) messageBlocks(ctx context.Context, workspace *statsd.Workspace, channelIDs []string, period statsd.Period) []slack.Block {
+	leaderboard, err := s.LeaderboardService.FindMessageLeaderboard(ctx, workspace.TeamID, channelIDs, period)
+	if errors.Is(err, statsd.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		s.logger.Error("find message leaderboard", slog.String("team", workspace.TeamID), slog.String("error", err.Error()))
+		return nil
+	}
+
+	client := newSlackClient(workspace.BotToken)
+	var blocks []slack.Block
+	for _, m := range []struct {
+		label   string
+		message *statsd.Message
+	}{
+		{"most loved post", leaderboard.MostLikedMessage},
+		{"most controversial post", leaderboard.MostControversialMessage},
+	} {
+		if m.message == nil {
+			continue
+		}
+		permalink, err := client.GetPermalinkContext(ctx, &slack.PermalinkParameters{Channel: m.message.ChannelID, Ts: m.message.MessageTS})
+		if err != nil {
+			// The message may have been deleted since.
+			s.logger.Info("get permalink", slog.String("channel", m.message.ChannelID), slog.String("ts", m.message.MessageTS), slog.String("error", err.Error()))
+			continue
+		}
+		m.message.Permalink = permalink
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("- <%s|%s> by <@%s> with %d likes and %d dislikes", m.message.Permalink, m.label, m.message.AuthorUID, m.message.ReceivedLikes, m.message.ReceivedDislikes), false, false),
+			nil,
+			nil,
+		))
+	}
+	return blocks
+}
+
+// chartMembers is the number of members shown in the chart of the members with the most likes.
+const chartMembers = 10
+
+// uploadCharts attaches a bar chart of the members with the most likes over a period and a line
+// chart of the reactions within the twelve months up to its end to a channel. The charts are
+// optional, so failures are logged and leave them out.
+func (s *Slack) uploadCharts(ctx context.Context, workspace *statsd.Workspace, channelID string, channelIDs []string, period statsd.Period) {
+	members, err := s.LeaderboardService.FindTopMembers(ctx, workspace.TeamID, channelIDs, period, chartMembers)
+	if err != nil {
+		s.logger.Error("find top members", slog.String("team", workspace.TeamID), slog.String("error", err.Error()))
+	} else if len(members) > 0 {
+		names := s.memberNames(ctx, workspace, members)
+		bars := make([]chart.Bar, len(members))
+		for i, m := range members {
+			bars[i] = chart.Bar{Label: names[i], Value: m.ReceivedLikes}
+		}
+		s.uploadChart(ctx, workspace, channelID, "likes-"+period.String()+".png", "Most likes received", chart.BarChart("Most likes received, "+period.Title(), bars))
+	}
+
+	// The all-time period is unbounded, so its trend ends with the current month.
+	end := time.Now()
+	if period.Kind != statsd.PeriodAllTime {
+		end = period.End.Add(-time.Nanosecond)
+	}
+	last := statsd.NewMonthYear(end)
+	lastStart, err := last.Time()
+	if err != nil {
+		s.logger.Error("trend", slog.String("team", workspace.TeamID), slog.String("error", err.Error()))
+		return
+	}
+	totals, err := s.LeaderboardService.FindMonthlyTotals(ctx, workspace.TeamID, channelIDs, statsd.NewMonthYear(lastStart.AddDate(0, -11, 0)), last)
+	if err != nil {
+		s.logger.Error("find monthly totals", slog.String("team", workspace.TeamID), slog.String("error", err.Error()))
+		return
+	}
+	labels := make([]string, len(totals))
+	likes, dislikes := chart.Series{Name: "Likes"}, chart.Series{Name: "Dislikes"}
+	for i, total := range totals {
+		t, err := total.Date.Time()
+		if err != nil {
+			s.logger.Error("trend", slog.String("team", workspace.TeamID), slog.String("error", err.Error()))
+			return
+		}
+		labels[i] = t.Format("Jan")
+		likes.Values = append(likes.Values, total.ReceivedLikes)
+		dislikes.Values = append(dislikes.Values, total.ReceivedDislikes)
+	}
+	s.uploadChart(ctx, workspace, channelID, "trend-"+last.String()+".png", "Reactions by month", chart.LineChart("Reactions by month up to "+lastStart.Format("January 2006"), labels, []chart.Series{likes, dislikes}))
+}
+
+// memberNames returns the display names of members for use within charts, which can't mention
+// them. Members whose name can't be looked up are named by their Slack user ID.
+func (s *Slack) memberNames(ctx context.Context, workspace *statsd.Workspace, members []statsd.Member) []string {
+	client := newSlackClient(workspace.BotToken)
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.SlackUID
+		user, err := client.GetUserInfoContext(ctx, m.SlackUID)
+		if err != nil {
+			s.logger.Info("get user info", slog.String("user", m.SlackUID), slog.String("error", err.Error()))
+			continue
+		}
+		for _, name := range []string{user.Profile.DisplayName, user.RealName, user.Name} {
+			if name != "" {
+				names[i] = name
+				break
+			}
+		}
+	}
+	return names
+}
+
+// uploadHeatmap attaches a heatmap of the reactions over a period to a channel. The heatmap is
+// optional, so failures are logged and leave it out.
+func (s *Slack) uploadHeatmap(ctx context.Context, workspace *statsd.Workspace, channelID string, channelIDs []string, period statsd.Period) {
+	h, err := s.HeatmapService.FindHeatmap(ctx, workspace.TeamID, channelIDs, period)
+	if err != nil {
+		s.logger.Error("find heatmap", slog.String("team", workspace.TeamID), slog.String("error", err.Error()))
+		return
+	}
+	s.uploadChart(ctx, workspace, channelID, "heatmap-"+period.String()+".png", "Reactions by weekday and hour", chart.Heatmap(h))
+}
+
+// uploadChart uploads img as a PNG file into a channel. Failures are logged.
+func (s *Slack) uploadChart(ctx context.Context, workspace *statsd.Workspace, channelID string, filename string, title string, img image.Image) {
+	var buf bytes.Buffer
+	if err := chart.EncodePNG(&buf, img); err != nil {
+		s.logger.Error("encode chart", slog.String("file", filename), slog.String("error", err.Error()))
+		return
+	}
+	_, err := newSlackClient(workspace.BotToken).UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Reader:   &buf,
+		FileSize: buf.Len(),
+		Filename: filename,
+		Title:    title,
+		Channel:  channelID,
+	})
+	if err != nil {
+		s.logger.Error("upload chart", slog.String("team", workspace.TeamID), slog.String("file", filename), slog.String("error", err.Error()))
+	}
+}
+
+// formPeriod returns the period requested by the `date`, `period` or `last` form values.
+func formPeriod(r *http.Request) (statsd.Period, error) {
+	if rawDate := r.PostForm.Get("date"); rawDate != "" {
+		date, err := statsd.NewMonthYearString(rawDate)
+		if err != nil {
+			return statsd.Period{}, err
+		}
+		return statsd.NewMonthPeriod(date)
+	}
+	if rawPeriod := r.PostForm.Get("period"); rawPeriod != "" {
+		return statsd.ParsePeriod(rawPeriod, time.Now())
+	}
+	if kind := r.PostForm.Get("last"); kind != "" {
+		current, err := statsd.NewPeriod(statsd.PeriodKind(kind), time.Now())
+		if err != nil {
+			return statsd.Period{}, err
+		}
+		return current.Prev(), nil
 	}
-	date, err := statsd.NewMonthYearString(rawDate)
+	return statsd.Period{}, errors.New("no date, period or last value provided within the form")
+}
+
+// HandleYearInReview sends a summary of a year into Slack: the leaders of the year, the leaders
+// of each of its months and the all-time leaders.
+//
+// Expecting x-www-form-urlencoded payload in the form of `team=<teamID>&channel=<channelID>&year=<year>`.
+// The year may be omitted, in which case the previous year is reviewed in January and the current
+// year otherwise, so the review can be scheduled for late December or early January.
+// The team may be omitted if only a single workspace is installed.
+func (s *Slack) HandleYearInReview(w http.ResponseWriter, r *http.Request) error {
+	err := r.ParseForm()
 	if err != nil {
 		return err
 	}
 
-	leaderboard, err := s.LeaderboardService.FindLeaderboard(date)
+	workspace, err := s.findWorkspace(r.Context(), r.PostForm.Get("team"))
+	if err != nil {
+		return err
+	}
+	channelID := r.PostForm.Get("channel")
+	if channelID == "" {
+		return errors.New("no channel value provided within the form")
+	}
+	now := time.Now().In(statsd.TimeZone)
+	year := now.Year()
+	if now.Month() == time.January {
+		year--
+	}
+	if rawYear := r.PostForm.Get("year"); rawYear != "" {
+		if year, err = strconv.Atoi(rawYear); err != nil {
+			return fmt.Errorf("invalid year value provided within the form: %w", err)
+		}
+	}
+	period, err := statsd.NewPeriod(statsd.PeriodYear, time.Date(year, time.January, 1, 0, 0, 0, 0, statsd.TimeZone))
 	if err != nil {
 		return err
 	}
 
-	month, err := date.Month()
+	leaderboard, err := s.LeaderboardService.FindPeriodLeaderboard(r.Context(), workspace.TeamID, period)
+	if err != nil {
+		return err
+	}
+	allTime, err := s.LeaderboardService.FindPeriodLeaderboard(r.Context(), workspace.TeamID, statsd.NewAllTimePeriod())
 	if err != nil {
 		return err
 	}
 
+	// Months without any activity are left out.
+	var months strings.Builder
+	for t := period.Start; t.Before(period.End) && t.Before(now); t = t.AddDate(0, 1, 0) {
+		monthly, err := s.LeaderboardService.FindLeaderboard(r.Context(), workspace.TeamID, statsd.NewMonthYear(t))
+		if errors.Is(err, statsd.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		fmt.Fprintf(&months, "- %s: <@%s> with %d likes, hottest takes %s\n", t.Format("January"),
+			monthly.MostReceivedLikesMember.SlackUID, monthly.MostReceivedLikesMember.ReceivedLikes,
+			hottestTakes(monthly.MostControversialMember))
+	}
+
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Slack member activity for the month of %s", month), false, false),
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Slack member activity: %s in review", period.Title()), false, false),
 			nil,
 			nil,
 		),
@@ -94,25 +535,93 @@ func (s *Slack) HandleMonthlyUpdate(w http.ResponseWriter, r *http.Request) erro
 			nil,
 		),
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("- hottest takes (most dislikes received): <@%s> with %d dislikes", leaderboard.MostReceivedDislikesMember.SlackUID, leaderboard.MostReceivedDislikesMember.ReceivedDislikes), false, false),
+			slack.NewTextBlockObject("mrkdwn", "- hottest takes "+hottestTakes(leaderboard.MostControversialMember), false, false),
 			nil,
 			nil,
 		),
 	}
+	if months.Len() > 0 {
+		blocks = append(blocks,
+			slack.NewDividerBlock(),
+			slack.NewSectionBlock(
+				slack.NewTextBlockObject("mrkdwn", "Month by month:\n"+months.String(), false, false),
+				nil,
+				nil,
+			),
+		)
+	}
+	blocks = append(blocks,
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("All time: <@%s> with %d likes, hottest takes %s",
+				allTime.MostReceivedLikesMember.SlackUID, allTime.MostReceivedLikesMember.ReceivedLikes,
+				hottestTakes(allTime.MostControversialMember)), false, false),
+			nil,
+			nil,
+		),
+	)
 
 	msg := slack.NewBlockMessage(blocks...)
 
-	_, _, err = s.client.PostMessage(channelID, slack.MsgOptionBlocks(msg.Blocks.BlockSet...))
+	_, _, err = newSlackClient(workspace.BotToken).PostMessageContext(r.Context(), channelID, slack.MsgOptionBlocks(msg.Blocks.BlockSet...))
 	if err != nil {
-		return fmt.Errorf("WeeklyUpdate PostMessage: %w", err)
+		return fmt.Errorf("HandleYearInReview PostMessage: %w", err)
 	}
 
-	s.logger.Info("published monthly update", slog.String("month", month))
+	s.audit(r.Context(), workspace.TeamID, "http:"+r.RemoteAddr, statsd.AuditActionYearInReview, channelID, nil, map[string]any{
+		"period":            period.String(),
+		"mostLikes":         leaderboard.MostReceivedLikesMember.SlackUID,
+		"mostLikesCount":    leaderboard.MostReceivedLikesMember.ReceivedLikes,
+		"mostDislikes":      leaderboard.MostReceivedDislikesMember.SlackUID,
+		"mostDislikesCount": leaderboard.MostReceivedDislikesMember.ReceivedDislikes,
+		"mostControversial": leaderboard.MostControversialMember.SlackUID,
+	})
+	s.logger.Info("published year in review", slog.String("team", workspace.TeamID), slog.String("period", period.String()))
 	return nil
 }
 
+// audit records an administrative action. Failures are logged but don't fail the action,
+// which has already taken place.
+func (s *Slack) audit(ctx context.Context, teamID string, actor string, action string, target string, before any, after any) {
+	e, err := statsd.NewAuditEntry(teamID, actor, action, target, before, after)
+	if err == nil {
+		err = s.AuditService.CreateAuditEntry(ctx, e)
+	}
+	if err != nil {
+		s.logger.Error("record audit entry", slog.String("action", action), slog.String("error", err.Error()))
+	}
+}
+
+// findWorkspace retrieves an installed workspace by its team ID. If no team ID is given, the
+// only installed workspace is returned so single workspace deployments need not specify it.
+func (s *Slack) findWorkspace(ctx context.Context, teamID string) (*statsd.Workspace, error) {
+	if teamID != "" {
+		return s.WorkspaceService.FindWorkspace(ctx, teamID)
+	}
+	workspaces, err := s.WorkspaceService.FindWorkspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) != 1 {
+		return nil, fmt.Errorf("no team value provided within the form and %d workspaces are installed", len(workspaces))
+	}
+	return workspaces[0], nil
+}
+
 // handleEvents handles Slack push events.
-func (s *Slack) HandleEvents(w http.ResponseWriter, r *http.Request) error {
+func (s *Slack) HandleEvents(w http.ResponseWriter, r *http.Request) (err error) {
+	// Record every event by its type and outcome.
+	eventType, outcome := "unknown", ""
+	defer func() {
+		if outcome == "" {
+			outcome = outcomeOK
+			if err != nil {
+				outcome = outcomeError
+			}
+		}
+		eventsTotal.WithLabelValues(eventType, outcome).Inc()
+	}()
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -128,6 +637,8 @@ func (s *Slack) HandleEvents(w http.ResponseWriter, r *http.Request) error {
 		return fmt.Errorf("HandleEvents: %w", err)
 	}
 	if err := sv.Ensure(); err != nil {
+		signatureFailuresTotal.Inc()
+		outcome = outcomeUnauthorized
 		w.WriteHeader(http.StatusUnauthorized)
 		return fmt.Errorf("HandleEvents: %w", err)
 	}
@@ -136,6 +647,10 @@ func (s *Slack) HandleEvents(w http.ResponseWriter, r *http.Request) error {
 		w.WriteHeader(http.StatusInternalServerError)
 		return fmt.Errorf("HandleEvents: %w", err)
 	}
+	eventType = eventsAPIEvent.Type
+	if eventsAPIEvent.Type == slackevents.CallbackEvent {
+		eventType = eventsAPIEvent.InnerEvent.Type
+	}
 
 	if eventsAPIEvent.Type == slackevents.URLVerification {
 		var r *slackevents.ChallengeResponse
@@ -148,15 +663,49 @@ func (s *Slack) HandleEvents(w http.ResponseWriter, r *http.Request) error {
 		w.Write([]byte(r.Challenge))
 	}
 	if eventsAPIEvent.Type == slackevents.CallbackEvent {
+		// Every event is routed to the workspace it originated from.
+		teamID := eventsAPIEvent.TeamID
+		workspace, err := s.WorkspaceService.FindWorkspace(r.Context(), teamID)
+		if errors.Is(err, statsd.ErrNotFound) {
+			s.logger.Info("event from workspace which is not installed", slog.String("team", teamID))
+			outcome = outcomeIgnored
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("HandleEvents: %w", err)
+		}
+
 		innerEvent := eventsAPIEvent.InnerEvent
 		switch ev := innerEvent.Data.(type) {
+		case *slackevents.AppUninstalledEvent:
+			if err := s.WorkspaceService.DeleteWorkspace(r.Context(), teamID); err != nil {
+				return fmt.Errorf("HandleEvents: %w", err)
+			}
+			s.audit(r.Context(), teamID, "slack", statsd.AuditActionWorkspaceUninstall, teamID, nil, nil)
+			s.logger.Info("uninstalled workspace", slog.String("team", teamID))
 		case *slackevents.ReactionAddedEvent:
-			err := s.HandleReactionAddedEvent(ev)
+			// Other emoji are ignored before the channel filter, which may look up the channel.
+			if !statsd.IsCountedReaction(ev.Reaction) {
+				outcome = outcomeIgnored
+				return nil
+			}
+			if !s.allowChannel(r.Context(), workspace, ev.Item.Channel) {
+				outcome = outcomeFiltered
+				return nil
+			}
+			err := s.HandleReactionAddedEvent(r.Context(), teamID, ev)
 			if err != nil {
 				return fmt.Errorf("HandleEvents: %w", err)
 			}
 		case *slackevents.ReactionRemovedEvent:
-			err := s.HandleReactionRemovedEvent(ev)
+			if !statsd.IsCountedReaction(ev.Reaction) {
+				outcome = outcomeIgnored
+				return nil
+			}
+			if !s.allowChannel(r.Context(), workspace, ev.Item.Channel) {
+				outcome = outcomeFiltered
+				return nil
+			}
+			err := s.HandleReactionRemovedEvent(r.Context(), teamID, ev)
 			if err != nil {
 				return fmt.Errorf("HandleEvents: %w", err)
 			}
@@ -165,71 +714,106 @@ func (s *Slack) HandleEvents(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
-// HandleReactionEvent handles an event by updating the member with the specified slackUID.
-func (s *Slack) HandleReactionEvent(memSlackUID string, update func(m *statsd.Member)) error {
-	if memSlackUID == "USLACKBOT" || memSlackUID == "" {
-		s.logger.Info("reaction to invalid target", slog.String("target slackUID", memSlackUID))
-		return nil
+// allowChannel reports whether reactions within a channel are counted according to the channel filter.
+// Channels whose type can't be looked up are treated as filtered rather than failing the event,
+// which Slack would otherwise retry.
+func (s *Slack) allowChannel(ctx context.Context, workspace *statsd.Workspace, channelID string) bool {
+	ok, reason, err := s.channelFilter.Allows(channelID, func() (statsd.ChannelType, error) {
+		return s.channelType(ctx, workspace, channelID)
+	})
+	if err != nil {
+		s.logger.Error("look up channel type", slog.String("channel", channelID), slog.String("error", err.Error()))
+		ok, reason = false, statsd.ChannelTypeUnknown
 	}
-	monthYear := statsd.NewMonthYear(time.Now().UTC())
-
-	// Create the member if he does not already exist within the database.
-	mem, err := s.MemberService.FindMember(memSlackUID, monthYear)
-	if errors.Is(err, statsd.ErrNotFound) {
-		m := &statsd.Member{
-			SlackUID: memSlackUID,
-			Date:     monthYear,
-		}
-		err := s.MemberService.CreateMember(m)
-		if err != nil {
-			return fmt.Errorf("HandleReactionAddedEvent CreateMember itemMember: %w", err)
-		}
-		s.logger.Info("created new member", slog.String("slackUID", m.SlackUID), slog.String("date", monthYear.String()))
-		mem = m
-	} else if err != nil {
-		return fmt.Errorf("HandleReactionAddedEvent FindMember ItemUser: %w", err)
+	if !ok {
+		eventsFilteredTotal.WithLabelValues(reason).Inc()
+		s.logger.Info("reaction within filtered channel", slog.String("channel", channelID), slog.String("reason", reason))
 	}
+	return ok
+}
 
-	update(mem)
+// channelType returns the type of a channel. Direct messages are recognized by their ID, while
+// the type of other channels is looked up once through the Slack API.
+func (s *Slack) channelType(ctx context.Context, workspace *statsd.Workspace, channelID string) (statsd.ChannelType, error) {
+	if strings.HasPrefix(channelID, "D") {
+		return statsd.ChannelDM, nil
+	}
+	key := workspace.TeamID + "/" + channelID
+	if v, ok := s.channelTypes.Load(key); ok {
+		return v.(statsd.ChannelType), nil
+	}
 
-	// Update the stats of the User being reacted to.
-	m, err := s.MemberService.UpdateMember(mem.ID, statsd.MemberUpdate{
-		ReceivedLikes:    &mem.ReceivedLikes,
-		ReceivedDislikes: &mem.ReceivedDislikes,
-	})
+	channel, err := newSlackClient(workspace.BotToken).GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channelID})
 	if err != nil {
-		return err
+		return "", fmt.Errorf("channelType GetConversationInfo: %w", err)
 	}
-	s.logger.Info("updated user", slog.String("slackUID", m.SlackUID), slog.Int("received likes", m.ReceivedLikes), slog.Int("received dislikes", m.ReceivedDislikes))
-	return nil
+	typ := conversationType(channel)
+	s.channelTypes.Store(key, typ)
+	return typ, nil
+}
+
+// conversationType returns the type of a conversation returned by the Slack API.
+func conversationType(channel *slack.Channel) statsd.ChannelType {
+	switch {
+	case channel.IsIM:
+		return statsd.ChannelDM
+	case channel.IsMpIM:
+		return statsd.ChannelMPIM
+	case channel.IsPrivate:
+		return statsd.ChannelPrivate
+	}
+	return statsd.ChannelPublic
 }
 
 // HandleReactionAddedEvent handles the event when a user reacts to the post of another user.
-func (s *Slack) HandleReactionAddedEvent(e *slackevents.ReactionAddedEvent) error {
-	switch e.Reaction {
-	case ThumbsUp:
-		return s.HandleReactionEvent(e.ItemUser, func(m *statsd.Member) {
-			m.ReceivedLikes += 1
-		})
-	case ThumbsDown:
-		return s.HandleReactionEvent(e.ItemUser, func(m *statsd.Member) {
-			m.ReceivedDislikes += 1
-		})
+func (s *Slack) HandleReactionAddedEvent(ctx context.Context, teamID string, e *slackevents.ReactionAddedEvent) error {
+	if !statsd.IsCountedReaction(e.Reaction) {
+		return nil
 	}
-	return nil
+	reactedAt, err := statsd.ParseTimestamp(e.EventTimestamp)
+	if err != nil {
+		reactedAt = time.Now().UTC()
+	}
+	return countReaction(ctx, s.logger, s.ReactionService, &statsd.Reaction{
+		TeamID:     teamID,
+		Date:       statsd.NewMonthYear(reactedAt),
+		ChannelID:  e.Item.Channel,
+		MessageTS:  e.Item.Timestamp,
+		ReactorUID: e.User,
+		AuthorUID:  e.ItemUser,
+		Name:       e.Reaction,
+		ReactedAt:  reactedAt,
+	})
 }
 
 // HandleReactionRemovedEvent handles the event when a user removes a reaction from another user's post.
-func (s *Slack) HandleReactionRemovedEvent(e *slackevents.ReactionRemovedEvent) error {
-	switch e.Reaction {
-	case ThumbsUp:
-		return s.HandleReactionEvent(e.ItemUser, func(m *statsd.Member) {
-			m.ReceivedLikes = max(m.ReceivedLikes-1, 0)
-		})
-	case ThumbsDown:
-		return s.HandleReactionEvent(e.ItemUser, func(m *statsd.Member) {
-			m.ReceivedDislikes = max(m.ReceivedDislikes-1, 0)
-		})
+func (s *Slack) HandleReactionRemovedEvent(ctx context.Context, teamID string, e *slackevents.ReactionRemovedEvent) error {
+	if !statsd.IsCountedReaction(e.Reaction) {
+		return nil
+	}
+	err := s.ReactionService.DeleteReaction(ctx, teamID, e.Item.Channel, e.Item.Timestamp, e.User, e.Reaction)
+	if errors.Is(err, statsd.ErrNotFound) {
+		s.logger.Info("removed reaction was never recorded", slog.String("channel", e.Item.Channel), slog.String("ts", e.Item.Timestamp), slog.String("reactor slackUID", e.User))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HandleReactionRemovedEvent DeleteReaction: %w", err)
+	}
+	reactionsCountedTotal.WithLabelValues(metricName(e.Reaction), "removed").Inc()
+	s.logger.Info("removed reaction", slog.String("target slackUID", e.ItemUser), slog.String("reaction", e.Reaction))
+	return nil
+}
+
+// countReaction records a reaction with statsd.CountReaction and logs whether it was recorded.
+// It is shared by live events and the backfill so both log and count reactions in the same way.
+func countReaction(ctx context.Context, logger *slog.Logger, rs statsd.ReactionService, r *statsd.Reaction) error {
+	recorded, err := statsd.CountReaction(ctx, rs, r)
+	if err != nil {
+		return err
+	} else if !recorded {
+		logger.Info("reaction not recorded", slog.String("target slackUID", r.AuthorUID), slog.String("channel", r.ChannelID), slog.String("ts", r.MessageTS), slog.String("reactor slackUID", r.ReactorUID))
+		return nil
 	}
+	reactionsCountedTotal.WithLabelValues(metricName(r.Name), "added").Inc()
+	logger.Info("recorded reaction", slog.String("target slackUID", r.AuthorUID), slog.String("reaction", r.Name), slog.String("date", r.Date.String()))
 	return nil
 }
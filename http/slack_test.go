@@ -0,0 +1,299 @@
+package http_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	statsdhttp "github.com/ddritzenhoff/statsd/http"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// SigningSecret is the signing secret the requests to the test Slack service are signed with.
+const SigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+func TestSlack_HandleEvents(t *testing.T) {
+	// Ensure events are counted towards the workspace they originate from.
+	t.Run("SecondWorkspace", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustSaveWorkspace(t, db, "T1ZN1SE2N")
+		MustSaveWorkspace(t, db, "T2ZN1SE2N")
+		s := NewSlack(t, db, statsd.ChannelFilter{})
+
+		w := httptest.NewRecorder()
+		if err := s.HandleEvents(w, NewEventRequest(t, ReactionAddedEvent("T2ZN1SE2N", "C1ZN1SE2N", statsd.ThumbsUp))); err != nil {
+			t.Fatal(err)
+		} else if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+		MustHaveLikes(t, db, "T2ZN1SE2N", 1)
+		if _, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", "05-2006"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure events from workspaces which aren't installed are ignored.
+	t.Run("UnknownTeam", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustSaveWorkspace(t, db, "T1ZN1SE2N")
+		s := NewSlack(t, db, statsd.ChannelFilter{})
+
+		w := httptest.NewRecorder()
+		if err := s.HandleEvents(w, NewEventRequest(t, ReactionAddedEvent("T9ZN1SE2N", "C1ZN1SE2N", statsd.ThumbsUp))); err != nil {
+			t.Fatal(err)
+		} else if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+		if _, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T9ZN1SE2N", "U1ZN1SE2N", "05-2006"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure reactions within channels allowed by the channel filter are counted.
+	t.Run("AllowedChannel", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustSaveWorkspace(t, db, "T1ZN1SE2N")
+		s := NewSlack(t, db, statsd.ChannelFilter{Types: []statsd.ChannelType{statsd.ChannelPublic}})
+		SetConversationInfo(t, `{"ok":true,"channel":{"id":"C1ZN1SE2N","is_channel":true,"is_private":false}}`)
+
+		if err := s.HandleEvents(httptest.NewRecorder(), NewEventRequest(t, ReactionAddedEvent("T1ZN1SE2N", "C1ZN1SE2N", statsd.ThumbsUp))); err != nil {
+			t.Fatal(err)
+		}
+		MustHaveLikes(t, db, "T1ZN1SE2N", 1)
+	})
+
+	// Ensure reactions within channels rejected by the channel filter aren't counted.
+	t.Run("DeniedChannel", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustSaveWorkspace(t, db, "T1ZN1SE2N")
+		SetConversationInfo(t, `{"ok":true,"channel":{"id":"C2ZN1SE2N","is_group":true,"is_private":true}}`)
+
+		for _, filter := range []statsd.ChannelFilter{
+			{Exclude: []string{"C2ZN1SE2N"}},
+			{Types: []statsd.ChannelType{statsd.ChannelPublic}},
+		} {
+			s := NewSlack(t, db, filter)
+			if err := s.HandleEvents(httptest.NewRecorder(), NewEventRequest(t, ReactionAddedEvent("T1ZN1SE2N", "C2ZN1SE2N", statsd.ThumbsUp))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", "05-2006"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure reactions within channels whose type can't be looked up are treated as filtered rather than failing the event.
+	t.Run("ErrChannelLookup", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustSaveWorkspace(t, db, "T1ZN1SE2N")
+		s := NewSlack(t, db, statsd.ChannelFilter{Types: []statsd.ChannelType{statsd.ChannelPublic}})
+		SetConversationInfo(t, `{"ok":false,"error":"channel_not_found"}`)
+
+		w := httptest.NewRecorder()
+		if err := s.HandleEvents(w, NewEventRequest(t, ReactionAddedEvent("T1ZN1SE2N", "C1ZN1SE2N", statsd.ThumbsUp))); err != nil {
+			t.Fatal(err)
+		} else if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+		if _, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", "05-2006"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure requests with an invalid signature are rejected.
+	t.Run("ErrSignature", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustSaveWorkspace(t, db, "T1ZN1SE2N")
+		s := NewSlack(t, db, statsd.ChannelFilter{})
+
+		r := NewEventRequest(t, ReactionAddedEvent("T1ZN1SE2N", "C1ZN1SE2N", statsd.ThumbsUp))
+		r.Header.Set("X-Slack-Signature", "v0="+strings.Repeat("0", 64))
+		w := httptest.NewRecorder()
+		if err := s.HandleEvents(w, r); err == nil {
+			t.Fatal("expected error")
+		} else if got, want := w.Code, http.StatusUnauthorized; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+		if _, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", "05-2006"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestSlack_HandleOAuthCallback(t *testing.T) {
+	// Ensure the workspace granted by Slack is installed.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustSaveWorkspace(t, db, "T1ZN1SE2N")
+		s := NewSlack(t, db, statsd.ChannelFilter{})
+		statsdhttp.SetSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got, want := r.URL.Path, "/api/oauth.v2.access"; got != want {
+				t.Errorf("Path=%v, want %v", got, want)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true,"access_token":"xoxb-2","bot_user_id":"U0ZN1SE2N","team":{"id":"T2ZN1SE2N","name":"Initech"},"authed_user":{"id":"U1ZN1SE2N"}}`))
+		}))
+
+		w := httptest.NewRecorder()
+		if err := s.HandleOAuthCallback(w, NewOAuthCallbackRequest("8a2f", "8a2f")); err != nil {
+			t.Fatal(err)
+		} else if got, want := w.Code, http.StatusOK; got != want {
+			t.Fatalf("Code=%v, want %v", got, want)
+		}
+		if workspace, err := sqlite.NewWorkspaceService(db).FindWorkspace(context.Background(), "T2ZN1SE2N"); err != nil {
+			t.Fatal(err)
+		} else if got, want := workspace.BotToken, "xoxb-2"; got != want {
+			t.Fatalf("BotToken=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a callback whose state doesn't match the cookie set by the install is rejected without contacting Slack.
+	t.Run("ErrState", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := NewSlack(t, db, statsd.ChannelFilter{})
+		statsdhttp.SetSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}))
+
+		for _, r := range []*http.Request{
+			NewOAuthCallbackRequest("8a2f", "9b3e"),
+			NewOAuthCallbackRequest("", "8a2f"),
+		} {
+			w := httptest.NewRecorder()
+			if err := s.HandleOAuthCallback(w, r); err == nil {
+				t.Fatal("expected error")
+			} else if got, want := w.Code, http.StatusBadRequest; got != want {
+				t.Fatalf("Code=%v, want %v", got, want)
+			}
+		}
+		if workspaces, err := sqlite.NewWorkspaceService(db).FindWorkspaces(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(workspaces), 0; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+	})
+}
+
+func TestFormatGrowth(t *testing.T) {
+	for _, tt := range []struct {
+		prev, cur int
+		want      string
+	}{
+		{10, 15, "+50%"},
+		{10, 8, "-20%"},
+		{10, 10, "no change"},
+		{0, 5, "up from none"},
+		{0, 0, "no change"},
+	} {
+		if got := statsdhttp.FormatGrowth(statsd.Growth(tt.prev, tt.cur)); got != tt.want {
+			t.Fatalf("FormatGrowth(Growth(%d, %d))=%q, want %q", tt.prev, tt.cur, got, tt.want)
+		}
+	}
+}
+
+// NewSlack returns a Slack service backed by db which counts the reactions allowed by filter.
+func NewSlack(tb testing.TB, db *sqlite.DB, filter statsd.ChannelFilter) statsdhttp.Slacker {
+	tb.Helper()
+	s, err := statsdhttp.NewSlackService(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		sqlite.NewMemberService(db),
+		sqlite.NewLeaderboardService(db),
+		sqlite.NewReactionService(db),
+		sqlite.NewWorkspaceService(db),
+		sqlite.NewAuditService(db),
+		sqlite.NewPrivacyService(db),
+		sqlite.NewHeatmapService(db),
+		sqlite.NewStreakService(db),
+		nil,
+		filter,
+		SigningSecret,
+		statsdhttp.OAuthConfig{ClientID: "1234.5678", ClientSecret: "secret", RedirectURL: "https://statsd.example.com/slack/oauth/callback"},
+	)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return s
+}
+
+// SetConversationInfo answers the calls to conversations.info with body until the end of the test.
+func SetConversationInfo(tb testing.TB, body string) {
+	tb.Helper()
+	statsdhttp.SetSlackAPI(tb, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Path, "/api/conversations.info"; got != want {
+			tb.Errorf("Path=%v, want %v", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+}
+
+// MustSaveWorkspace installs a workspace. Fatal on error.
+func MustSaveWorkspace(tb testing.TB, db *sqlite.DB, teamID string) {
+	tb.Helper()
+	if err := sqlite.NewWorkspaceService(db).SaveWorkspace(context.Background(), &statsd.Workspace{TeamID: teamID, TeamName: "Acme", BotUserID: "U0ZN1SE2N", BotToken: "xoxb-" + teamID}); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// MustHaveLikes verifies the likes U1ZN1SE2N received within May 2006 in a workspace. Fatal on mismatch.
+func MustHaveLikes(tb testing.TB, db *sqlite.DB, teamID string, likes int) {
+	tb.Helper()
+	m, err := sqlite.NewMemberService(db).FindMember(context.Background(), teamID, "U1ZN1SE2N", "05-2006")
+	if err != nil {
+		tb.Fatal(err)
+	} else if got, want := m.ReceivedLikes, likes; got != want {
+		tb.Fatalf("ReceivedLikes=%v, want %v", got, want)
+	}
+}
+
+// ReactionAddedEvent returns the body of a reaction_added event in which U2ZN1SE2N reacted to a message
+// of U1ZN1SE2N posted on May 15, 2006.
+func ReactionAddedEvent(teamID string, channelID string, reaction string) string {
+	return fmt.Sprintf(`{"token":"XXYYZZ","team_id":%q,"api_app_id":"A1ZN1SE2N","type":"event_callback","event_id":"Ev1ZN1SE2N","event_time":1147683660,`+
+		`"event":{"type":"reaction_added","user":"U2ZN1SE2N","reaction":%q,"item_user":"U1ZN1SE2N",`+
+		`"item":{"type":"message","channel":%q,"ts":"1147683600.000100"},"event_ts":"1147683660.000200"}}`, teamID, reaction, channelID)
+}
+
+// NewEventRequest returns a request to the events endpoint signed with SigningSecret.
+func NewEventRequest(tb testing.TB, body string) *http.Request {
+	tb.Helper()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(SigningSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+
+	r := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Slack-Request-Timestamp", ts)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+// NewOAuthCallbackRequest returns a request to the OAuth callback carrying the state set as cookie by
+// the install, if any, and the state returned by Slack.
+func NewOAuthCallbackRequest(cookieState string, state string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/slack/oauth/callback?code=4724469134.4644010092847&state="+state, nil)
+	if cookieState != "" {
+		r.AddCookie(&http.Cookie{Name: "statsd_oauth_state", Value: cookieState})
+	}
+	return r
+}
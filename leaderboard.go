@@ -1,15 +1,91 @@
 package statsd
 
-// Leaderboard represents the Slack user(s) with the most likes and dislikes for a particular month in a given year.
+import (
+	"context"
+	"math"
+)
+
+// Leaderboard represents the Slack user(s) of a workspace with the most likes and dislikes for a particular month in a given year,
+// or for a Period. Date is only set for monthly leaderboards and ChannelIDs only for leaderboards scoped to channels.
+// MostControversialMember is the member with the highest ControversyScore and is zero if no member received
+// both likes and dislikes.
 type Leaderboard struct {
-	Date                       MonthYear
-	MostReceivedLikesMember    Member
-	MostReceivedDislikesMember Member
+	TeamID                     string    `json:"teamID"`
+	Date                       MonthYear `json:"date,omitempty"`
+	Period                     Period    `json:"period"`
+	ChannelIDs                 []string  `json:"channelIDs,omitempty"`
+	MostReceivedLikesMember    Member    `json:"mostReceivedLikesMember"`
+	MostReceivedDislikesMember Member    `json:"mostReceivedDislikesMember"`
+	MostControversialMember    Member    `json:"mostControversialMember"`
+}
+
+// MonthlyTotal represents the reactions received by the members of a workspace within a month.
+type MonthlyTotal struct {
+	Date             MonthYear `json:"date"`
+	ReceivedLikes    int       `json:"receivedLikes"`
+	ReceivedDislikes int       `json:"receivedDislikes"`
+}
+
+// ControversyScore rates how divisive the reactions to a message or member are. The total number of
+// reactions is raised to the power of the balance between them, i.e. the ratio of the less to the
+// more frequent kind. 40 likes and 35 dislikes score about 43.7, 3 likes and 3 dislikes score 6 and
+// reactions of only one kind score 0.
+func ControversyScore(likes int, dislikes int) float64 {
+	if likes <= 0 || dislikes <= 0 {
+		return 0
+	}
+	balance := float64(min(likes, dislikes)) / float64(max(likes, dislikes))
+	return math.Pow(float64(likes+dislikes), balance)
 }
 
 // LeaderboardService represents a service for managing a Leaderboard.
 type LeaderboardService interface {
-	// FindLeaderboard retrives a Leadboard by its date (year and month).
-	// Returns ErrNotFound if no matches are found.
-	FindLeaderboard(Date MonthYear) (*Leaderboard, error)
+	// FindLeaderboard retrives the Leadboard of a workspace by its date (year and month).
+	// Members who opted out are left out. Returns ErrNotFound if no matches are found.
+	FindLeaderboard(ctx context.Context, teamID string, Date MonthYear) (*Leaderboard, error)
+
+	// FindPeriodLeaderboard retrieves the Leaderboard of a workspace over a period. Periods of whole
+	// months, such as a year to date, and the all-time period are summed from the monthly counts,
+	// including adjustments, while other periods are counted from the recorded Reactions.
+	// Members who opted out are left out.
+	// Returns ErrNotFound if no matches are found and ErrInvalid if a period counted from the
+	// recorded Reactions starts before they have been pruned or overlaps a month with adjustments,
+	// which apply to the whole month and can't be counted towards a part of it.
+	FindPeriodLeaderboard(ctx context.Context, teamID string, period Period) (*Leaderboard, error)
+
+	// FindChannelLeaderboard retrieves the Leaderboard of a workspace over a period, counting only
+	// the recorded Reactions within the given channels. Adjustments don't belong to a channel and
+	// are left out, as are members who opted out.
+	// Returns ErrInvalid if no channel is given or if the period starts before the Reactions have
+	// been pruned, and ErrNotFound if no matches are found.
+	FindChannelLeaderboard(ctx context.Context, teamID string, channelIDs []string, period Period) (*Leaderboard, error)
+
+	// FindMessageLeaderboard retrieves the most liked and the most controversial messages of a
+	// workspace over a period, counted from the recorded Reactions. The most controversial message
+	// has the highest ControversyScore. The messages may be restricted to the given channels;
+	// all channels are included if none are given. Messages of members who opted out are left out.
+	// Returns ErrInvalid if the period starts before the Reactions have been pruned, unless it is the
+	// all-time period, which covers the remaining ones, and ErrNotFound if no message received a reaction.
+	FindMessageLeaderboard(ctx context.Context, teamID string, channelIDs []string, period Period) (*MessageLeaderboard, error)
+
+	// FindTopMembers retrieves up to limit members of a workspace who received the most likes over a
+	// period, ordered by their likes. Without channels, the likes are counted as by FindPeriodLeaderboard,
+	// and otherwise as by FindChannelLeaderboard. Members without likes and members who opted out are left out.
+	// Returns ErrInvalid if likes counted from the recorded Reactions start before they have been pruned,
+	// or, without channels, if they overlap a month with adjustments.
+	FindTopMembers(ctx context.Context, teamID string, channelIDs []string, period Period, limit int) ([]Member, error)
+
+	// FindMonthlyTotals retrieves the reactions received within a workspace for each month from first
+	// to last, including months without any. Without channels, the totals are summed from the monthly
+	// counts, including adjustments, and otherwise counted from the recorded Reactions within the channels.
+	// Members who opted out of the totals are left out.
+	FindMonthlyTotals(ctx context.Context, teamID string, channelIDs []string, first MonthYear, last MonthYear) ([]MonthlyTotal, error)
+
+	// FindLeaderboardTrend retrieves up to limit members of a workspace who received the most likes
+	// over a period as FindTopMembers does and compares them, as well as the reactions received within
+	// the workspace, with the previous period. Members who opted out of the totals are left out of them.
+	// Returns ErrInvalid for the all-time period, which has no previous period, and if either period
+	// counted from the recorded Reactions starts before they have been pruned or, without channels,
+	// overlaps a month with adjustments.
+	FindLeaderboardTrend(ctx context.Context, teamID string, channelIDs []string, period Period, limit int) (*LeaderboardTrend, error)
 }
@@ -0,0 +1,32 @@
+package statsd_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+func TestControversyScore(t *testing.T) {
+	for _, tt := range []struct {
+		likes, dislikes int
+		score           float64
+	}{
+		{0, 0, 0},
+		{0, 3, 0},
+		{40, 0, 0},
+		{3, 3, 6},
+		{35, 40, 43.72},
+		{40, 35, 43.72},
+		{1, 10, 1.27},
+	} {
+		if got := statsd.ControversyScore(tt.likes, tt.dislikes); math.Abs(got-tt.score) > 0.01 {
+			t.Fatalf("ControversyScore(%d, %d)=%v, want %v", tt.likes, tt.dislikes, got, tt.score)
+		}
+	}
+
+	// Ensure a divisive post outranks one which was merely disliked.
+	if statsd.ControversyScore(40, 35) <= statsd.ControversyScore(1, 3) {
+		t.Fatal("expected the balanced post to be more controversial")
+	}
+}
@@ -1,6 +1,8 @@
 package statsd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -11,14 +13,19 @@ type MonthYear string
 
 const monthYearLayout string = "01-2006"
 
-// NewMonthYear returns a new instance of MonthYear.
+// TimeZone is the time zone of the organisation. The boundaries of months are determined
+// within it so that reactions late on the last day of a month still count towards that month.
+// Defaults to UTC. It must only be changed on startup.
+var TimeZone = time.UTC
+
+// NewMonthYear returns the month of t within TimeZone.
 func NewMonthYear(t time.Time) MonthYear {
-	return MonthYear(t.UTC().Format(monthYearLayout))
+	return MonthYear(t.In(TimeZone).Format(monthYearLayout))
 }
 
 // NewMonthYearString returns a new instance of MonthYear.
 func NewMonthYearString(s string) (MonthYear, error) {
-	t, err := time.Parse(monthYearLayout, s)
+	t, err := time.ParseInLocation(monthYearLayout, s, TimeZone)
 	if err != nil {
 		return "", err
 	}
@@ -39,9 +46,19 @@ func (my *MonthYear) Month() (string, error) {
 	return t.Month().String(), nil
 }
 
+// Time returns the first instant of the month within TimeZone.
+func (my *MonthYear) Time() (time.Time, error) {
+	t, err := time.ParseInLocation(monthYearLayout, my.String(), TimeZone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse the MonthYear: %s", my.String())
+	}
+	return t, nil
+}
+
 // Member represents reactions pertaining to a particular member of the slack organization within a given month and year.
 type Member struct {
 	ID               int       `json:"id"`
+	TeamID           string    `json:"teamID"`
 	Date             MonthYear `json:"date"`
 	SlackUID         string    `json:"slackUID"`
 	ReceivedLikes    int       `json:"receivedLikes"`
@@ -53,6 +70,9 @@ type Member struct {
 // Validate returns an error if the member contains invalid fields.
 // This only performs basic validation.
 func (m *Member) Validate() error {
+	if m.TeamID == "" {
+		return fmt.Errorf("slack team ID required %w", ErrInvalid)
+	}
 	if m.SlackUID == "" {
 		return fmt.Errorf("slack user ID required %w", ErrInvalid)
 	}
@@ -63,21 +83,26 @@ func (m *Member) Validate() error {
 type MemberService interface {
 	// FindMemberByID retrieves a Member by ID.
 	// Returns ErrNotFound if the ID does not exist.
-	FindMemberByID(id int) (*Member, error)
+	FindMemberByID(ctx context.Context, id int) (*Member, error)
 
-	// FindMember retrives a Member by his Slack User ID, and date (month and year).
+	// FindMember retrives a Member by his Slack team ID, Slack User ID, and date (month and year).
 	// Returns ErrNotFound if no matches found.
-	FindMember(SlackUID string, date MonthYear) (*Member, error)
+	FindMember(ctx context.Context, teamID string, SlackUID string, date MonthYear) (*Member, error)
 
 	// CreateMember creates a new Member.
-	CreateMember(m *Member) error
+	CreateMember(ctx context.Context, m *Member) error
 
 	// UpdateMember updates a Member.
 	// Returns ErrNotFound if the member does not exist.
-	UpdateMember(id int, upd MemberUpdate) (*Member, error)
+	UpdateMember(ctx context.Context, id int, upd MemberUpdate) (*Member, error)
 
 	// DeleteMember permanently deletes a Member
-	DeleteMember(id int) error
+	DeleteMember(ctx context.Context, id int) error
+
+	// RecomputeMembers rebuilds the received likes and dislikes of every Member of a workspace within a month from the recorded Reactions
+	// and Adjustments. Returns the Members whose counts changed. If dryRun is set, the changes are reported but not saved.
+	// Returns ErrInvalid if the reactions of the month have been pruned.
+	RecomputeMembers(ctx context.Context, teamID string, date MonthYear, dryRun bool) ([]*MemberDiff, error)
 }
 
 // MemberUpdate represents a set of fields to be updated via UpdateMember().
@@ -85,3 +110,32 @@ type MemberUpdate struct {
 	ReceivedLikes    *int
 	ReceivedDislikes *int
 }
+
+// MemberDiff represents the change of a Member's counts caused by RecomputeMembers().
+type MemberDiff struct {
+	SlackUID            string `json:"slackUID"`
+	OldReceivedLikes    int    `json:"oldReceivedLikes"`
+	NewReceivedLikes    int    `json:"newReceivedLikes"`
+	OldReceivedDislikes int    `json:"oldReceivedDislikes"`
+	NewReceivedDislikes int    `json:"newReceivedDislikes"`
+}
+
+// UnbackedMonths returns those of months whose counts differ from the Reactions recorded for them, such as
+// months counted before reactions were recorded. Replaying reactions into them would count reactions twice,
+// so their counts must be rebuilt with RecomputeMembers afterwards. Pruned months are left out since their
+// reactions are never recorded again.
+func UnbackedMonths(ctx context.Context, ms MemberService, teamID string, months []MonthYear) ([]MonthYear, error) {
+	var unbacked []MonthYear
+	for _, month := range months {
+		diffs, err := ms.RecomputeMembers(ctx, teamID, month, true)
+		if errors.Is(err, ErrInvalid) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("UnbackedMonths RecomputeMembers: %w", err)
+		}
+		if len(diffs) > 0 {
+			unbacked = append(unbacked, month)
+		}
+	}
+	return unbacked, nil
+}
@@ -0,0 +1,89 @@
+package statsd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+func TestNewMonthYear(t *testing.T) {
+	// Ensure months are determined within the configured time zone.
+	t.Run("TimeZone", func(t *testing.T) {
+		MustSetTimeZone(t, "America/Los_Angeles")
+		for _, tt := range []struct {
+			t    string
+			want statsd.MonthYear
+		}{
+			// Evening of the last day of the month, which is already the next month in UTC.
+			{"2024-01-31T23:30:00-08:00", "01-2024"},
+			{"2024-02-01T00:00:00-08:00", "02-2024"},
+			{"2024-12-31T23:59:59-08:00", "12-2024"},
+			{"2025-01-01T00:00:00-08:00", "01-2025"},
+			// Daylight saving time started on March 10, 2024.
+			{"2024-03-31T23:59:59-07:00", "03-2024"},
+			{"2024-04-01T00:00:00-07:00", "04-2024"},
+			// Daylight saving time ended on November 3, 2024.
+			{"2024-10-31T23:59:59-07:00", "10-2024"},
+			{"2024-11-30T23:59:59-08:00", "11-2024"},
+			{"2024-12-01T00:00:00-08:00", "12-2024"},
+		} {
+			ts, err := time.Parse(time.RFC3339, tt.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := statsd.NewMonthYear(ts.UTC()); got != tt.want {
+				t.Fatalf("NewMonthYear(%s)=%v, want %v", tt.t, got, tt.want)
+			}
+		}
+	})
+
+	// Ensure UTC is used by default.
+	t.Run("UTC", func(t *testing.T) {
+		if got, want := statsd.NewMonthYear(time.Date(2024, time.January, 31, 23, 30, 0, 0, time.FixedZone("PST", -8*60*60))), statsd.MonthYear("02-2024"); got != want {
+			t.Fatalf("NewMonthYear=%v, want %v", got, want)
+		}
+	})
+}
+
+func TestMonthYear_Time(t *testing.T) {
+	// Ensure a month begins at midnight within the configured time zone, whichever its UTC offset.
+	MustSetTimeZone(t, "America/Los_Angeles")
+	for _, tt := range []struct {
+		month statsd.MonthYear
+		want  string
+	}{
+		{"03-2024", "2024-03-01T00:00:00-08:00"},
+		{"04-2024", "2024-04-01T00:00:00-07:00"},
+		{"11-2024", "2024-11-01T00:00:00-07:00"},
+		{"12-2024", "2024-12-01T00:00:00-08:00"},
+	} {
+		if got, err := tt.month.Time(); err != nil {
+			t.Fatal(err)
+		} else if got.Format(time.RFC3339) != tt.want {
+			t.Fatalf("Time(%s)=%v, want %v", tt.month, got.Format(time.RFC3339), tt.want)
+		} else if month := statsd.NewMonthYear(got); month != tt.month {
+			t.Fatalf("NewMonthYear(Time(%s))=%v", tt.month, month)
+		} else if month := statsd.NewMonthYear(got.Add(-time.Second)); month == tt.month {
+			t.Fatalf("NewMonthYear(Time(%s)-1s)=%v", tt.month, month)
+		}
+	}
+
+	if month, err := statsd.NewMonthYearString("11-2024"); err != nil {
+		t.Fatal(err)
+	} else if got, want := month, statsd.MonthYear("11-2024"); got != want {
+		t.Fatalf("NewMonthYearString=%v, want %v", got, want)
+	}
+}
+
+// MustSetTimeZone sets the time zone of the organisation for the duration of a test. Fatal on error.
+func MustSetTimeZone(tb testing.TB, name string) {
+	tb.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	prev := statsd.TimeZone
+	statsd.TimeZone = loc
+	tb.Cleanup(func() { statsd.TimeZone = prev })
+}
@@ -0,0 +1,25 @@
+package statsd
+
+// Message represents a Slack message together with the likes and dislikes it received.
+type Message struct {
+	TeamID           string `json:"teamID"`
+	ChannelID        string `json:"channelID"`
+	MessageTS        string `json:"messageTS"`
+	AuthorUID        string `json:"authorUID"`
+	ReceivedLikes    int    `json:"receivedLikes"`
+	ReceivedDislikes int    `json:"receivedDislikes"`
+
+	// Link to the message within Slack. Only set once it has been looked up.
+	Permalink string `json:"permalink,omitempty"`
+}
+
+// MessageLeaderboard represents the messages of a workspace which stood out over a Period.
+// MostControversialMessage is the message with the highest ControversyScore and is nil if no message
+// received both likes and dislikes.
+type MessageLeaderboard struct {
+	TeamID                   string   `json:"teamID"`
+	Period                   Period   `json:"period"`
+	ChannelIDs               []string `json:"channelIDs,omitempty"`
+	MostLikedMessage         *Message `json:"mostLikedMessage"`
+	MostControversialMessage *Message `json:"mostControversialMessage,omitempty"`
+}
@@ -0,0 +1,236 @@
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PeriodKind represents the granularity of a Period.
+type PeriodKind string
+
+// Granularities of a Period.
+const (
+	PeriodWeek    PeriodKind = "week"
+	PeriodMonth   PeriodKind = "month"
+	PeriodQuarter PeriodKind = "quarter"
+	PeriodYear    PeriodKind = "year"
+	PeriodCustom  PeriodKind = "custom"
+	PeriodAllTime PeriodKind = "all-time"
+)
+
+const periodDayLayout = "2006-01-02"
+
+// Period represents a span of time within TimeZone which reports and leaderboards cover.
+// Weeks begin on Monday and are numbered according to ISO 8601.
+type Period struct {
+	Kind PeriodKind `json:"kind"`
+
+	// First instant within the period and first instant after it.
+	// Both are zero for the all-time period, which is unbounded.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// NewPeriod returns the period of the given kind containing t.
+// Returns ErrInvalid for custom periods, which have no fixed length.
+func NewPeriod(kind PeriodKind, t time.Time) (Period, error) {
+	t = t.In(TimeZone)
+	y, m, d := t.Date()
+	var start time.Time
+	switch kind {
+	case PeriodWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		start = time.Date(y, m, d-offset, 0, 0, 0, 0, TimeZone)
+	case PeriodMonth:
+		start = time.Date(y, m, 1, 0, 0, 0, 0, TimeZone)
+	case PeriodQuarter:
+		start = time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, TimeZone)
+	case PeriodYear:
+		start = time.Date(y, time.January, 1, 0, 0, 0, 0, TimeZone)
+	default:
+		return Period{}, fmt.Errorf("period must be %s, %s, %s or %s %w", PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear, ErrInvalid)
+	}
+	return Period{Kind: kind, Start: start, End: periodEnd(kind, start)}, nil
+}
+
+// NewCustomPeriod returns the period from start up to, but excluding, end.
+func NewCustomPeriod(start time.Time, end time.Time) (Period, error) {
+	if !start.Before(end) {
+		return Period{}, fmt.Errorf("period must start before it ends %w", ErrInvalid)
+	}
+	return Period{Kind: PeriodCustom, Start: start.In(TimeZone), End: end.In(TimeZone)}, nil
+}
+
+// NewAllTimePeriod returns the period covering everything ever recorded.
+func NewAllTimePeriod() Period {
+	return Period{Kind: PeriodAllTime}
+}
+
+// NewYearToDatePeriod returns the period from the beginning of the year of now up to the end of
+// its current month, so that it consists of whole months.
+func NewYearToDatePeriod(now time.Time) Period {
+	now = now.In(TimeZone)
+	return Period{
+		Kind:  PeriodCustom,
+		Start: time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, TimeZone),
+		End:   time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, TimeZone),
+	}
+}
+
+// NewMonthPeriod returns the period covering a month.
+func NewMonthPeriod(my MonthYear) (Period, error) {
+	t, err := my.Time()
+	if err != nil {
+		return Period{}, fmt.Errorf("valid month required %w", ErrInvalid)
+	}
+	return NewPeriod(PeriodMonth, t)
+}
+
+// ParsePeriod parses the string representation of a period: `2024-W05` for a week, `09-2024`
+// for a month, `2024-Q3` for a quarter, `2024` for a year, `2024-01-01..2024-01-31` for the
+// days of a custom period, both of which are included, `ytd` for the year to date of now and `all-time`.
+func ParsePeriod(s string, now time.Time) (Period, error) {
+	invalid := fmt.Errorf("invalid period %q %w", s, ErrInvalid)
+	if s == string(PeriodAllTime) {
+		return NewAllTimePeriod(), nil
+	}
+	if s == "ytd" {
+		return NewYearToDatePeriod(now), nil
+	}
+	if from, to, ok := strings.Cut(s, ".."); ok {
+		start, err := time.ParseInLocation(periodDayLayout, from, TimeZone)
+		if err != nil {
+			return Period{}, invalid
+		}
+		last, err := time.ParseInLocation(periodDayLayout, to, TimeZone)
+		if err != nil {
+			return Period{}, invalid
+		}
+		return NewCustomPeriod(start, last.AddDate(0, 0, 1))
+	}
+	if rawYear, rawWeek, ok := strings.Cut(s, "-W"); ok {
+		year, err1 := strconv.Atoi(rawYear)
+		week, err2 := strconv.Atoi(rawWeek)
+		if err1 != nil || err2 != nil {
+			return Period{}, invalid
+		}
+		// January 4 always falls within the first week of the year.
+		p, _ := NewPeriod(PeriodWeek, time.Date(year, time.January, 4, 0, 0, 0, 0, TimeZone))
+		p.Start = p.Start.AddDate(0, 0, 7*(week-1))
+		p.End = periodEnd(PeriodWeek, p.Start)
+		if y, w := p.Start.ISOWeek(); y != year || w != week {
+			return Period{}, invalid
+		}
+		return p, nil
+	}
+	if rawYear, rawQuarter, ok := strings.Cut(s, "-Q"); ok {
+		year, err1 := strconv.Atoi(rawYear)
+		quarter, err2 := strconv.Atoi(rawQuarter)
+		if err1 != nil || err2 != nil || quarter < 1 || quarter > 4 {
+			return Period{}, invalid
+		}
+		return NewPeriod(PeriodQuarter, time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, TimeZone))
+	}
+	if len(s) == 4 {
+		year, err := strconv.Atoi(s)
+		if err != nil {
+			return Period{}, invalid
+		}
+		return NewPeriod(PeriodYear, time.Date(year, time.January, 1, 0, 0, 0, 0, TimeZone))
+	}
+	my, err := NewMonthYearString(s)
+	if err != nil {
+		return Period{}, invalid
+	}
+	return NewMonthPeriod(my)
+}
+
+// String returns the representation of the period accepted by ParsePeriod().
+func (p Period) String() string {
+	switch p.Kind {
+	case PeriodWeek:
+		year, week := p.Start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodMonth:
+		return string(NewMonthYear(p.Start))
+	case PeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", p.Start.Year(), (int(p.Start.Month())+2)/3)
+	case PeriodYear:
+		return strconv.Itoa(p.Start.Year())
+	case PeriodAllTime:
+		return string(PeriodAllTime)
+	}
+	return p.Start.Format(periodDayLayout) + ".." + p.End.AddDate(0, 0, -1).Format(periodDayLayout)
+}
+
+// Title returns a human readable name of the period used within reports, e.g. `September 2024`.
+func (p Period) Title() string {
+	switch p.Kind {
+	case PeriodWeek:
+		year, week := p.Start.ISOWeek()
+		return fmt.Sprintf("week %d of %d", week, year)
+	case PeriodMonth:
+		return p.Start.Format("January 2006")
+	case PeriodQuarter:
+		return fmt.Sprintf("Q%d %d", (int(p.Start.Month())+2)/3, p.Start.Year())
+	case PeriodYear:
+		return strconv.Itoa(p.Start.Year())
+	case PeriodAllTime:
+		return "all time"
+	}
+	return p.Start.Format("January 2, 2006") + " to " + p.End.AddDate(0, 0, -1).Format("January 2, 2006")
+}
+
+// Prev returns the period of the same length which ends when p starts.
+// The all-time period has no predecessor and is returned as is.
+func (p Period) Prev() Period {
+	if p.Kind == PeriodAllTime {
+		return p
+	}
+	if p.Kind == PeriodCustom {
+		return Period{Kind: PeriodCustom, Start: p.Start.Add(-p.End.Sub(p.Start)), End: p.Start}
+	}
+	prev, _ := NewPeriod(p.Kind, p.Start.AddDate(0, 0, -1))
+	return prev
+}
+
+// Months returns the first and last month covered by the period if it consists of whole months.
+// The all-time period is unbounded and has no first and last month.
+func (p Period) Months() (first MonthYear, last MonthYear, ok bool) {
+	if p.Kind == PeriodAllTime {
+		return "", "", false
+	}
+	start, end := p.Start.In(TimeZone), p.End.In(TimeZone)
+	if !isMonthStart(start) || !isMonthStart(end) {
+		return "", "", false
+	}
+	return NewMonthYear(start), NewMonthYear(end.AddDate(0, -1, 0)), true
+}
+
+// Contains reports whether t falls within the period.
+func (p Period) Contains(t time.Time) bool {
+	if p.Kind == PeriodAllTime {
+		return true
+	}
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// periodEnd returns the first instant after the period of the given kind which begins at start.
+func periodEnd(kind PeriodKind, start time.Time) time.Time {
+	switch kind {
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	case PeriodQuarter:
+		return start.AddDate(0, 3, 0)
+	}
+	return start.AddDate(1, 0, 0)
+}
+
+// isMonthStart reports whether t is midnight of the first day of a month.
+func isMonthStart(t time.Time) bool {
+	return t.Day() == 1 && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
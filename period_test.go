@@ -0,0 +1,142 @@
+package statsd_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+func TestParsePeriod(t *testing.T) {
+	// Ensure every kind of period is parsed and formatted again.
+	t.Run("OK", func(t *testing.T) {
+		MustSetTimeZone(t, "America/Los_Angeles")
+		for _, tt := range []struct {
+			s          string
+			kind       statsd.PeriodKind
+			start, end string
+			title      string
+		}{
+			{"2024-W01", statsd.PeriodWeek, "2024-01-01T00:00:00-08:00", "2024-01-08T00:00:00-08:00", "week 1 of 2024"},
+			{"2020-W53", statsd.PeriodWeek, "2020-12-28T00:00:00-08:00", "2021-01-04T00:00:00-08:00", "week 53 of 2020"},
+			{"2024-W10", statsd.PeriodWeek, "2024-03-04T00:00:00-08:00", "2024-03-11T00:00:00-07:00", "week 10 of 2024"},
+			{"09-2024", statsd.PeriodMonth, "2024-09-01T00:00:00-07:00", "2024-10-01T00:00:00-07:00", "September 2024"},
+			{"2024-Q4", statsd.PeriodQuarter, "2024-10-01T00:00:00-07:00", "2025-01-01T00:00:00-08:00", "Q4 2024"},
+			{"2024", statsd.PeriodYear, "2024-01-01T00:00:00-08:00", "2025-01-01T00:00:00-08:00", "2024"},
+			{"2024-01-15..2024-02-14", statsd.PeriodCustom, "2024-01-15T00:00:00-08:00", "2024-02-15T00:00:00-08:00", "January 15, 2024 to February 14, 2024"},
+			{"all-time", statsd.PeriodAllTime, "0001-01-01T00:00:00Z", "0001-01-01T00:00:00Z", "all time"},
+		} {
+			p, err := statsd.ParsePeriod(tt.s, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			} else if got, want := p.Kind, tt.kind; got != want {
+				t.Fatalf("%s: Kind=%v, want %v", tt.s, got, want)
+			} else if got, want := p.Start.Format(time.RFC3339), tt.start; got != want {
+				t.Fatalf("%s: Start=%v, want %v", tt.s, got, want)
+			} else if got, want := p.End.Format(time.RFC3339), tt.end; got != want {
+				t.Fatalf("%s: End=%v, want %v", tt.s, got, want)
+			} else if got, want := p.String(), tt.s; got != want {
+				t.Fatalf("String=%v, want %v", got, want)
+			} else if got, want := p.Title(), tt.title; got != want {
+				t.Fatalf("%s: Title=%v, want %v", tt.s, got, want)
+			}
+		}
+	})
+
+	// Ensure `ytd` is the year to date of now.
+	t.Run("YearToDate", func(t *testing.T) {
+		MustSetTimeZone(t, "America/Los_Angeles")
+		now := time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC)
+		if p, err := statsd.ParsePeriod("ytd", now); err != nil {
+			t.Fatal(err)
+		} else if got, want := p, statsd.NewYearToDatePeriod(now); got != want {
+			t.Fatalf("ParsePeriod=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure malformed periods are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		for _, s := range []string{"", "2024-W54", "2021-W53", "2024-Q5", "13-2024", "2024-02-01..2024-01-01", "yesterday"} {
+			if _, err := statsd.ParsePeriod(s, time.Now()); !errors.Is(err, statsd.ErrInvalid) {
+				t.Fatalf("%q: unexpected error: %#v", s, err)
+			}
+		}
+	})
+}
+
+func TestNewPeriod(t *testing.T) {
+	// Ensure the period containing a time is found within the configured time zone.
+	MustSetTimeZone(t, "America/Los_Angeles")
+	now := time.Date(2024, time.January, 1, 5, 0, 0, 0, time.UTC) // Sunday, December 31, 2023 in Los Angeles.
+	for _, tt := range []struct {
+		kind statsd.PeriodKind
+		want string
+		prev string
+	}{
+		{statsd.PeriodWeek, "2023-W52", "2023-W51"},
+		{statsd.PeriodMonth, "12-2023", "11-2023"},
+		{statsd.PeriodQuarter, "2023-Q4", "2023-Q3"},
+		{statsd.PeriodYear, "2023", "2022"},
+	} {
+		p, err := statsd.NewPeriod(tt.kind, now)
+		if err != nil {
+			t.Fatal(err)
+		} else if got := p.String(); got != tt.want {
+			t.Fatalf("NewPeriod(%s)=%v, want %v", tt.kind, got, tt.want)
+		} else if got := p.Prev().String(); got != tt.prev {
+			t.Fatalf("Prev(%s)=%v, want %v", tt.want, got, tt.prev)
+		} else if !p.Contains(now) || p.Prev().Contains(now) {
+			t.Fatalf("%s: unexpected Contains", tt.want)
+		}
+	}
+
+	if _, err := statsd.NewPeriod(statsd.PeriodCustom, now); !errors.Is(err, statsd.ErrInvalid) {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
+
+func TestPeriod_Months(t *testing.T) {
+	// Ensure only periods of whole months are reported as months.
+	for _, tt := range []struct {
+		s           string
+		first, last statsd.MonthYear
+		ok          bool
+	}{
+		{"2024-Q2", "04-2024", "06-2024", true},
+		{"2024", "01-2024", "12-2024", true},
+		{"05-2024", "05-2024", "05-2024", true},
+		{"2024-03-01..2024-04-30", "03-2024", "04-2024", true},
+		{"2024-03-01..2024-04-29", "", "", false},
+		{"2024-W05", "", "", false},
+	} {
+		p, err := statsd.ParsePeriod(tt.s, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first, last, ok := p.Months(); first != tt.first || last != tt.last || ok != tt.ok {
+			t.Fatalf("Months(%s)=%v, %v, %v", tt.s, first, last, ok)
+		}
+	}
+}
+
+func TestNewYearToDatePeriod(t *testing.T) {
+	// Ensure the year to date consists of whole months up to the end of the current one.
+	MustSetTimeZone(t, "America/Los_Angeles")
+	p := statsd.NewYearToDatePeriod(time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC))
+	if got, want := p.String(), "2024-01-01..2024-09-30"; got != want {
+		t.Fatalf("String=%v, want %v", got, want)
+	} else if first, last, ok := p.Months(); first != "01-2024" || last != "09-2024" || !ok {
+		t.Fatalf("Months()=%v, %v, %v", first, last, ok)
+	}
+}
+
+// MustParsePeriod parses a period or fails the test.
+func MustParsePeriod(tb testing.TB, s string) statsd.Period {
+	tb.Helper()
+	p, err := statsd.ParsePeriod(s, time.Now())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return p
+}
@@ -0,0 +1,70 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Actions recorded in the audit log for privacy controls.
+const (
+	AuditActionOptOut = "member.optout"
+	AuditActionOptIn  = "member.optin"
+	AuditActionForget = "member.forget"
+)
+
+// OptOut represents a Member of a workspace who doesn't want to appear in leaderboards and monthly updates.
+type OptOut struct {
+	TeamID   string `json:"teamID"`
+	SlackUID string `json:"slackUID"`
+
+	// CountTotals reports whether the member's reactions are still included in the aggregate totals of the workspace.
+	CountTotals bool `json:"countTotals"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate returns an error if the opt-out contains invalid fields.
+// This only performs basic validation.
+func (o *OptOut) Validate() error {
+	if o.TeamID == "" {
+		return fmt.Errorf("slack team ID required %w", ErrInvalid)
+	}
+	if o.SlackUID == "" {
+		return fmt.Errorf("slack user ID required %w", ErrInvalid)
+	}
+	return nil
+}
+
+// ForgetResult reports the number of rows erased by ForgetMember() per table and the number of
+// audit log entries from which the member was redacted.
+type ForgetResult struct {
+	Members      int `json:"members"`
+	Reactions    int `json:"reactions"`
+	LikedWeeks   int `json:"likedWeeks"`
+	Adjustments  int `json:"adjustments"`
+	OptOuts      int `json:"optOuts"`
+	AuditEntries int `json:"auditEntries"`
+
+	// Pseudonym replaces the Slack user ID of the member within the audit log. It's a salted hash
+	// of the ID, so the erasure itself can be recorded without the ID.
+	Pseudonym string `json:"pseudonym"`
+}
+
+// PrivacyService represents a service for managing the privacy controls of Members.
+type PrivacyService interface {
+	// OptOut excludes a Member from leaderboards. Opting out again replaces the previous choice.
+	OptOut(ctx context.Context, o *OptOut) error
+
+	// OptIn includes a Member in leaderboards again.
+	// Returns ErrNotFound if the member hasn't opted out.
+	OptIn(ctx context.Context, teamID string, slackUID string) error
+
+	// FindOptOuts retrieves the opt-outs of a workspace.
+	FindOptOuts(ctx context.Context, teamID string) ([]*OptOut, error)
+
+	// ForgetMember permanently erases every row pertaining to a Member of a workspace, including
+	// the reactions they gave, which are also removed from the counts of their recipients. The
+	// member's Slack user ID is replaced by a pseudonym within the audit log.
+	ForgetMember(ctx context.Context, teamID string, slackUID string) (*ForgetResult, error)
+}
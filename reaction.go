@@ -0,0 +1,97 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the Slack reactions which are counted as likes and dislikes.
+const (
+	ThumbsUp   = "+1"
+	ThumbsDown = "-1"
+)
+
+// Reaction represents a single counted reaction one member of the slack organization left on the message of another.
+type Reaction struct {
+	ID         int       `json:"id"`
+	TeamID     string    `json:"teamID"`
+	Date       MonthYear `json:"date"`
+	ChannelID  string    `json:"channelID"`
+	MessageTS  string    `json:"messageTS"`
+	ReactorUID string    `json:"reactorUID"`
+	AuthorUID  string    `json:"authorUID"`
+	Name       string    `json:"name"`
+	ReactedAt  time.Time `json:"reactedAt"`
+}
+
+// Validate returns an error if the reaction contains invalid fields.
+// This only performs basic validation.
+func (r *Reaction) Validate() error {
+	if r.TeamID == "" {
+		return fmt.Errorf("slack team ID required %w", ErrInvalid)
+	}
+	if r.AuthorUID == "" {
+		return fmt.Errorf("author slack user ID required %w", ErrInvalid)
+	}
+	if r.ReactorUID == "" {
+		return fmt.Errorf("reactor slack user ID required %w", ErrInvalid)
+	}
+	if !IsCountedReaction(r.Name) {
+		return fmt.Errorf("reaction %q is not counted %w", r.Name, ErrInvalid)
+	}
+	return nil
+}
+
+// ReactionService represents a service for recording Reactions.
+type ReactionService interface {
+	// CreateReaction records a Reaction and adds it to the received likes or dislikes of its author.
+	// Returns ErrConflict if the reaction has already been recorded or falls within a pruned month,
+	// whose reactions are only kept within the aggregated counts.
+	CreateReaction(ctx context.Context, r *Reaction) error
+
+	// DeleteReaction removes a Reaction and subtracts it from the received likes or dislikes of its author.
+	// Returns ErrNotFound if the reaction has not been recorded.
+	DeleteReaction(ctx context.Context, teamID string, channelID string, messageTS string, reactorUID string, name string) error
+}
+
+// IsCountedReaction reports whether the named Slack reaction is counted as a like or dislike.
+func IsCountedReaction(name string) bool {
+	return name == ThumbsUp || name == ThumbsDown
+}
+
+// CountReaction records a reaction and reports whether it was recorded. Reactions which aren't counted,
+// target Slackbot or an unknown author, or have already been recorded are left out without an error.
+// It is shared by live events, the backfill and the import of export archives so all of them count
+// reactions in the same way.
+func CountReaction(ctx context.Context, rs ReactionService, r *Reaction) (bool, error) {
+	if !IsCountedReaction(r.Name) || r.AuthorUID == "" || r.AuthorUID == "USLACKBOT" {
+		return false, nil
+	}
+	err := rs.CreateReaction(ctx, r)
+	if errors.Is(err, ErrConflict) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("CountReaction CreateReaction: %w", err)
+	}
+	return true, nil
+}
+
+// ParseTimestamp converts a Slack timestamp such as `1360782804.083113` into a time.Time.
+func ParseTimestamp(ts string) (time.Time, error) {
+	rawSec, rawMicro, _ := strings.Cut(ts, ".")
+	sec, err := strconv.ParseInt(rawSec, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse the timestamp: %s", ts)
+	}
+	var micro int64
+	if rawMicro != "" {
+		if micro, err = strconv.ParseInt(rawMicro, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("unable to parse the timestamp: %s", ts)
+		}
+	}
+	return time.Unix(sec, micro*int64(time.Microsecond)).UTC(), nil
+}
@@ -0,0 +1,38 @@
+package statsd
+
+import "time"
+
+// AuditActionPrune is the action recorded in the audit log when data is pruned by hand.
+const AuditActionPrune = "data.prune"
+
+// DefaultPruneBatchSize is the number of rows deleted per batch if a RetentionPolicy doesn't set it.
+const DefaultPruneBatchSize = 1000
+
+// RetentionPolicy determines how long data is kept before it is pruned. The monthly
+// aggregates of Members and their Adjustments are kept forever.
+type RetentionPolicy struct {
+	// Number of months of Reactions kept, including the current month. Zero keeps them forever.
+	ReactionMonths int
+
+	// Number of months of audit log entries kept, including the current month. Zero keeps them forever.
+	AuditMonths int
+
+	// Maximum number of rows deleted at once so that writers aren't blocked for long.
+	BatchSize int
+}
+
+// RetentionCutoff returns the first instant kept by a retention of months relative to now.
+// Months begin within TimeZone. Returns the zero time if months is zero, i.e. everything is kept.
+func RetentionCutoff(now time.Time, months int) time.Time {
+	if months <= 0 {
+		return time.Time{}
+	}
+	now = now.In(TimeZone)
+	return time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, TimeZone)
+}
+
+// PruneResult reports the number of rows pruned per table.
+type PruneResult struct {
+	Reactions    int `json:"reactions"`
+	AuditEntries int `json:"auditEntries"`
+}
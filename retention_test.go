@@ -0,0 +1,23 @@
+package statsd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+func TestRetentionCutoff(t *testing.T) {
+	// Ensure the cutoff is the beginning of a month within the configured time zone.
+	MustSetTimeZone(t, "America/Los_Angeles")
+	now := time.Date(2024, time.December, 1, 6, 0, 0, 0, time.UTC) // November 30 in Los Angeles.
+	if got, want := statsd.RetentionCutoff(now, 1).Format(time.RFC3339), "2024-11-01T00:00:00-07:00"; got != want {
+		t.Fatalf("RetentionCutoff=%v, want %v", got, want)
+	}
+	if got, want := statsd.RetentionCutoff(now, 13).Format(time.RFC3339), "2023-11-01T00:00:00-07:00"; got != want {
+		t.Fatalf("RetentionCutoff=%v, want %v", got, want)
+	}
+	if !statsd.RetentionCutoff(now, 0).IsZero() {
+		t.Fatal("expected zero cutoff")
+	}
+}
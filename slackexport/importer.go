@@ -0,0 +1,352 @@
+// Package slackexport imports reactions from the standard Slack workspace export archive.
+package slackexport
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+// conversationFiles are the files at the root of an export which map conversation folders to their IDs,
+// along with the type of the conversations each of them lists.
+var conversationFiles = []struct {
+	name string
+	typ  statsd.ChannelType
+}{
+	{"channels.json", statsd.ChannelPublic},
+	{"groups.json", statsd.ChannelPrivate},
+	{"mpims.json", statsd.ChannelMPIM},
+	{"dms.json", statsd.ChannelDM},
+}
+
+// conversation represents an entry of one of the conversationFiles.
+type conversation struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// user represents an entry of the users.json file at the root of an export.
+type user struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+	IsBot   bool   `json:"is_bot"`
+}
+
+// message represents a message within the per-day files of an export.
+type message struct {
+	User      string     `json:"user"`
+	BotID     string     `json:"bot_id"`
+	TS        string     `json:"ts"`
+	Reactions []reaction `json:"reactions"`
+}
+
+// reaction represents a reaction on a message within an export.
+type reaction struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+	Count int      `json:"count"`
+}
+
+// ImportResult summarizes the reactions processed by an import.
+type ImportResult struct {
+	// Recorded is the number of reactions that were newly recorded.
+	Recorded int `json:"recorded"`
+	// Duplicates is the number of reactions that had already been recorded.
+	Duplicates int `json:"duplicates"`
+	// Skipped is the number of reactions that were left out: those to or by bots, those to deleted
+	// users and those whose users the export doesn't list.
+	Skipped int `json:"skipped"`
+}
+
+// Importer records the reactions found within a Slack export archive.
+type Importer struct {
+	// Services used by Importer
+	ReactionService statsd.ReactionService
+	MemberService   statsd.MemberService
+
+	// Dependencies
+	logger        *slog.Logger
+	channelFilter statsd.ChannelFilter
+}
+
+// NewImporter creates a new instance of Importer.
+func NewImporter(logger *slog.Logger, rs statsd.ReactionService, ms statsd.MemberService, filter statsd.ChannelFilter) *Importer {
+	return &Importer{
+		logger:          logger,
+		channelFilter:   filter,
+		ReactionService: rs,
+		MemberService:   ms,
+	}
+}
+
+// channelFile represents the messages with reactions within one of the per-day files of an export.
+type channelFile struct {
+	name      string
+	channelID string
+	msgs      []message
+}
+
+// ImportFile records the reactions found within the export archive of a workspace at the given path.
+func (i *Importer) ImportFile(ctx context.Context, teamID string, name string, recompute bool) (*ImportResult, error) {
+	rc, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("ImportFile: %w", err)
+	}
+	defer rc.Close()
+	return i.Import(ctx, teamID, &rc.Reader, recompute)
+}
+
+// Import records the reactions found within the export archive of a workspace.
+//
+// Reactions which have already been recorded, whether by live events, a backfill or a
+// previous import, are skipped so the same archive may be imported more than once. Channels
+// rejected by the channel filter are left out, as they are for live events.
+//
+// Exports don't include when a reaction was left, so reactions are dated by the message they were
+// left on. A reaction left after the end of the month its message was posted in counts towards the
+// month of the message, unlike with live counting, and streaks and the heatmap place it at the time
+// of the message.
+//
+// Months whose counts aren't backed by recorded reactions, such as those counted before reactions
+// were recorded, would be counted twice. Unless recompute is set, Import returns ErrInvalid for
+// them. Otherwise their counts are rebuilt from the recorded reactions once the import is done.
+func (i *Importer) Import(ctx context.Context, teamID string, zr *zip.Reader, recompute bool) (*ImportResult, error) {
+	channelIDs, channelTypes, err := readConversations(zr)
+	if err != nil {
+		return nil, fmt.Errorf("Import: %w", err)
+	}
+	users, err := readUsers(zr)
+	if err != nil {
+		return nil, fmt.Errorf("Import: %w", err)
+	}
+	files, err := i.readChannelFiles(zr, channelIDs, channelTypes)
+	if err != nil {
+		return nil, fmt.Errorf("Import: %w", err)
+	}
+
+	counted, err := statsd.UnbackedMonths(ctx, i.MemberService, teamID, months(files))
+	if err != nil {
+		return nil, fmt.Errorf("Import: %w", err)
+	}
+	if len(counted) > 0 && !recompute {
+		return nil, fmt.Errorf("Import: counts of %v aren't backed by recorded reactions and must be recomputed %w", counted, statsd.ErrInvalid)
+	}
+
+	result := &ImportResult{}
+	for _, f := range files {
+		for _, msg := range f.msgs {
+			if err := i.importMessage(ctx, teamID, f.channelID, msg, users, result); err != nil {
+				return nil, fmt.Errorf("Import %s: %w", f.name, err)
+			}
+		}
+	}
+
+	for _, month := range counted {
+		diffs, err := i.MemberService.RecomputeMembers(ctx, teamID, month, false)
+		if err != nil {
+			return nil, fmt.Errorf("Import RecomputeMembers: %w", err)
+		}
+		i.logger.Info("recomputed month", slog.String("month", month.String()), slog.Int("changed", len(diffs)))
+	}
+
+	i.logger.Info("imported export archive", slog.Int("recorded", result.Recorded), slog.Int("duplicates", result.Duplicates), slog.Int("skipped", result.Skipped))
+	return result, nil
+}
+
+// readChannelFiles returns the messages with reactions within the per-day files of the channels
+// allowed by the channel filter.
+func (i *Importer) readChannelFiles(zr *zip.Reader, channelIDs map[string]string, channelTypes map[string]statsd.ChannelType) ([]channelFile, error) {
+	var files []channelFile
+	allowed := make(map[string]bool)
+	for _, f := range zr.File {
+		dir, file := path.Split(f.Name)
+		dir = strings.Trim(dir, "/")
+		if dir == "" || strings.Contains(dir, "/") || path.Ext(file) != ".json" {
+			continue
+		}
+		// Folders are named after channels, except for direct messages which are named after their ID.
+		channelID, ok := channelIDs[dir]
+		if !ok {
+			channelID = dir
+		}
+		if _, ok := allowed[channelID]; !ok {
+			allowed[channelID] = i.allowChannel(channelID, channelTypes)
+		}
+		if !allowed[channelID] {
+			continue
+		}
+
+		var msgs []message
+		if err := decodeFile(f, &msgs); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		cf := channelFile{name: f.Name, channelID: channelID}
+		for _, msg := range msgs {
+			if len(msg.Reactions) > 0 {
+				cf.msgs = append(cf.msgs, msg)
+			}
+		}
+		files = append(files, cf)
+	}
+	return files, nil
+}
+
+// months returns the months the messages within files were posted in, in chronological order.
+// Messages whose timestamp can't be parsed are left to importMessage to report.
+func months(files []channelFile) []statsd.MonthYear {
+	seen := make(map[statsd.MonthYear]time.Time)
+	var months []statsd.MonthYear
+	for _, f := range files {
+		for _, msg := range f.msgs {
+			t, err := statsd.ParseTimestamp(msg.TS)
+			if err != nil {
+				continue
+			}
+			month := statsd.NewMonthYear(t)
+			if _, ok := seen[month]; !ok {
+				seen[month] = t
+				months = append(months, month)
+			}
+		}
+	}
+	sort.Slice(months, func(a, b int) bool { return seen[months[a]].Before(seen[months[b]]) })
+	return months
+}
+
+// importMessage records the counted reactions of a single message.
+func (i *Importer) importMessage(ctx context.Context, teamID string, channelID string, msg message, users map[string]user, result *ImportResult) error {
+	if len(msg.Reactions) == 0 {
+		return nil
+	}
+	reactedAt, err := statsd.ParseTimestamp(msg.TS)
+	if err != nil {
+		return err
+	}
+
+	author, ok := users[msg.User]
+	validAuthor := msg.BotID == "" && !(ok && (author.IsBot || author.Deleted))
+	for _, r := range msg.Reactions {
+		if !statsd.IsCountedReaction(r.Name) {
+			continue
+		}
+		// Exports truncate the list of users on popular reactions.
+		result.Skipped += max(r.Count-len(r.Users), 0)
+		for _, reactorUID := range r.Users {
+			if reactor, ok := users[reactorUID]; !validAuthor || (ok && reactor.IsBot) {
+				result.Skipped++
+				continue
+			}
+			recorded, err := statsd.CountReaction(ctx, i.ReactionService, &statsd.Reaction{
+				TeamID:     teamID,
+				Date:       statsd.NewMonthYear(reactedAt),
+				ChannelID:  channelID,
+				MessageTS:  msg.TS,
+				ReactorUID: reactorUID,
+				AuthorUID:  msg.User,
+				Name:       r.Name,
+				ReactedAt:  reactedAt,
+			})
+			if err != nil {
+				return err
+			}
+			switch {
+			case recorded:
+				result.Recorded++
+			case msg.User == "" || msg.User == "USLACKBOT":
+				result.Skipped++
+			default:
+				result.Duplicates++
+			}
+		}
+	}
+	return nil
+}
+
+// allowChannel reports whether reactions within a channel are counted according to the channel filter.
+// Channels which none of the conversationFiles list have an unknown type and are left out if the
+// filter restricts types.
+func (i *Importer) allowChannel(channelID string, channelTypes map[string]statsd.ChannelType) bool {
+	ok, reason, err := i.channelFilter.Allows(channelID, func() (statsd.ChannelType, error) {
+		typ, ok := channelTypes[channelID]
+		if !ok {
+			return "", fmt.Errorf("channel %s isn't listed within the export %w", channelID, statsd.ErrNotFound)
+		}
+		return typ, nil
+	})
+	if err != nil {
+		i.logger.Error("look up channel type", slog.String("channel", channelID), slog.String("error", err.Error()))
+		ok, reason = false, statsd.ChannelTypeUnknown
+	}
+	if !ok {
+		i.logger.Info("skipping filtered channel", slog.String("channel", channelID), slog.String("reason", reason))
+	}
+	return ok
+}
+
+// readConversations maps the folder names of an export to their conversation IDs and the
+// conversation IDs to their types.
+func readConversations(zr *zip.Reader) (map[string]string, map[string]statsd.ChannelType, error) {
+	channelIDs := make(map[string]string)
+	channelTypes := make(map[string]statsd.ChannelType)
+	for _, file := range conversationFiles {
+		f, err := zr.Open(file.name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		var conversations []conversation
+		err = json.NewDecoder(f).Decode(&conversations)
+		f.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file.name, err)
+		}
+		for _, c := range conversations {
+			if c.Name != "" {
+				channelIDs[c.Name] = c.ID
+			}
+			channelTypes[c.ID] = file.typ
+		}
+	}
+	return channelIDs, channelTypes, nil
+}
+
+// readUsers maps the user IDs of an export to their users.
+func readUsers(zr *zip.Reader) (map[string]user, error) {
+	users := make(map[string]user)
+	f, err := zr.Open("users.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return users, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var a []user
+	if err := json.NewDecoder(f).Decode(&a); err != nil {
+		return nil, fmt.Errorf("users.json: %w", err)
+	}
+	for _, u := range a {
+		users[u.ID] = u
+	}
+	return users, nil
+}
+
+// decodeFile decodes the JSON contents of a file within the archive into v.
+func decodeFile(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,168 @@
+package slackexport_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/slackexport"
+	"github.com/ddritzenhoff/statsd/sqlite"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestImporter_Import(t *testing.T) {
+	// Ensure reactions are imported and counted towards their authors, except for those involving
+	// bots or deleted users and those whose users the export doesn't list.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer db.Close()
+		imp := slackexport.NewImporter(slog.New(slog.NewTextHandler(io.Discard, nil)), sqlite.NewReactionService(db), sqlite.NewMemberService(db), statsd.ChannelFilter{})
+
+		if result, err := imp.Import(context.Background(), "T0EXAMPLE", MustZipDir(t, "testdata/export"), false); err != nil {
+			t.Fatal(err)
+		} else if got, want := *result, (slackexport.ImportResult{Recorded: 6, Skipped: 6}); got != want {
+			t.Fatalf("result=%+v, want %+v", got, want)
+		}
+		MustHaveCounts(t, db, "U0ALICE", "01-2023", 2, 1)
+		MustHaveCounts(t, db, "U0ALICE", "02-2023", 1, 0)
+		MustHaveCounts(t, db, "U0BOB", "02-2023", 1, 1)
+		if _, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T0EXAMPLE", "U0DAVE", "02-2023"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	// Ensure importing the same archive twice does not count reactions twice.
+	t.Run("Idempotent", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer db.Close()
+		imp := slackexport.NewImporter(slog.New(slog.NewTextHandler(io.Discard, nil)), sqlite.NewReactionService(db), sqlite.NewMemberService(db), statsd.ChannelFilter{})
+
+		if _, err := imp.Import(context.Background(), "T0EXAMPLE", MustZipDir(t, "testdata/export"), false); err != nil {
+			t.Fatal(err)
+		}
+		if result, err := imp.Import(context.Background(), "T0EXAMPLE", MustZipDir(t, "testdata/export"), false); err != nil {
+			t.Fatal(err)
+		} else if got, want := *result, (slackexport.ImportResult{Duplicates: 6, Skipped: 6}); got != want {
+			t.Fatalf("result=%+v, want %+v", got, want)
+		}
+		MustHaveCounts(t, db, "U0ALICE", "01-2023", 2, 1)
+		MustHaveCounts(t, db, "U0BOB", "02-2023", 1, 1)
+	})
+
+	// Ensure channels rejected by the channel filter are left out.
+	t.Run("ChannelFilter", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer db.Close()
+		filter := statsd.ChannelFilter{Exclude: []string{"C0GENERAL"}, Types: []statsd.ChannelType{statsd.ChannelPublic}}
+		imp := slackexport.NewImporter(slog.New(slog.NewTextHandler(io.Discard, nil)), sqlite.NewReactionService(db), sqlite.NewMemberService(db), filter)
+
+		if result, err := imp.Import(context.Background(), "T0EXAMPLE", MustZipDir(t, "testdata/export"), false); err != nil {
+			t.Fatal(err)
+		} else if got, want := *result, (slackexport.ImportResult{Recorded: 2, Skipped: 5}); got != want {
+			t.Fatalf("result=%+v, want %+v", got, want)
+		}
+		MustHaveCounts(t, db, "U0BOB", "02-2023", 1, 1)
+		for _, date := range []statsd.MonthYear{"01-2023", "02-2023"} {
+			if _, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T0EXAMPLE", "U0ALICE", date); !errors.Is(err, statsd.ErrNotFound) {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+	})
+
+	// Ensure months counted before their reactions were recorded are only imported into if they are recomputed.
+	t.Run("Recompute", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer db.Close()
+		ms := sqlite.NewMemberService(db)
+		imp := slackexport.NewImporter(slog.New(slog.NewTextHandler(io.Discard, nil)), sqlite.NewReactionService(db), ms, statsd.ChannelFilter{})
+		m := &statsd.Member{TeamID: "T0EXAMPLE", Date: "01-2023", SlackUID: "U0ALICE"}
+		if err := ms.CreateMember(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		likes := 5
+		if _, err := ms.UpdateMember(context.Background(), m.ID, statsd.MemberUpdate{ReceivedLikes: &likes}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := imp.Import(context.Background(), "T0EXAMPLE", MustZipDir(t, "testdata/export"), false); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		MustHaveCounts(t, db, "U0ALICE", "01-2023", 5, 0)
+		if _, err := ms.FindMember(context.Background(), "T0EXAMPLE", "U0BOB", "02-2023"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if result, err := imp.Import(context.Background(), "T0EXAMPLE", MustZipDir(t, "testdata/export"), true); err != nil {
+			t.Fatal(err)
+		} else if got, want := *result, (slackexport.ImportResult{Recorded: 6, Skipped: 6}); got != want {
+			t.Fatalf("result=%+v, want %+v", got, want)
+		}
+		MustHaveCounts(t, db, "U0ALICE", "01-2023", 2, 1)
+		MustHaveCounts(t, db, "U0BOB", "02-2023", 1, 1)
+	})
+}
+
+// MustOpenDB returns a new, open in-memory DB. Fatal on error.
+func MustOpenDB(tb testing.TB) *sqlite.DB {
+	tb.Helper()
+	db := sqlite.NewDB("file::memory:?cache=shared")
+	if err := db.Open(); err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+// MustZipDir returns an in-memory zip archive of the files within dir. Fatal on error.
+func MustZipDir(tb testing.TB, dir string) *zip.Reader {
+	tb.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(filepath.ToSlash(name))
+		if err != nil {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return zr
+}
+
+// MustHaveCounts verifies the received likes and dislikes of a member. Fatal on mismatch.
+func MustHaveCounts(tb testing.TB, db *sqlite.DB, slackUID string, date statsd.MonthYear, likes int, dislikes int) {
+	tb.Helper()
+	m, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T0EXAMPLE", slackUID, date)
+	if err != nil {
+		tb.Fatal(err)
+	} else if m.ReceivedLikes != likes || m.ReceivedDislikes != dislikes {
+		tb.Fatalf("%s %s: likes=%d dislikes=%d, want likes=%d dislikes=%d", slackUID, date, m.ReceivedLikes, m.ReceivedDislikes, likes, dislikes)
+	}
+}
@@ -0,0 +1,206 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.AdjustmentService = (*AdjustmentService)(nil)
+
+// AdjustmentService represents a service for managing Adjustments.
+type AdjustmentService struct {
+	db *DB
+}
+
+// NewAdjustmentService returns a new instance of AdjustmentService.
+func NewAdjustmentService(db *DB) *AdjustmentService {
+	return &AdjustmentService{
+		db: db,
+	}
+}
+
+// CreateAdjustment records an Adjustment and applies its delta to the Member's counts.
+// Returns ErrInvalid if the counts would become negative.
+func (as *AdjustmentService) CreateAdjustment(ctx context.Context, a *statsd.Adjustment) error {
+	ctx, span := tracer.Start(ctx, "AdjustmentService.CreateAdjustment")
+	defer span.End()
+
+	if a == nil {
+		return fmt.Errorf("CreateAdjustment: a reference is nil")
+	}
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := as.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	query := newQueries(tx.Tx)
+
+	// Ensure a negative delta doesn't take more than the member has received.
+	likes, dislikes := adjustmentCounts(a.Metric, a.Delta)
+	if a.Delta < 0 {
+		genMember, err := query.FindMember(ctx, gen.FindMemberParams{
+			TeamID:    a.TeamID,
+			SlackUid:  a.SlackUID,
+			MonthYear: a.Date.String(),
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("CreateAdjustment FindMember: %w", err)
+		}
+		if genMember.ReceivedLikes+likes < 0 || genMember.ReceivedDislikes+dislikes < 0 {
+			return fmt.Errorf("adjustment would make the %s of %s negative %w", a.Metric, a.SlackUID, statsd.ErrInvalid)
+		}
+	}
+
+	a.CreatedAt = tx.now
+	genAdjustment, err := query.CreateAdjustment(ctx, gen.CreateAdjustmentParams{
+		TeamID:    a.TeamID,
+		MonthYear: a.Date.String(),
+		SlackUid:  a.SlackUID,
+		Metric:    a.Metric,
+		Delta:     int64(a.Delta),
+		Reason:    a.Reason,
+		Actor:     a.Actor,
+		CreatedAt: a.CreatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("CreateAdjustment: %w", err)
+	}
+
+	err = query.IncrementMemberReactions(ctx, gen.IncrementMemberReactionsParams{
+		TeamID:           a.TeamID,
+		MonthYear:        a.Date.String(),
+		SlackUid:         a.SlackUID,
+		ReceivedLikes:    likes,
+		ReceivedDislikes: dislikes,
+		CreatedAt:        tx.now.Format(time.RFC3339),
+		UpdatedAt:        tx.now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("CreateAdjustment IncrementMemberReactions: %w", err)
+	}
+
+	a.ID = int(genAdjustment.ID)
+	return tx.Commit()
+}
+
+// FindAdjustments retrieves the Adjustments of a workspace matching the filter, oldest first.
+func (as *AdjustmentService) FindAdjustments(ctx context.Context, filter statsd.AdjustmentFilter) ([]*statsd.Adjustment, error) {
+	ctx, span := tracer.Start(ctx, "AdjustmentService.FindAdjustments")
+	defer span.End()
+
+	genAdjustments, err := as.db.query.ListAdjustments(ctx, gen.ListAdjustmentsParams{
+		TeamID:    filter.TeamID,
+		MonthYear: filter.Date.String(),
+		SlackUid:  filter.SlackUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FindAdjustments: %w", err)
+	}
+
+	adjustments := make([]*statsd.Adjustment, 0, len(genAdjustments))
+	for i := range genAdjustments {
+		a, err := genAdjustmentToAdjustment(&genAdjustments[i])
+		if err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, a)
+	}
+	return adjustments, nil
+}
+
+// RevertAdjustment marks an Adjustment as reverted by actor and removes its delta from the Member's counts.
+// Returns ErrNotFound if the adjustment does not exist and ErrConflict if it has already been reverted.
+func (as *AdjustmentService) RevertAdjustment(ctx context.Context, id int, actor string) (*statsd.Adjustment, error) {
+	ctx, span := tracer.Start(ctx, "AdjustmentService.RevertAdjustment")
+	defer span.End()
+
+	if actor == "" {
+		return nil, fmt.Errorf("actor required %w", statsd.ErrInvalid)
+	}
+
+	tx, err := as.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	query := newQueries(tx.Tx)
+
+	genAdjustment, err := query.RevertAdjustment(ctx, gen.RevertAdjustmentParams{
+		RevertedBy: actor,
+		RevertedAt: tx.now.Format(time.RFC3339),
+		ID:         int64(id),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Distinguish unknown adjustments from those which have already been reverted.
+		if _, err := query.FindAdjustmentByID(ctx, int64(id)); errors.Is(err, sql.ErrNoRows) {
+			return nil, statsd.ErrNotFound
+		} else if err != nil {
+			return nil, fmt.Errorf("RevertAdjustment FindAdjustmentByID: %w", err)
+		}
+		return nil, statsd.ErrConflict
+	} else if err != nil {
+		return nil, fmt.Errorf("RevertAdjustment: %w", err)
+	}
+
+	// Counts are floored at zero in case reactions were removed after the adjustment was made.
+	likes, dislikes := adjustmentCounts(genAdjustment.Metric, int(genAdjustment.Delta))
+	err = query.DecrementMemberReactions(ctx, gen.DecrementMemberReactionsParams{
+		ReceivedLikes:    likes,
+		ReceivedDislikes: dislikes,
+		UpdatedAt:        tx.now.Format(time.RFC3339),
+		TeamID:           genAdjustment.TeamID,
+		SlackUid:         genAdjustment.SlackUid,
+		MonthYear:        genAdjustment.MonthYear,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RevertAdjustment DecrementMemberReactions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return genAdjustmentToAdjustment(&genAdjustment)
+}
+
+// adjustmentCounts returns the change of likes and dislikes of an adjustment.
+func adjustmentCounts(metric string, delta int) (likes int64, dislikes int64) {
+	switch metric {
+	case statsd.MetricLikes:
+		return int64(delta), 0
+	case statsd.MetricDislikes:
+		return 0, int64(delta)
+	}
+	return 0, 0
+}
+
+// genAdjustmentToAdjustment converts the sqlite adjustment type to the statsd adjustment type.
+func genAdjustmentToAdjustment(a *gen.Adjustment) (*statsd.Adjustment, error) {
+	date, err := statsd.NewMonthYearString(a.MonthYear)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := time.Parse(time.RFC3339, a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	adjustment := &statsd.Adjustment{ID: int(a.ID), TeamID: a.TeamID, Date: date, SlackUID: a.SlackUid, Metric: a.Metric, Delta: int(a.Delta), Reason: a.Reason, Actor: a.Actor, CreatedAt: createdAt, RevertedBy: a.RevertedBy}
+	if a.RevertedAt != "" {
+		revertedAt, err := time.Parse(time.RFC3339, a.RevertedAt)
+		if err != nil {
+			return nil, err
+		}
+		adjustment.RevertedAt = &revertedAt
+	}
+	return adjustment, nil
+}
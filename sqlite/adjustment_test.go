@@ -0,0 +1,141 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestAdjustmentService_CreateAdjustment(t *testing.T) {
+	// Ensure an adjustment is recorded and applied to the member's counts.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		as := sqlite.NewAdjustmentService(db)
+		ms := sqlite.NewMemberService(db)
+
+		a := &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", Metric: statsd.MetricLikes, Delta: 5, Reason: "missed while offline", Actor: "cli:alice"}
+		if err := as.CreateAdjustment(context.Background(), a); err != nil {
+			t.Fatal(err)
+		} else if got, want := a.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if a.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		if m, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := m.ReceivedLikes, 5; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+
+		if adjustments, err := as.FindAdjustments(context.Background(), statsd.AdjustmentFilter{TeamID: "T1ZN1SE2N"}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(adjustments), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := adjustments[0].Reason, "missed while offline"; got != want {
+			t.Fatalf("Reason=%v, want %v", got, want)
+		} else if adjustments[0].RevertedAt != nil {
+			t.Fatalf("unexpected RevertedAt: %v", adjustments[0].RevertedAt)
+		}
+	})
+
+	// Ensure an adjustment cannot take away more than the member has received.
+	t.Run("ErrNegative", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsDown})
+
+		a := &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", Metric: statsd.MetricDislikes, Delta: -2, Reason: "spam", Actor: "cli:alice"}
+		if err := sqlite.NewAdjustmentService(db).CreateAdjustment(context.Background(), a); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure an adjustment requires a reason.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		a := &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", Metric: statsd.MetricLikes, Delta: 1, Actor: "cli:alice"}
+		if err := sqlite.NewAdjustmentService(db).CreateAdjustment(context.Background(), a); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestAdjustmentService_RevertAdjustment(t *testing.T) {
+	// Ensure a reverted adjustment no longer counts and cannot be reverted twice.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		as := sqlite.NewAdjustmentService(db)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp})
+		a := MustCreateAdjustment(t, db, &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", Metric: statsd.MetricLikes, Delta: -1, Reason: "self-promotion", Actor: "cli:alice"})
+
+		if reverted, err := as.RevertAdjustment(context.Background(), a.ID, "cli:bob"); err != nil {
+			t.Fatal(err)
+		} else if got, want := reverted.RevertedBy, "cli:bob"; got != want {
+			t.Fatalf("RevertedBy=%v, want %v", got, want)
+		} else if reverted.RevertedAt == nil {
+			t.Fatal("expected reverted at")
+		}
+
+		if m, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := m.ReceivedLikes, 1; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+
+		if _, err := as.RevertAdjustment(context.Background(), a.ID, "cli:bob"); !errors.Is(err, statsd.ErrConflict) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure an error is returned if the adjustment does not exist.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		if _, err := sqlite.NewAdjustmentService(db).RevertAdjustment(context.Background(), 1, "cli:bob"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// Ensure recomputing a month keeps the adjustments which haven't been reverted.
+func TestMemberService_RecomputeMembers_Adjustments(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	ms := sqlite.NewMemberService(db)
+
+	MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp})
+	MustCreateAdjustment(t, db, &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", Metric: statsd.MetricLikes, Delta: 4, Reason: "missed while offline", Actor: "cli:alice"})
+	reverted := MustCreateAdjustment(t, db, &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", Metric: statsd.MetricDislikes, Delta: 2, Reason: "typo", Actor: "cli:alice"})
+	if _, err := sqlite.NewAdjustmentService(db).RevertAdjustment(context.Background(), reverted.ID, "cli:alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if diffs, err := ms.RecomputeMembers(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006"), false); err != nil {
+		t.Fatal(err)
+	} else if len(diffs) != 0 {
+		t.Fatalf("unexpected diffs: %#v", diffs)
+	}
+	if m, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+		t.Fatal(err)
+	} else if got, want := m.ReceivedLikes, 5; got != want {
+		t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+	} else if got, want := m.ReceivedDislikes, 0; got != want {
+		t.Fatalf("ReceivedDislikes=%v, want %v", got, want)
+	}
+}
+
+// MustCreateAdjustment records an adjustment in the database. Fatal on error.
+func MustCreateAdjustment(tb testing.TB, db *sqlite.DB, a *statsd.Adjustment) *statsd.Adjustment {
+	tb.Helper()
+	if err := sqlite.NewAdjustmentService(db).CreateAdjustment(context.Background(), a); err != nil {
+		tb.Fatal(err)
+	}
+	return a
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.AuditService = (*AuditService)(nil)
+
+// AuditService represents a service for recording administrative actions.
+type AuditService struct {
+	db *DB
+}
+
+// NewAuditService returns a new instance of AuditService.
+func NewAuditService(db *DB) *AuditService {
+	return &AuditService{
+		db: db,
+	}
+}
+
+// CreateAuditEntry records an administrative action.
+func (as *AuditService) CreateAuditEntry(ctx context.Context, e *statsd.AuditEntry) error {
+	ctx, span := tracer.Start(ctx, "AuditService.CreateAuditEntry")
+	defer span.End()
+
+	if e == nil {
+		return fmt.Errorf("CreateAuditEntry: e reference is nil")
+	}
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	e.CreatedAt = as.db.now().UTC().Truncate(time.Second)
+	genEntry, err := as.db.query.CreateAuditEntry(ctx, gen.CreateAuditEntryParams{
+		TeamID:      e.TeamID,
+		Actor:       e.Actor,
+		Action:      e.Action,
+		Target:      e.Target,
+		BeforeValue: string(e.Before),
+		AfterValue:  string(e.After),
+		CreatedAt:   e.CreatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("CreateAuditEntry: %w", err)
+	}
+	e.ID = int(genEntry.ID)
+	return nil
+}
+
+// FindAuditEntries retrieves the entries matching the filter, the most recent first.
+func (as *AuditService) FindAuditEntries(ctx context.Context, filter statsd.AuditFilter) ([]*statsd.AuditEntry, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.FindAuditEntries")
+	defer span.End()
+
+	// Timestamps are stored as RFC 3339 in UTC, so they can be compared as strings.
+	var from string
+	if !filter.From.IsZero() {
+		from = filter.From.UTC().Format(time.RFC3339)
+	}
+	to := "9999-12-31T23:59:59Z"
+	if !filter.To.IsZero() {
+		to = filter.To.UTC().Format(time.RFC3339)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = statsd.DefaultAuditLimit
+	}
+
+	genEntries, err := as.db.query.ListAuditEntries(ctx, gen.ListAuditEntriesParams{
+		FromTime: from,
+		ToTime:   to,
+		TeamID:   filter.TeamID,
+		Actor:    filter.Actor,
+		Action:   filter.Action,
+		Limit:    int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FindAuditEntries: %w", err)
+	}
+
+	entries := make([]*statsd.AuditEntry, 0, len(genEntries))
+	for i := range genEntries {
+		e, err := genAuditLogToAuditEntry(&genEntries[i])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// genAuditLogToAuditEntry converts the sqlite audit log type to the statsd audit entry type.
+func genAuditLogToAuditEntry(e *gen.AuditLog) (*statsd.AuditEntry, error) {
+	createdAt, err := time.Parse(time.RFC3339, e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	entry := &statsd.AuditEntry{ID: int(e.ID), TeamID: e.TeamID, Actor: e.Actor, Action: e.Action, Target: e.Target, CreatedAt: createdAt}
+	if e.BeforeValue != "" {
+		entry.Before = json.RawMessage(e.BeforeValue)
+	}
+	if e.AfterValue != "" {
+		entry.After = json.RawMessage(e.AfterValue)
+	}
+	return entry, nil
+}
@@ -0,0 +1,108 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestAuditService_CreateAuditEntry(t *testing.T) {
+	// Ensure an entry can be recorded along with its values.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		as := sqlite.NewAuditService(db)
+
+		e, err := statsd.NewAuditEntry("T1ZN1SE2N", "cli:alice", statsd.AuditActionRecompute, "05-2006", nil, map[string]int{"U1ZN1SE2N": 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := as.CreateAuditEntry(context.Background(), e); err != nil {
+			t.Fatal(err)
+		} else if got, want := e.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if e.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := string(entries[0].After), `{"U1ZN1SE2N":3}`; got != want {
+			t.Fatalf("After=%v, want %v", got, want)
+		} else if entries[0].Before != nil {
+			t.Fatalf("unexpected Before: %s", entries[0].Before)
+		}
+	})
+
+	// Ensure an entry requires an actor and an action.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		if err := sqlite.NewAuditService(db).CreateAuditEntry(context.Background(), &statsd.AuditEntry{Action: statsd.AuditActionRecompute}); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestAuditService_FindAuditEntries(t *testing.T) {
+	// Ensure entries can be filtered by workspace, actor, action, time range and limit.
+	t.Run("Filter", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		as := sqlite.NewAuditService(db)
+
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "cli:alice", Action: statsd.AuditActionRecompute})
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "slack:U1ZN1SE2N", Action: statsd.AuditActionWorkspaceInstall})
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T2ZN1SE2N", Actor: "cli:alice", Action: statsd.AuditActionImport})
+
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{Actor: "cli:alice"}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 2; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := entries[0].Action, statsd.AuditActionImport; got != want {
+			t.Fatalf("Action=%v, want %v", got, want)
+		}
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{TeamID: "T1ZN1SE2N"}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 2; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{Action: statsd.AuditActionImport}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := entries[0].TeamID, "T2ZN1SE2N"; got != want {
+			t.Fatalf("TeamID=%v, want %v", got, want)
+		}
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{From: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 0; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{To: time.Now().Add(-time.Hour)}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 0; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{Limit: 1}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+	})
+}
+
+// MustCreateAuditEntry records an audit entry in the database. Fatal on error.
+func MustCreateAuditEntry(tb testing.TB, db *sqlite.DB, e *statsd.AuditEntry) *statsd.AuditEntry {
+	tb.Helper()
+	if err := sqlite.NewAuditService(db).CreateAuditEntry(context.Background(), e); err != nil {
+		tb.Fatal(err)
+	}
+	return e
+}
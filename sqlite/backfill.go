@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.BackfillService = (*BackfillService)(nil)
+
+// BackfillService represents a service for managing BackfillCheckpoints.
+type BackfillService struct {
+	db *DB
+}
+
+// NewBackfillService returns a new instance of BackfillService.
+func NewBackfillService(db *DB) *BackfillService {
+	return &BackfillService{
+		db: db,
+	}
+}
+
+// FindBackfillCheckpoint retrieves the checkpoint of a channel for the given range of months.
+// Returns ErrNotFound if the backfill has not been started.
+func (bs *BackfillService) FindBackfillCheckpoint(ctx context.Context, teamID string, channelID string, from statsd.MonthYear, to statsd.MonthYear) (*statsd.BackfillCheckpoint, error) {
+	ctx, span := tracer.Start(ctx, "BackfillService.FindBackfillCheckpoint")
+	defer span.End()
+
+	genCheckpoint, err := bs.db.query.FindBackfillCheckpoint(ctx, gen.FindBackfillCheckpointParams{
+		TeamID:        teamID,
+		ChannelID:     channelID,
+		FromMonthYear: from.String(),
+		ToMonthYear:   to.String(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, statsd.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return genCheckpointToCheckpoint(&genCheckpoint)
+}
+
+// SaveBackfillCheckpoint creates or replaces the checkpoint of a channel.
+func (bs *BackfillService) SaveBackfillCheckpoint(ctx context.Context, c *statsd.BackfillCheckpoint) error {
+	ctx, span := tracer.Start(ctx, "BackfillService.SaveBackfillCheckpoint")
+	defer span.End()
+
+	if c == nil {
+		return fmt.Errorf("SaveBackfillCheckpoint: c reference is nil")
+	}
+	if c.TeamID == "" {
+		return fmt.Errorf("slack team ID required %w", statsd.ErrInvalid)
+	}
+	if c.ChannelID == "" {
+		return fmt.Errorf("channel ID required %w", statsd.ErrInvalid)
+	}
+
+	c.UpdatedAt = bs.db.now().UTC().Truncate(time.Second)
+	var done int64
+	if c.Done {
+		done = 1
+	}
+	err := bs.db.query.SaveBackfillCheckpoint(ctx, gen.SaveBackfillCheckpointParams{
+		TeamID:        c.TeamID,
+		ChannelID:     c.ChannelID,
+		FromMonthYear: c.From.String(),
+		ToMonthYear:   c.To.String(),
+		Cursor:        c.Cursor,
+		Done:          done,
+		UpdatedAt:     c.UpdatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("SaveBackfillCheckpoint: %w", err)
+	}
+	return nil
+}
+
+// genCheckpointToCheckpoint converts the sqlite checkpoint type to the statsd checkpoint type.
+func genCheckpointToCheckpoint(c *gen.BackfillCheckpoint) (*statsd.BackfillCheckpoint, error) {
+	from, err := statsd.NewMonthYearString(c.FromMonthYear)
+	if err != nil {
+		return nil, err
+	}
+	to, err := statsd.NewMonthYearString(c.ToMonthYear)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := time.Parse(time.RFC3339, c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &statsd.BackfillCheckpoint{TeamID: c.TeamID, ChannelID: c.ChannelID, From: from, To: to, Cursor: c.Cursor, Done: c.Done != 0, UpdatedAt: updatedAt}, nil
+}
@@ -0,0 +1,59 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestBackfillService_SaveBackfillCheckpoint(t *testing.T) {
+	// Ensure a checkpoint can be created and then advanced.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		bs := sqlite.NewBackfillService(db)
+
+		c := &statsd.BackfillCheckpoint{
+			TeamID:    "T1ZN1SE2N",
+			ChannelID: "C1ZN1SE2N",
+			From:      statsd.MonthYear("01-2023"),
+			To:        statsd.MonthYear("09-2024"),
+			Cursor:    "bmV4dF90czoxNTEyMDg1ODYxMDAwNTQz",
+		}
+		if err := bs.SaveBackfillCheckpoint(context.Background(), c); err != nil {
+			t.Fatal(err)
+		} else if c.UpdatedAt.IsZero() {
+			t.Fatal("expected updated at")
+		}
+
+		c.Cursor = ""
+		c.Done = true
+		if err := bs.SaveBackfillCheckpoint(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+
+		if other, err := bs.FindBackfillCheckpoint(context.Background(), "T1ZN1SE2N", "C1ZN1SE2N", statsd.MonthYear("01-2023"), statsd.MonthYear("09-2024")); err != nil {
+			t.Fatal(err)
+		} else if !other.Done {
+			t.Fatal("expected done")
+		} else if got, want := other.Cursor, ""; got != want {
+			t.Fatalf("Cursor=%v, want %v", got, want)
+		}
+	})
+	// Ensure checkpoints of different ranges are kept apart.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		bs := sqlite.NewBackfillService(db)
+
+		if err := bs.SaveBackfillCheckpoint(context.Background(), &statsd.BackfillCheckpoint{TeamID: "T1ZN1SE2N", ChannelID: "C1ZN1SE2N", From: statsd.MonthYear("01-2023"), To: statsd.MonthYear("09-2024")}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bs.FindBackfillCheckpoint(context.Background(), "T1ZN1SE2N", "C1ZN1SE2N", statsd.MonthYear("01-2023"), statsd.MonthYear("12-2023")); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Secrets are protected with envelope encryption: every secret is encrypted with its own
+// random data key, which in turn is wrapped by one of the key encryption keys of the
+// database. Rotating a key encryption key therefore only requires rewrapping the data keys.
+//
+// Sealed secrets have the format `v1:<key ID>:<wrapped data key>:<ciphertext>`, where the
+// key ID identifies the key encryption key and the remaining parts are base64 encoded
+// AES-256-GCM nonces followed by their ciphertexts.
+const envelopeVersion = "v1"
+
+// ErrDecrypt is returned if a secret can't be decrypted with any of the encryption keys.
+var ErrDecrypt = errors.New("unable to decrypt secret with the configured encryption keys")
+
+// encrypt seals a secret with a new data key wrapped by the primary encryption key.
+func (db *DB) encrypt(plaintext string) (string, error) {
+	kek, err := db.primaryKey()
+	if err != nil {
+		return "", err
+	}
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dek, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrappedDEK, err := seal(kek, dek)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{envelopeVersion, keyID(kek), encode(wrappedDEK), encode(ciphertext)}, ":"), nil
+}
+
+// decrypt opens a secret which was sealed by encrypt.
+func (db *DB) decrypt(secret string) (string, error) {
+	if !strings.HasPrefix(secret, envelopeVersion+":") {
+		return db.decryptLegacy(secret)
+	}
+	dek, ciphertext, err := db.unwrap(secret)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dek, ciphertext)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+// rewrap wraps the data key of a sealed secret with the primary encryption key. The
+// ciphertext of the secret itself is left untouched. Secrets which were sealed before
+// envelope encryption was introduced are sealed anew.
+func (db *DB) rewrap(secret string) (string, error) {
+	kek, err := db.primaryKey()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(secret, envelopeVersion+":") {
+		plaintext, err := db.decryptLegacy(secret)
+		if err != nil {
+			return "", err
+		}
+		return db.encrypt(plaintext)
+	}
+	dek, ciphertext, err := db.unwrap(secret)
+	if err != nil {
+		return "", err
+	}
+	wrappedDEK, err := seal(kek, dek)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{envelopeVersion, keyID(kek), encode(wrappedDEK), encode(ciphertext)}, ":"), nil
+}
+
+// unwrap returns the data key and ciphertext of a sealed secret.
+func (db *DB) unwrap(secret string) (dek []byte, ciphertext []byte, err error) {
+	parts := strings.Split(secret, ":")
+	if len(parts) != 4 || parts[0] != envelopeVersion {
+		return nil, nil, errors.New("unable to parse sealed secret")
+	}
+	wrappedDEK, err := decode(parts[2])
+	if err != nil {
+		return nil, nil, err
+	}
+	if ciphertext, err = decode(parts[3]); err != nil {
+		return nil, nil, err
+	}
+	for _, kek := range db.EncryptionKeys {
+		if keyID(kek) != parts[1] {
+			continue
+		}
+		if dek, err = open(kek, wrappedDEK); err != nil {
+			return nil, nil, ErrDecrypt
+		}
+		return dek, ciphertext, nil
+	}
+	return nil, nil, ErrDecrypt
+}
+
+// decryptLegacy opens a secret which was sealed directly with an encryption key and
+// stored as the base64 encoded nonce followed by the ciphertext.
+func (db *DB) decryptLegacy(secret string) (string, error) {
+	buf, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	for _, key := range db.EncryptionKeys {
+		if len(key) != 32 {
+			continue
+		}
+		if plaintext, err := open(key, buf); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", ErrDecrypt
+}
+
+// RotateEncryptionKeys rewraps the data keys of all stored secrets with the primary (first)
+// encryption key. Afterwards, the previous encryption keys are no longer needed.
+// Returns the number of rewrapped secrets.
+func (db *DB) RotateEncryptionKeys(ctx context.Context) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	query := newQueries(tx.Tx)
+
+	genWorkspaces, err := query.ListWorkspaces(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("RotateEncryptionKeys ListWorkspaces: %w", err)
+	}
+	for _, w := range genWorkspaces {
+		botToken, err := db.rewrap(w.BotToken)
+		if err != nil {
+			return 0, fmt.Errorf("RotateEncryptionKeys %s: %w", w.TeamID, err)
+		}
+		err = query.UpdateWorkspaceBotToken(ctx, gen.UpdateWorkspaceBotTokenParams{
+			BotToken:  botToken,
+			UpdatedAt: tx.now.Format(time.RFC3339),
+			TeamID:    w.TeamID,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("RotateEncryptionKeys UpdateWorkspaceBotToken: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(genWorkspaces), nil
+}
+
+// primaryKey returns the key encryption key used to wrap new data keys.
+func (db *DB) primaryKey() ([]byte, error) {
+	if len(db.EncryptionKeys) == 0 {
+		return nil, errors.New("an encryption key is required to store secrets")
+	}
+	for _, key := range db.EncryptionKeys {
+		if len(key) != 32 {
+			return nil, errors.New("encryption keys must be 32 bytes long")
+		}
+	}
+	return db.EncryptionKeys[0], nil
+}
+
+// keyID returns a short identifier of a key encryption key which doesn't reveal the key.
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// pseudonym returns a salted hash of a Slack user ID which identifies the user within the audit
+// log once they have been forgotten. The primary encryption key serves as the salt, so the ID can't
+// be recovered by hashing every possible ID.
+func pseudonym(key []byte, teamID string, slackUID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(teamID + ":" + slackUID))
+	return "forgotten:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// seal encrypts plaintext with AES-256-GCM and returns the nonce followed by the ciphertext.
+func seal(key []byte, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts a nonce and ciphertext which were produced by seal.
+func open(key []byte, buf []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, buf[:aead.NonceSize()], buf[aead.NonceSize():], nil)
+}
+
+// newAEAD returns the AES-256-GCM cipher for key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encode returns buf as unpadded base64, which is used for every part of a sealed secret.
+func encode(buf []byte) string {
+	return base64.RawStdEncoding.EncodeToString(buf)
+}
+
+// decode returns the bytes of a part of a sealed secret which was produced by encode.
+func decode(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(s)
+}
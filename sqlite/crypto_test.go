@@ -0,0 +1,108 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+var (
+	keyA = []byte("0123456789abcdef0123456789abcdef")
+	keyB = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestDB_Encryption(t *testing.T) {
+	// Ensure secrets are stored as ciphertext.
+	t.Run("CiphertextStored", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "db")
+		db := MustOpenDBWithKeys(t, dsn, keyA)
+		defer MustCloseDB(t, db)
+
+		if err := sqlite.NewWorkspaceService(db).SaveWorkspace(context.Background(), &statsd.Workspace{TeamID: "T1ZN1SE2N", BotToken: "xoxb-secret"}); err != nil {
+			t.Fatal(err)
+		}
+		if raw := MustQueryBotToken(t, dsn, "T1ZN1SE2N"); strings.Contains(raw, "xoxb-secret") {
+			t.Fatalf("plaintext stored: %s", raw)
+		} else if !strings.HasPrefix(raw, "v1:") {
+			t.Fatalf("unexpected format: %s", raw)
+		}
+	})
+
+	// Ensure secrets can't be decrypted with the wrong key.
+	t.Run("ErrWrongKey", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "db")
+		db := MustOpenDBWithKeys(t, dsn, keyA)
+		if err := sqlite.NewWorkspaceService(db).SaveWorkspace(context.Background(), &statsd.Workspace{TeamID: "T1ZN1SE2N", BotToken: "xoxb-secret"}); err != nil {
+			t.Fatal(err)
+		}
+		MustCloseDB(t, db)
+
+		db = MustOpenDBWithKeys(t, dsn, keyB)
+		defer MustCloseDB(t, db)
+		if _, err := sqlite.NewWorkspaceService(db).FindWorkspace(context.Background(), "T1ZN1SE2N"); !errors.Is(err, sqlite.ErrDecrypt) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure secrets are readable with only the new key after a rotation.
+	t.Run("RotateEncryptionKeys", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "db")
+		db := MustOpenDBWithKeys(t, dsn, keyA)
+		if err := sqlite.NewWorkspaceService(db).SaveWorkspace(context.Background(), &statsd.Workspace{TeamID: "T1ZN1SE2N", BotToken: "xoxb-secret"}); err != nil {
+			t.Fatal(err)
+		}
+		MustCloseDB(t, db)
+		before := MustQueryBotToken(t, dsn, "T1ZN1SE2N")
+
+		db = MustOpenDBWithKeys(t, dsn, keyB, keyA)
+		if n, err := db.RotateEncryptionKeys(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+		MustCloseDB(t, db)
+		if after := MustQueryBotToken(t, dsn, "T1ZN1SE2N"); after == before {
+			t.Fatal("expected secret to be rewrapped")
+		}
+
+		db = MustOpenDBWithKeys(t, dsn, keyB)
+		defer MustCloseDB(t, db)
+		if w, err := sqlite.NewWorkspaceService(db).FindWorkspace(context.Background(), "T1ZN1SE2N"); err != nil {
+			t.Fatal(err)
+		} else if got, want := w.BotToken, "xoxb-secret"; got != want {
+			t.Fatalf("BotToken=%v, want %v", got, want)
+		}
+	})
+}
+
+// MustOpenDBWithKeys returns a new, open DB at dsn using the given encryption keys. Fatal on error.
+func MustOpenDBWithKeys(tb testing.TB, dsn string, keys ...[]byte) *sqlite.DB {
+	tb.Helper()
+	db := sqlite.NewDB(dsn)
+	db.EncryptionKeys = keys
+	if err := db.Open(); err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+// MustQueryBotToken returns the bot token of a workspace as stored in the database file at dsn. Fatal on error.
+func MustQueryBotToken(tb testing.TB, dsn string, teamID string) string {
+	tb.Helper()
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer db.Close()
+	var botToken string
+	if err := db.QueryRow(`SELECT bot_token FROM workspaces WHERE team_id = ?`, teamID).Scan(&botToken); err != nil {
+		tb.Fatal(err)
+	}
+	return botToken
+}
@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetNow replaces the clock of db so tests can control the current time.
+func (db *DB) SetNow(now func() time.Time) {
+	db.now = now
+}
+
+// TablesContaining returns the names of the tables with a value which contains s.
+func (db *DB) TablesContaining(ctx context.Context, s string) ([]string, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table'`)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, table := range tables {
+		found, err := db.tableContains(ctx, table, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", table, err)
+		} else if found {
+			matches = append(matches, table)
+		}
+	}
+	return matches, nil
+}
+
+// tableContains reports whether any value of a table contains s.
+func (db *DB) tableContains(ctx context.Context, table string, s string) (bool, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT * FROM "`+table+`"`)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return false, err
+		}
+		for _, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			if strings.Contains(fmt.Sprint(v), s) {
+				return true, nil
+			}
+		}
+	}
+	return false, rows.Err()
+}
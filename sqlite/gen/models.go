@@ -6,8 +6,51 @@ package gen
 
 import ()
 
+type Adjustment struct {
+	ID         int64
+	TeamID     string
+	MonthYear  string
+	SlackUid   string
+	Metric     string
+	Delta      int64
+	Reason     string
+	Actor      string
+	CreatedAt  string
+	RevertedBy string
+	RevertedAt string
+}
+
+type AuditLog struct {
+	ID          int64
+	TeamID      string
+	Actor       string
+	Action      string
+	Target      string
+	BeforeValue string
+	AfterValue  string
+	CreatedAt   string
+}
+
+type BackfillCheckpoint struct {
+	TeamID        string
+	ChannelID     string
+	FromMonthYear string
+	ToMonthYear   string
+	Cursor        string
+	Done          int64
+	UpdatedAt     string
+}
+
+type LikedWeek struct {
+	TeamID   string
+	SlackUid string
+	Week     string
+	Likes    int64
+}
+
 type Member struct {
 	ID               int64
+	TeamID           string
 	MonthYear        string
 	SlackUid         string
 	ReceivedLikes    int64
@@ -15,3 +58,36 @@ type Member struct {
 	CreatedAt        string
 	UpdatedAt        string
 }
+
+type OptOut struct {
+	TeamID      string
+	SlackUid    string
+	CountTotals int64
+	CreatedAt   string
+}
+
+type Reaction struct {
+	ID         int64
+	TeamID     string
+	MonthYear  string
+	ChannelID  string
+	MessageTs  string
+	ReactorUid string
+	AuthorUid  string
+	Name       string
+	ReactedAt  string
+}
+
+type Retention struct {
+	Name         string
+	PrunedBefore string
+}
+
+type Workspace struct {
+	TeamID    string
+	TeamName  string
+	BotUserID string
+	BotToken  string
+	CreatedAt string
+	UpdatedAt string
+}
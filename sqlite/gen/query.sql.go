@@ -9,19 +9,524 @@ import (
 	"context"
 )
 
+const adjustedMonths = `-- name: AdjustedMonths :many
+SELECT month_year
+FROM adjustments
+WHERE team_id = ? AND reverted_at = ''
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(? AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(? AS TEXT)
+GROUP BY month_year
+ORDER BY substr(month_year, 4, 4) || substr(month_year, 1, 2)
+`
+
+type AdjustedMonthsParams struct {
+	TeamID        string
+	FromYearMonth string
+	ToYearMonth   string
+}
+
+func (q *Queries) AdjustedMonths(ctx context.Context, arg AdjustedMonthsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, adjustedMonths, arg.TeamID, arg.FromYearMonth, arg.ToYearMonth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var monthYear string
+		if err := rows.Scan(&monthYear); err != nil {
+			return nil, err
+		}
+		items = append(items, monthYear)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const adoptBackfillCheckpoints = `-- name: AdoptBackfillCheckpoints :exec
+UPDATE backfill_checkpoints
+SET team_id = ?
+WHERE team_id = ''
+`
+
+func (q *Queries) AdoptBackfillCheckpoints(ctx context.Context, teamID string) error {
+	_, err := q.db.ExecContext(ctx, adoptBackfillCheckpoints, teamID)
+	return err
+}
+
+const adoptMembers = `-- name: AdoptMembers :exec
+UPDATE members
+SET team_id = ?
+WHERE team_id = ''
+`
+
+func (q *Queries) AdoptMembers(ctx context.Context, teamID string) error {
+	_, err := q.db.ExecContext(ctx, adoptMembers, teamID)
+	return err
+}
+
+const adoptReactions = `-- name: AdoptReactions :exec
+UPDATE reactions
+SET team_id = ?
+WHERE team_id = ''
+`
+
+func (q *Queries) AdoptReactions(ctx context.Context, teamID string) error {
+	_, err := q.db.ExecContext(ctx, adoptReactions, teamID)
+	return err
+}
+
+const controversialMembersBetween = `-- name: ControversialMembersBetween :many
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND (CAST(? AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+HAVING received_likes > 0 AND received_dislikes > 0
+ORDER BY slack_uid ASC
+`
+
+type ControversialMembersBetweenParams struct {
+	TeamID      string
+	Start       string
+	End         string
+	AllChannels int64
+	ChannelIds  string
+}
+
+type ControversialMembersBetweenRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) ControversialMembersBetween(ctx context.Context, arg ControversialMembersBetweenParams) ([]ControversialMembersBetweenRow, error) {
+	rows, err := q.db.QueryContext(ctx, controversialMembersBetween,
+		arg.TeamID,
+		arg.Start,
+		arg.End,
+		arg.AllChannels,
+		arg.ChannelIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ControversialMembersBetweenRow
+	for rows.Next() {
+		var i ControversialMembersBetweenRow
+		if err := rows.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const controversialMembersInMonths = `-- name: ControversialMembersInMonths :many
+SELECT slack_uid,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = ?
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(? AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(? AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+HAVING received_likes > 0 AND received_dislikes > 0
+ORDER BY slack_uid ASC
+`
+
+type ControversialMembersInMonthsParams struct {
+	TeamID        string
+	FromYearMonth string
+	ToYearMonth   string
+}
+
+type ControversialMembersInMonthsRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) ControversialMembersInMonths(ctx context.Context, arg ControversialMembersInMonthsParams) ([]ControversialMembersInMonthsRow, error) {
+	rows, err := q.db.QueryContext(ctx, controversialMembersInMonths, arg.TeamID, arg.FromYearMonth, arg.ToYearMonth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ControversialMembersInMonthsRow
+	for rows.Next() {
+		var i ControversialMembersInMonthsRow
+		if err := rows.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const controversialMessages = `-- name: ControversialMessages :many
+SELECT channel_id, message_ts, author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND (CAST(? AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY channel_id, message_ts, author_uid
+HAVING received_likes > 0 AND received_dislikes > 0
+ORDER BY message_ts ASC, channel_id ASC
+`
+
+type ControversialMessagesParams struct {
+	TeamID      string
+	Start       string
+	End         string
+	AllChannels int64
+	ChannelIds  string
+}
+
+type ControversialMessagesRow struct {
+	ChannelID        string
+	MessageTs        string
+	AuthorUid        string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) ControversialMessages(ctx context.Context, arg ControversialMessagesParams) ([]ControversialMessagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, controversialMessages,
+		arg.TeamID,
+		arg.Start,
+		arg.End,
+		arg.AllChannels,
+		arg.ChannelIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ControversialMessagesRow
+	for rows.Next() {
+		var i ControversialMessagesRow
+		if err := rows.Scan(
+			&i.ChannelID,
+			&i.MessageTs,
+			&i.AuthorUid,
+			&i.ReceivedLikes,
+			&i.ReceivedDislikes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countGivenReactions = `-- name: CountGivenReactions :many
+SELECT month_year, author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions
+WHERE team_id = ? AND reactor_uid = ? AND author_uid != ?
+GROUP BY month_year, author_uid
+ORDER BY month_year, author_uid
+`
+
+type CountGivenReactionsParams struct {
+	TeamID   string
+	SlackUid string
+}
+
+type CountGivenReactionsRow struct {
+	MonthYear        string
+	AuthorUid        string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) CountGivenReactions(ctx context.Context, arg CountGivenReactionsParams) ([]CountGivenReactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countGivenReactions, arg.TeamID, arg.SlackUid, arg.SlackUid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountGivenReactionsRow
+	for rows.Next() {
+		var i CountGivenReactionsRow
+		if err := rows.Scan(
+			&i.MonthYear,
+			&i.AuthorUid,
+			&i.ReceivedLikes,
+			&i.ReceivedDislikes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countReactions = `-- name: CountReactions :many
+SELECT author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions
+WHERE team_id = ? AND month_year = ?
+GROUP BY author_uid
+ORDER BY author_uid
+`
+
+type CountReactionsParams struct {
+	TeamID    string
+	MonthYear string
+}
+
+type CountReactionsRow struct {
+	AuthorUid        string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) CountReactions(ctx context.Context, arg CountReactionsParams) ([]CountReactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countReactions, arg.TeamID, arg.MonthYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountReactionsRow
+	for rows.Next() {
+		var i CountReactionsRow
+		if err := rows.Scan(&i.AuthorUid, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countReactionsPerQuarterHour = `-- name: CountReactionsPerQuarterHour :many
+SELECT CAST(substr(reacted_at, 1, 14) || printf('%02d', CAST(substr(reacted_at, 15, 2) AS INTEGER) / 15 * 15) AS TEXT) AS quarter_hour,
+CAST(COUNT(*) AS INTEGER) AS reactions
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND (CAST(? AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid AND o.count_totals = 0)
+GROUP BY quarter_hour
+ORDER BY quarter_hour ASC
+`
+
+type CountReactionsPerQuarterHourParams struct {
+	TeamID      string
+	Start       string
+	End         string
+	AllChannels int64
+	ChannelIds  string
+}
+
+type CountReactionsPerQuarterHourRow struct {
+	QuarterHour string
+	Reactions   int64
+}
+
+func (q *Queries) CountReactionsPerQuarterHour(ctx context.Context, arg CountReactionsPerQuarterHourParams) ([]CountReactionsPerQuarterHourRow, error) {
+	rows, err := q.db.QueryContext(ctx, countReactionsPerQuarterHour,
+		arg.TeamID,
+		arg.Start,
+		arg.End,
+		arg.AllChannels,
+		arg.ChannelIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountReactionsPerQuarterHourRow
+	for rows.Next() {
+		var i CountReactionsPerQuarterHourRow
+		if err := rows.Scan(&i.QuarterHour, &i.Reactions); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countWorkspaces = `-- name: CountWorkspaces :one
+SELECT COUNT(*) FROM workspaces
+`
+
+func (q *Queries) CountWorkspaces(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countWorkspaces)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAdjustment = `-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+    team_id,
+    month_year,
+    slack_uid,
+    metric,
+    delta,
+    reason,
+    actor,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING id, team_id, month_year, slack_uid, metric, delta, reason, actor, created_at, reverted_by, reverted_at
+`
+
+type CreateAdjustmentParams struct {
+	TeamID    string
+	MonthYear string
+	SlackUid  string
+	Metric    string
+	Delta     int64
+	Reason    string
+	Actor     string
+	CreatedAt string
+}
+
+func (q *Queries) CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error) {
+	row := q.db.QueryRowContext(ctx, createAdjustment,
+		arg.TeamID,
+		arg.MonthYear,
+		arg.SlackUid,
+		arg.Metric,
+		arg.Delta,
+		arg.Reason,
+		arg.Actor,
+		arg.CreatedAt,
+	)
+	var i Adjustment
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.MonthYear,
+		&i.SlackUid,
+		&i.Metric,
+		&i.Delta,
+		&i.Reason,
+		&i.Actor,
+		&i.CreatedAt,
+		&i.RevertedBy,
+		&i.RevertedAt,
+	)
+	return i, err
+}
+
+const createAuditEntry = `-- name: CreateAuditEntry :one
+INSERT INTO audit_log (
+    team_id,
+    actor,
+    action,
+    target,
+    before_value,
+    after_value,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING id, team_id, actor, action, target, before_value, after_value, created_at
+`
+
+type CreateAuditEntryParams struct {
+	TeamID      string
+	Actor       string
+	Action      string
+	Target      string
+	BeforeValue string
+	AfterValue  string
+	CreatedAt   string
+}
+
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) (AuditLog, error) {
+	row := q.db.QueryRowContext(ctx, createAuditEntry,
+		arg.TeamID,
+		arg.Actor,
+		arg.Action,
+		arg.Target,
+		arg.BeforeValue,
+		arg.AfterValue,
+		arg.CreatedAt,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.Actor,
+		&i.Action,
+		&i.Target,
+		&i.BeforeValue,
+		&i.AfterValue,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createMember = `-- name: CreateMember :one
 INSERT INTO members (
+    team_id,
     month_year,
     slack_uid,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?
+    ?, ?, ?, ?, ?
 )
-RETURNING id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at
+RETURNING id, team_id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at
 `
 
 type CreateMemberParams struct {
+	TeamID    string
 	MonthYear string
 	SlackUid  string
 	CreatedAt string
@@ -30,6 +535,7 @@ type CreateMemberParams struct {
 
 func (q *Queries) CreateMember(ctx context.Context, arg CreateMemberParams) (Member, error) {
 	row := q.db.QueryRowContext(ctx, createMember,
+		arg.TeamID,
 		arg.MonthYear,
 		arg.SlackUid,
 		arg.CreatedAt,
@@ -38,6 +544,7 @@ func (q *Queries) CreateMember(ctx context.Context, arg CreateMemberParams) (Mem
 	var i Member
 	err := row.Scan(
 		&i.ID,
+		&i.TeamID,
 		&i.MonthYear,
 		&i.SlackUid,
 		&i.ReceivedLikes,
@@ -48,6 +555,106 @@ func (q *Queries) CreateMember(ctx context.Context, arg CreateMemberParams) (Mem
 	return i, err
 }
 
+const createReaction = `-- name: CreateReaction :one
+INSERT INTO reactions (
+    team_id,
+    month_year,
+    channel_id,
+    message_ts,
+    reactor_uid,
+    author_uid,
+    name,
+    reacted_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT DO NOTHING
+RETURNING id, team_id, month_year, channel_id, message_ts, reactor_uid, author_uid, name, reacted_at
+`
+
+type CreateReactionParams struct {
+	TeamID     string
+	MonthYear  string
+	ChannelID  string
+	MessageTs  string
+	ReactorUid string
+	AuthorUid  string
+	Name       string
+	ReactedAt  string
+}
+
+func (q *Queries) CreateReaction(ctx context.Context, arg CreateReactionParams) (Reaction, error) {
+	row := q.db.QueryRowContext(ctx, createReaction,
+		arg.TeamID,
+		arg.MonthYear,
+		arg.ChannelID,
+		arg.MessageTs,
+		arg.ReactorUid,
+		arg.AuthorUid,
+		arg.Name,
+		arg.ReactedAt,
+	)
+	var i Reaction
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.MonthYear,
+		&i.ChannelID,
+		&i.MessageTs,
+		&i.ReactorUid,
+		&i.AuthorUid,
+		&i.Name,
+		&i.ReactedAt,
+	)
+	return i, err
+}
+
+const decrementLikedWeek = `-- name: DecrementLikedWeek :exec
+UPDATE liked_weeks
+SET likes = MAX(likes - 1, 0)
+WHERE team_id = ? AND slack_uid = ? AND week = ?
+`
+
+type DecrementLikedWeekParams struct {
+	TeamID   string
+	SlackUid string
+	Week     string
+}
+
+func (q *Queries) DecrementLikedWeek(ctx context.Context, arg DecrementLikedWeekParams) error {
+	_, err := q.db.ExecContext(ctx, decrementLikedWeek, arg.TeamID, arg.SlackUid, arg.Week)
+	return err
+}
+
+const decrementMemberReactions = `-- name: DecrementMemberReactions :exec
+UPDATE members
+SET received_likes = MAX(received_likes - ?, 0),
+received_dislikes = MAX(received_dislikes - ?, 0),
+updated_at = ?
+WHERE team_id = ? AND slack_uid = ? AND month_year = ?
+`
+
+type DecrementMemberReactionsParams struct {
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+	UpdatedAt        string
+	TeamID           string
+	SlackUid         string
+	MonthYear        string
+}
+
+func (q *Queries) DecrementMemberReactions(ctx context.Context, arg DecrementMemberReactionsParams) error {
+	_, err := q.db.ExecContext(ctx, decrementMemberReactions,
+		arg.ReceivedLikes,
+		arg.ReceivedDislikes,
+		arg.UpdatedAt,
+		arg.TeamID,
+		arg.SlackUid,
+		arg.MonthYear,
+	)
+	return err
+}
+
 const deleteMember = `-- name: DeleteMember :exec
 DELETE FROM members
 WHERE id = ?
@@ -58,64 +665,216 @@ func (q *Queries) DeleteMember(ctx context.Context, id int64) error {
 	return err
 }
 
-const findMember = `-- name: FindMember :one
-SELECT id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at FROM members
-WHERE slack_uid = ? AND month_year = ? LIMIT 1
+const deleteMemberAdjustments = `-- name: DeleteMemberAdjustments :execrows
+DELETE FROM adjustments
+WHERE team_id = ? AND slack_uid = ?
 `
 
-type FindMemberParams struct {
-	SlackUid  string
-	MonthYear string
+type DeleteMemberAdjustmentsParams struct {
+	TeamID   string
+	SlackUid string
 }
 
-func (q *Queries) FindMember(ctx context.Context, arg FindMemberParams) (Member, error) {
-	row := q.db.QueryRowContext(ctx, findMember, arg.SlackUid, arg.MonthYear)
-	var i Member
+func (q *Queries) DeleteMemberAdjustments(ctx context.Context, arg DeleteMemberAdjustmentsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMemberAdjustments, arg.TeamID, arg.SlackUid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMemberLikedWeeks = `-- name: DeleteMemberLikedWeeks :execrows
+DELETE FROM liked_weeks
+WHERE team_id = ? AND slack_uid = ?
+`
+
+type DeleteMemberLikedWeeksParams struct {
+	TeamID   string
+	SlackUid string
+}
+
+func (q *Queries) DeleteMemberLikedWeeks(ctx context.Context, arg DeleteMemberLikedWeeksParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMemberLikedWeeks, arg.TeamID, arg.SlackUid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMemberReactions = `-- name: DeleteMemberReactions :execrows
+DELETE FROM reactions
+WHERE team_id = ? AND (author_uid = ? OR reactor_uid = ?)
+`
+
+type DeleteMemberReactionsParams struct {
+	TeamID   string
+	SlackUid string
+}
+
+func (q *Queries) DeleteMemberReactions(ctx context.Context, arg DeleteMemberReactionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMemberReactions, arg.TeamID, arg.SlackUid, arg.SlackUid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMemberRows = `-- name: DeleteMemberRows :execrows
+DELETE FROM members
+WHERE team_id = ? AND slack_uid = ?
+`
+
+type DeleteMemberRowsParams struct {
+	TeamID   string
+	SlackUid string
+}
+
+func (q *Queries) DeleteMemberRows(ctx context.Context, arg DeleteMemberRowsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMemberRows, arg.TeamID, arg.SlackUid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOptOut = `-- name: DeleteOptOut :execrows
+DELETE FROM opt_outs
+WHERE team_id = ? AND slack_uid = ?
+`
+
+type DeleteOptOutParams struct {
+	TeamID   string
+	SlackUid string
+}
+
+func (q *Queries) DeleteOptOut(ctx context.Context, arg DeleteOptOutParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOptOut, arg.TeamID, arg.SlackUid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteReaction = `-- name: DeleteReaction :one
+DELETE FROM reactions
+WHERE team_id = ? AND channel_id = ? AND message_ts = ? AND reactor_uid = ? AND name = ?
+RETURNING id, team_id, month_year, channel_id, message_ts, reactor_uid, author_uid, name, reacted_at
+`
+
+type DeleteReactionParams struct {
+	TeamID     string
+	ChannelID  string
+	MessageTs  string
+	ReactorUid string
+	Name       string
+}
+
+func (q *Queries) DeleteReaction(ctx context.Context, arg DeleteReactionParams) (Reaction, error) {
+	row := q.db.QueryRowContext(ctx, deleteReaction,
+		arg.TeamID,
+		arg.ChannelID,
+		arg.MessageTs,
+		arg.ReactorUid,
+		arg.Name,
+	)
+	var i Reaction
 	err := row.Scan(
 		&i.ID,
+		&i.TeamID,
 		&i.MonthYear,
-		&i.SlackUid,
-		&i.ReceivedLikes,
-		&i.ReceivedDislikes,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.ChannelID,
+		&i.MessageTs,
+		&i.ReactorUid,
+		&i.AuthorUid,
+		&i.Name,
+		&i.ReactedAt,
 	)
 	return i, err
 }
 
-const findMemberByID = `-- name: FindMemberByID :one
-SELECT id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at FROM members
+const deleteWorkspace = `-- name: DeleteWorkspace :exec
+DELETE FROM workspaces
+WHERE team_id = ?
+`
+
+func (q *Queries) DeleteWorkspace(ctx context.Context, teamID string) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspace, teamID)
+	return err
+}
+
+const findAdjustmentByID = `-- name: FindAdjustmentByID :one
+SELECT id, team_id, month_year, slack_uid, metric, delta, reason, actor, created_at, reverted_by, reverted_at FROM adjustments
 WHERE id = ? LIMIT 1
 `
 
-func (q *Queries) FindMemberByID(ctx context.Context, id int64) (Member, error) {
-	row := q.db.QueryRowContext(ctx, findMemberByID, id)
-	var i Member
+func (q *Queries) FindAdjustmentByID(ctx context.Context, id int64) (Adjustment, error) {
+	row := q.db.QueryRowContext(ctx, findAdjustmentByID, id)
+	var i Adjustment
 	err := row.Scan(
 		&i.ID,
+		&i.TeamID,
 		&i.MonthYear,
 		&i.SlackUid,
-		&i.ReceivedLikes,
-		&i.ReceivedDislikes,
+		&i.Metric,
+		&i.Delta,
+		&i.Reason,
+		&i.Actor,
 		&i.CreatedAt,
+		&i.RevertedBy,
+		&i.RevertedAt,
+	)
+	return i, err
+}
+
+const findBackfillCheckpoint = `-- name: FindBackfillCheckpoint :one
+SELECT team_id, channel_id, from_month_year, to_month_year, cursor, done, updated_at FROM backfill_checkpoints
+WHERE team_id = ? AND channel_id = ? AND from_month_year = ? AND to_month_year = ? LIMIT 1
+`
+
+type FindBackfillCheckpointParams struct {
+	TeamID        string
+	ChannelID     string
+	FromMonthYear string
+	ToMonthYear   string
+}
+
+func (q *Queries) FindBackfillCheckpoint(ctx context.Context, arg FindBackfillCheckpointParams) (BackfillCheckpoint, error) {
+	row := q.db.QueryRowContext(ctx, findBackfillCheckpoint,
+		arg.TeamID,
+		arg.ChannelID,
+		arg.FromMonthYear,
+		arg.ToMonthYear,
+	)
+	var i BackfillCheckpoint
+	err := row.Scan(
+		&i.TeamID,
+		&i.ChannelID,
+		&i.FromMonthYear,
+		&i.ToMonthYear,
+		&i.Cursor,
+		&i.Done,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const mostDislikesReceived = `-- name: MostDislikesReceived :one
-SELECT m.id, m.month_year, m.slack_uid, m.received_likes, m.received_dislikes, m.created_at, m.updated_at
-FROM members m
-WHERE month_year = ?
-ORDER BY received_dislikes DESC
-LIMIT 1
+const findMember = `-- name: FindMember :one
+SELECT id, team_id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at FROM members
+WHERE team_id = ? AND slack_uid = ? AND month_year = ? LIMIT 1
 `
 
-func (q *Queries) MostDislikesReceived(ctx context.Context, monthYear string) (Member, error) {
-	row := q.db.QueryRowContext(ctx, mostDislikesReceived, monthYear)
+type FindMemberParams struct {
+	TeamID    string
+	SlackUid  string
+	MonthYear string
+}
+
+func (q *Queries) FindMember(ctx context.Context, arg FindMemberParams) (Member, error) {
+	row := q.db.QueryRowContext(ctx, findMember, arg.TeamID, arg.SlackUid, arg.MonthYear)
 	var i Member
 	err := row.Scan(
 		&i.ID,
+		&i.TeamID,
 		&i.MonthYear,
 		&i.SlackUid,
 		&i.ReceivedLikes,
@@ -126,19 +885,17 @@ func (q *Queries) MostDislikesReceived(ctx context.Context, monthYear string) (M
 	return i, err
 }
 
-const mostLikesReceived = `-- name: MostLikesReceived :one
-SELECT m.id, m.month_year, m.slack_uid, m.received_likes, m.received_dislikes, m.created_at, m.updated_at
-FROM members m
-WHERE month_year = ?
-ORDER BY received_likes DESC
-LIMIT 1
+const findMemberByID = `-- name: FindMemberByID :one
+SELECT id, team_id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at FROM members
+WHERE id = ? LIMIT 1
 `
 
-func (q *Queries) MostLikesReceived(ctx context.Context, monthYear string) (Member, error) {
-	row := q.db.QueryRowContext(ctx, mostLikesReceived, monthYear)
+func (q *Queries) FindMemberByID(ctx context.Context, id int64) (Member, error) {
+	row := q.db.QueryRowContext(ctx, findMemberByID, id)
 	var i Member
 	err := row.Scan(
 		&i.ID,
+		&i.TeamID,
 		&i.MonthYear,
 		&i.SlackUid,
 		&i.ReceivedLikes,
@@ -149,13 +906,1510 @@ func (q *Queries) MostLikesReceived(ctx context.Context, monthYear string) (Memb
 	return i, err
 }
 
-const updateMember = `-- name: UpdateMember :one
+const findPrunedBefore = `-- name: FindPrunedBefore :one
+SELECT pruned_before FROM retention
+WHERE name = ?
+`
+
+func (q *Queries) FindPrunedBefore(ctx context.Context, name string) (string, error) {
+	row := q.db.QueryRowContext(ctx, findPrunedBefore, name)
+	var prunedBefore string
+	err := row.Scan(&prunedBefore)
+	return prunedBefore, err
+}
+
+const findWorkspace = `-- name: FindWorkspace :one
+SELECT team_id, team_name, bot_user_id, bot_token, created_at, updated_at FROM workspaces
+WHERE team_id = ? LIMIT 1
+`
+
+func (q *Queries) FindWorkspace(ctx context.Context, teamID string) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, findWorkspace, teamID)
+	var i Workspace
+	err := row.Scan(
+		&i.TeamID,
+		&i.TeamName,
+		&i.BotUserID,
+		&i.BotToken,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const incrementLikedWeek = `-- name: IncrementLikedWeek :exec
+INSERT INTO liked_weeks (
+    team_id,
+    slack_uid,
+    week,
+    likes
+) VALUES (
+    ?, ?, ?, 1
+)
+ON CONFLICT(team_id, slack_uid, week) DO UPDATE
+SET likes = likes + 1
+`
+
+type IncrementLikedWeekParams struct {
+	TeamID   string
+	SlackUid string
+	Week     string
+}
+
+func (q *Queries) IncrementLikedWeek(ctx context.Context, arg IncrementLikedWeekParams) error {
+	_, err := q.db.ExecContext(ctx, incrementLikedWeek, arg.TeamID, arg.SlackUid, arg.Week)
+	return err
+}
+
+const incrementMemberReactions = `-- name: IncrementMemberReactions :exec
+INSERT INTO members (
+    team_id,
+    month_year,
+    slack_uid,
+    received_likes,
+    received_dislikes,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(team_id, slack_uid, month_year) DO UPDATE
+SET received_likes = received_likes + excluded.received_likes,
+received_dislikes = received_dislikes + excluded.received_dislikes,
+updated_at = excluded.updated_at
+`
+
+type IncrementMemberReactionsParams struct {
+	TeamID           string
+	MonthYear        string
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+	CreatedAt        string
+	UpdatedAt        string
+}
+
+func (q *Queries) IncrementMemberReactions(ctx context.Context, arg IncrementMemberReactionsParams) error {
+	_, err := q.db.ExecContext(ctx, incrementMemberReactions,
+		arg.TeamID,
+		arg.MonthYear,
+		arg.SlackUid,
+		arg.ReceivedLikes,
+		arg.ReceivedDislikes,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const listAdjustments = `-- name: ListAdjustments :many
+SELECT id, team_id, month_year, slack_uid, metric, delta, reason, actor, created_at, reverted_by, reverted_at FROM adjustments
+WHERE team_id = ?
+AND (month_year = ? OR ? = '')
+AND (slack_uid = ? OR ? = '')
+ORDER BY id
+`
+
+type ListAdjustmentsParams struct {
+	TeamID    string
+	MonthYear string
+	SlackUid  string
+}
+
+func (q *Queries) ListAdjustments(ctx context.Context, arg ListAdjustmentsParams) ([]Adjustment, error) {
+	rows, err := q.db.QueryContext(ctx, listAdjustments,
+		arg.TeamID,
+		arg.MonthYear,
+		arg.MonthYear,
+		arg.SlackUid,
+		arg.SlackUid,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Adjustment
+	for rows.Next() {
+		var i Adjustment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.MonthYear,
+			&i.SlackUid,
+			&i.Metric,
+			&i.Delta,
+			&i.Reason,
+			&i.Actor,
+			&i.CreatedAt,
+			&i.RevertedBy,
+			&i.RevertedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEntries = `-- name: ListAuditEntries :many
+SELECT id, team_id, actor, action, target, before_value, after_value, created_at FROM audit_log
+WHERE created_at >= ? AND created_at < ?
+AND (team_id = ? OR ? = '')
+AND (actor = ? OR ? = '')
+AND (action = ? OR ? = '')
+ORDER BY created_at DESC, id DESC
+LIMIT ?
+`
+
+type ListAuditEntriesParams struct {
+	FromTime string
+	ToTime   string
+	TeamID   string
+	Actor    string
+	Action   string
+	Limit    int64
+}
+
+func (q *Queries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEntries,
+		arg.FromTime,
+		arg.ToTime,
+		arg.TeamID,
+		arg.TeamID,
+		arg.Actor,
+		arg.Actor,
+		arg.Action,
+		arg.Action,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.Actor,
+			&i.Action,
+			&i.Target,
+			&i.BeforeValue,
+			&i.AfterValue,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGivenLikes = `-- name: ListGivenLikes :many
+SELECT author_uid, reacted_at
+FROM reactions
+WHERE team_id = ? AND reactor_uid = ? AND author_uid != ? AND name = '+1'
+ORDER BY reacted_at
+`
+
+type ListGivenLikesParams struct {
+	TeamID   string
+	SlackUid string
+}
+
+type ListGivenLikesRow struct {
+	AuthorUid string
+	ReactedAt string
+}
+
+func (q *Queries) ListGivenLikes(ctx context.Context, arg ListGivenLikesParams) ([]ListGivenLikesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGivenLikes, arg.TeamID, arg.SlackUid, arg.SlackUid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGivenLikesRow
+	for rows.Next() {
+		var i ListGivenLikesRow
+		if err := rows.Scan(&i.AuthorUid, &i.ReactedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLikedMembers = `-- name: ListLikedMembers :many
+SELECT month_year, slack_uid
+FROM members m
+WHERE team_id = ? AND received_likes > 0
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+ORDER BY substr(month_year, 4, 4) || substr(month_year, 1, 2) ASC, received_likes DESC, received_dislikes ASC, slack_uid ASC
+`
+
+type ListLikedMembersRow struct {
+	MonthYear string
+	SlackUid  string
+}
+
+func (q *Queries) ListLikedMembers(ctx context.Context, teamID string) ([]ListLikedMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLikedMembers, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLikedMembersRow
+	for rows.Next() {
+		var i ListLikedMembersRow
+		if err := rows.Scan(&i.MonthYear, &i.SlackUid); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLikedWeeks = `-- name: ListLikedWeeks :many
+SELECT slack_uid, week
+FROM liked_weeks w
+WHERE team_id = ? AND likes > 0
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = w.team_id AND o.slack_uid = w.slack_uid)
+ORDER BY week ASC
+`
+
+type ListLikedWeeksRow struct {
+	SlackUid string
+	Week     string
+}
+
+func (q *Queries) ListLikedWeeks(ctx context.Context, teamID string) ([]ListLikedWeeksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLikedWeeks, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLikedWeeksRow
+	for rows.Next() {
+		var i ListLikedWeeksRow
+		if err := rows.Scan(&i.SlackUid, &i.Week); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMemberLikedWeeks = `-- name: ListMemberLikedWeeks :many
+SELECT week
+FROM liked_weeks
+WHERE team_id = ? AND slack_uid = ? AND likes > 0
+ORDER BY week ASC
+`
+
+type ListMemberLikedWeeksParams struct {
+	TeamID   string
+	SlackUid string
+}
+
+func (q *Queries) ListMemberLikedWeeks(ctx context.Context, arg ListMemberLikedWeeksParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listMemberLikedWeeks, arg.TeamID, arg.SlackUid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var week string
+		if err := rows.Scan(&week); err != nil {
+			return nil, err
+		}
+		items = append(items, week)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMemberUIDs = `-- name: ListMemberUIDs :many
+SELECT slack_uid
+FROM members m
+WHERE team_id = ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+ORDER BY slack_uid ASC
+`
+
+func (q *Queries) ListMemberUIDs(ctx context.Context, teamID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listMemberUIDs, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var slackUid string
+		if err := rows.Scan(&slackUid); err != nil {
+			return nil, err
+		}
+		items = append(items, slackUid)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMembers = `-- name: ListMembers :many
+SELECT id, team_id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at FROM members
+WHERE team_id = ? AND month_year = ?
+ORDER BY slack_uid
+`
+
+type ListMembersParams struct {
+	TeamID    string
+	MonthYear string
+}
+
+func (q *Queries) ListMembers(ctx context.Context, arg ListMembersParams) ([]Member, error) {
+	rows, err := q.db.QueryContext(ctx, listMembers, arg.TeamID, arg.MonthYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Member
+	for rows.Next() {
+		var i Member
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.MonthYear,
+			&i.SlackUid,
+			&i.ReceivedLikes,
+			&i.ReceivedDislikes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOptOuts = `-- name: ListOptOuts :many
+SELECT team_id, slack_uid, count_totals, created_at FROM opt_outs
+WHERE team_id = ?
+ORDER BY slack_uid
+`
+
+func (q *Queries) ListOptOuts(ctx context.Context, teamID string) ([]OptOut, error) {
+	rows, err := q.db.QueryContext(ctx, listOptOuts, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OptOut
+	for rows.Next() {
+		var i OptOut
+		if err := rows.Scan(
+			&i.TeamID,
+			&i.SlackUid,
+			&i.CountTotals,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkspaces = `-- name: ListWorkspaces :many
+SELECT team_id, team_name, bot_user_id, bot_token, created_at, updated_at FROM workspaces
+ORDER BY team_id
+`
+
+func (q *Queries) ListWorkspaces(ctx context.Context) ([]Workspace, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkspaces)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workspace
+	for rows.Next() {
+		var i Workspace
+		if err := rows.Scan(
+			&i.TeamID,
+			&i.TeamName,
+			&i.BotUserID,
+			&i.BotToken,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const monthlyTotals = `-- name: MonthlyTotals :many
+SELECT month_year,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = ?
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(? AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(? AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid AND o.count_totals = 0)
+GROUP BY month_year
+`
+
+type MonthlyTotalsParams struct {
+	TeamID        string
+	FromYearMonth string
+	ToYearMonth   string
+}
+
+type MonthlyTotalsRow struct {
+	MonthYear        string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MonthlyTotals(ctx context.Context, arg MonthlyTotalsParams) ([]MonthlyTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, monthlyTotals, arg.TeamID, arg.FromYearMonth, arg.ToYearMonth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MonthlyTotalsRow
+	for rows.Next() {
+		var i MonthlyTotalsRow
+		if err := rows.Scan(&i.MonthYear, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const monthlyTotalsInChannels = `-- name: MonthlyTotalsInChannels :many
+SELECT month_year,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ?
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(? AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(? AS TEXT)
+AND channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT)))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid AND o.count_totals = 0)
+GROUP BY month_year
+`
+
+type MonthlyTotalsInChannelsParams struct {
+	TeamID        string
+	FromYearMonth string
+	ToYearMonth   string
+	ChannelIds    string
+}
+
+type MonthlyTotalsInChannelsRow struct {
+	MonthYear        string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MonthlyTotalsInChannels(ctx context.Context, arg MonthlyTotalsInChannelsParams) ([]MonthlyTotalsInChannelsRow, error) {
+	rows, err := q.db.QueryContext(ctx, monthlyTotalsInChannels,
+		arg.TeamID,
+		arg.FromYearMonth,
+		arg.ToYearMonth,
+		arg.ChannelIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MonthlyTotalsInChannelsRow
+	for rows.Next() {
+		var i MonthlyTotalsInChannelsRow
+		if err := rows.Scan(&i.MonthYear, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const mostDislikesReceived = `-- name: MostDislikesReceived :one
+SELECT m.id, m.team_id, m.month_year, m.slack_uid, m.received_likes, m.received_dislikes, m.created_at, m.updated_at
+FROM members m
+WHERE team_id = ? AND month_year = ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+ORDER BY received_dislikes DESC
+LIMIT 1
+`
+
+type MostDislikesReceivedParams struct {
+	TeamID    string
+	MonthYear string
+}
+
+func (q *Queries) MostDislikesReceived(ctx context.Context, arg MostDislikesReceivedParams) (Member, error) {
+	row := q.db.QueryRowContext(ctx, mostDislikesReceived, arg.TeamID, arg.MonthYear)
+	var i Member
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.MonthYear,
+		&i.SlackUid,
+		&i.ReceivedLikes,
+		&i.ReceivedDislikes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const mostDislikesReceivedBetween = `-- name: MostDislikesReceivedBetween :one
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+ORDER BY received_dislikes DESC
+LIMIT 1
+`
+
+type MostDislikesReceivedBetweenParams struct {
+	TeamID string
+	Start  string
+	End    string
+}
+
+type MostDislikesReceivedBetweenRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MostDislikesReceivedBetween(ctx context.Context, arg MostDislikesReceivedBetweenParams) (MostDislikesReceivedBetweenRow, error) {
+	row := q.db.QueryRowContext(ctx, mostDislikesReceivedBetween, arg.TeamID, arg.Start, arg.End)
+	var i MostDislikesReceivedBetweenRow
+	err := row.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes)
+	return i, err
+}
+
+const mostDislikesReceivedInChannels = `-- name: MostDislikesReceivedInChannels :one
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT)))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+ORDER BY received_dislikes DESC
+LIMIT 1
+`
+
+type MostDislikesReceivedInChannelsParams struct {
+	TeamID     string
+	Start      string
+	End        string
+	ChannelIds string
+}
+
+type MostDislikesReceivedInChannelsRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MostDislikesReceivedInChannels(ctx context.Context, arg MostDislikesReceivedInChannelsParams) (MostDislikesReceivedInChannelsRow, error) {
+	row := q.db.QueryRowContext(ctx, mostDislikesReceivedInChannels,
+		arg.TeamID,
+		arg.Start,
+		arg.End,
+		arg.ChannelIds,
+	)
+	var i MostDislikesReceivedInChannelsRow
+	err := row.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes)
+	return i, err
+}
+
+const mostDislikesReceivedInMonths = `-- name: MostDislikesReceivedInMonths :one
+SELECT slack_uid,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = ?
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(? AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(? AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+ORDER BY received_dislikes DESC
+LIMIT 1
+`
+
+type MostDislikesReceivedInMonthsParams struct {
+	TeamID        string
+	FromYearMonth string
+	ToYearMonth   string
+}
+
+type MostDislikesReceivedInMonthsRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MostDislikesReceivedInMonths(ctx context.Context, arg MostDislikesReceivedInMonthsParams) (MostDislikesReceivedInMonthsRow, error) {
+	row := q.db.QueryRowContext(ctx, mostDislikesReceivedInMonths, arg.TeamID, arg.FromYearMonth, arg.ToYearMonth)
+	var i MostDislikesReceivedInMonthsRow
+	err := row.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes)
+	return i, err
+}
+
+const mostLikedMessage = `-- name: MostLikedMessage :one
+SELECT channel_id, message_ts, author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND (CAST(? AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY channel_id, message_ts, author_uid
+ORDER BY received_likes DESC, received_dislikes ASC, message_ts ASC
+LIMIT 1
+`
+
+type MostLikedMessageParams struct {
+	TeamID      string
+	Start       string
+	End         string
+	AllChannels int64
+	ChannelIds  string
+}
+
+type MostLikedMessageRow struct {
+	ChannelID        string
+	MessageTs        string
+	AuthorUid        string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MostLikedMessage(ctx context.Context, arg MostLikedMessageParams) (MostLikedMessageRow, error) {
+	row := q.db.QueryRowContext(ctx, mostLikedMessage,
+		arg.TeamID,
+		arg.Start,
+		arg.End,
+		arg.AllChannels,
+		arg.ChannelIds,
+	)
+	var i MostLikedMessageRow
+	err := row.Scan(
+		&i.ChannelID,
+		&i.MessageTs,
+		&i.AuthorUid,
+		&i.ReceivedLikes,
+		&i.ReceivedDislikes,
+	)
+	return i, err
+}
+
+const mostLikesReceived = `-- name: MostLikesReceived :one
+SELECT m.id, m.team_id, m.month_year, m.slack_uid, m.received_likes, m.received_dislikes, m.created_at, m.updated_at
+FROM members m
+WHERE team_id = ? AND month_year = ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+ORDER BY received_likes DESC
+LIMIT 1
+`
+
+type MostLikesReceivedParams struct {
+	TeamID    string
+	MonthYear string
+}
+
+func (q *Queries) MostLikesReceived(ctx context.Context, arg MostLikesReceivedParams) (Member, error) {
+	row := q.db.QueryRowContext(ctx, mostLikesReceived, arg.TeamID, arg.MonthYear)
+	var i Member
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.MonthYear,
+		&i.SlackUid,
+		&i.ReceivedLikes,
+		&i.ReceivedDislikes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const mostLikesReceivedBetween = `-- name: MostLikesReceivedBetween :one
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+ORDER BY received_likes DESC
+LIMIT 1
+`
+
+type MostLikesReceivedBetweenParams struct {
+	TeamID string
+	Start  string
+	End    string
+}
+
+type MostLikesReceivedBetweenRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MostLikesReceivedBetween(ctx context.Context, arg MostLikesReceivedBetweenParams) (MostLikesReceivedBetweenRow, error) {
+	row := q.db.QueryRowContext(ctx, mostLikesReceivedBetween, arg.TeamID, arg.Start, arg.End)
+	var i MostLikesReceivedBetweenRow
+	err := row.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes)
+	return i, err
+}
+
+const mostLikesReceivedInChannels = `-- name: MostLikesReceivedInChannels :one
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT)))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+ORDER BY received_likes DESC
+LIMIT 1
+`
+
+type MostLikesReceivedInChannelsParams struct {
+	TeamID     string
+	Start      string
+	End        string
+	ChannelIds string
+}
+
+type MostLikesReceivedInChannelsRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MostLikesReceivedInChannels(ctx context.Context, arg MostLikesReceivedInChannelsParams) (MostLikesReceivedInChannelsRow, error) {
+	row := q.db.QueryRowContext(ctx, mostLikesReceivedInChannels,
+		arg.TeamID,
+		arg.Start,
+		arg.End,
+		arg.ChannelIds,
+	)
+	var i MostLikesReceivedInChannelsRow
+	err := row.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes)
+	return i, err
+}
+
+const mostLikesReceivedInMonths = `-- name: MostLikesReceivedInMonths :one
+SELECT slack_uid,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = ?
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(? AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(? AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+ORDER BY received_likes DESC
+LIMIT 1
+`
+
+type MostLikesReceivedInMonthsParams struct {
+	TeamID        string
+	FromYearMonth string
+	ToYearMonth   string
+}
+
+type MostLikesReceivedInMonthsRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) MostLikesReceivedInMonths(ctx context.Context, arg MostLikesReceivedInMonthsParams) (MostLikesReceivedInMonthsRow, error) {
+	row := q.db.QueryRowContext(ctx, mostLikesReceivedInMonths, arg.TeamID, arg.FromYearMonth, arg.ToYearMonth)
+	var i MostLikesReceivedInMonthsRow
+	err := row.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes)
+	return i, err
+}
+
+const pruneAuditEntries = `-- name: PruneAuditEntries :execrows
+DELETE FROM audit_log
+WHERE id IN (SELECT id FROM audit_log WHERE created_at < ? LIMIT ?)
+`
+
+type PruneAuditEntriesParams struct {
+	CreatedAt string
+	Limit     int64
+}
+
+func (q *Queries) PruneAuditEntries(ctx context.Context, arg PruneAuditEntriesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneAuditEntries, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneReactions = `-- name: PruneReactions :execrows
+DELETE FROM reactions
+WHERE id IN (
+    SELECT id FROM reactions
+    WHERE substr(month_year, 4, 4) || substr(month_year, 1, 2) < CAST(? AS TEXT)
+    LIMIT ?
+)
+`
+
+type PruneReactionsParams struct {
+	BeforeYearMonth string
+	Limit           int64
+}
+
+func (q *Queries) PruneReactions(ctx context.Context, arg PruneReactionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneReactions, arg.BeforeYearMonth, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reactionEdges = `-- name: ReactionEdges :many
+SELECT reactor_uid, author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid IN (r.author_uid, r.reactor_uid))
+GROUP BY reactor_uid, author_uid
+ORDER BY reactor_uid ASC, author_uid ASC
+`
+
+type ReactionEdgesParams struct {
+	TeamID string
+	Start  string
+	End    string
+}
+
+type ReactionEdgesRow struct {
+	ReactorUid string
+	AuthorUid  string
+	Likes      int64
+	Dislikes   int64
+}
+
+func (q *Queries) ReactionEdges(ctx context.Context, arg ReactionEdgesParams) ([]ReactionEdgesRow, error) {
+	rows, err := q.db.QueryContext(ctx, reactionEdges, arg.TeamID, arg.Start, arg.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReactionEdgesRow
+	for rows.Next() {
+		var i ReactionEdgesRow
+		if err := rows.Scan(
+			&i.ReactorUid,
+			&i.AuthorUid,
+			&i.Likes,
+			&i.Dislikes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const redactAuditEntries = `-- name: RedactAuditEntries :execrows
+UPDATE audit_log SET
+    actor = CASE WHEN actor = 'slack:' || CAST(? AS TEXT) THEN 'slack:' || CAST(? AS TEXT) ELSE actor END,
+    target = CASE WHEN target = CAST(? AS TEXT) THEN CAST(? AS TEXT) ELSE target END,
+    before_value = replace(before_value, '"' || CAST(? AS TEXT) || '"', '"' || CAST(? AS TEXT) || '"'),
+    after_value = replace(after_value, '"' || CAST(? AS TEXT) || '"', '"' || CAST(? AS TEXT) || '"')
+WHERE team_id = ? AND (
+    actor = 'slack:' || CAST(? AS TEXT) OR target = CAST(? AS TEXT)
+    OR instr(before_value, '"' || CAST(? AS TEXT) || '"') > 0 OR instr(after_value, '"' || CAST(? AS TEXT) || '"') > 0
+)
+`
+
+type RedactAuditEntriesParams struct {
+	SlackUid  string
+	Pseudonym string
+	TeamID    string
+}
+
+func (q *Queries) RedactAuditEntries(ctx context.Context, arg RedactAuditEntriesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, redactAuditEntries,
+		arg.SlackUid,
+		arg.Pseudonym,
+		arg.SlackUid,
+		arg.Pseudonym,
+		arg.SlackUid,
+		arg.Pseudonym,
+		arg.SlackUid,
+		arg.Pseudonym,
+		arg.TeamID,
+		arg.SlackUid,
+		arg.SlackUid,
+		arg.SlackUid,
+		arg.SlackUid,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revertAdjustment = `-- name: RevertAdjustment :one
+UPDATE adjustments
+SET reverted_by = ?,
+reverted_at = ?
+WHERE id = ? AND reverted_at = ''
+RETURNING id, team_id, month_year, slack_uid, metric, delta, reason, actor, created_at, reverted_by, reverted_at
+`
+
+type RevertAdjustmentParams struct {
+	RevertedBy string
+	RevertedAt string
+	ID         int64
+}
+
+func (q *Queries) RevertAdjustment(ctx context.Context, arg RevertAdjustmentParams) (Adjustment, error) {
+	row := q.db.QueryRowContext(ctx, revertAdjustment, arg.RevertedBy, arg.RevertedAt, arg.ID)
+	var i Adjustment
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.MonthYear,
+		&i.SlackUid,
+		&i.Metric,
+		&i.Delta,
+		&i.Reason,
+		&i.Actor,
+		&i.CreatedAt,
+		&i.RevertedBy,
+		&i.RevertedAt,
+	)
+	return i, err
+}
+
+const saveBackfillCheckpoint = `-- name: SaveBackfillCheckpoint :exec
+INSERT INTO backfill_checkpoints (
+    team_id,
+    channel_id,
+    from_month_year,
+    to_month_year,
+    cursor,
+    done,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(team_id, channel_id, from_month_year, to_month_year) DO UPDATE
+SET cursor = excluded.cursor,
+done = excluded.done,
+updated_at = excluded.updated_at
+`
+
+type SaveBackfillCheckpointParams struct {
+	TeamID        string
+	ChannelID     string
+	FromMonthYear string
+	ToMonthYear   string
+	Cursor        string
+	Done          int64
+	UpdatedAt     string
+}
+
+func (q *Queries) SaveBackfillCheckpoint(ctx context.Context, arg SaveBackfillCheckpointParams) error {
+	_, err := q.db.ExecContext(ctx, saveBackfillCheckpoint,
+		arg.TeamID,
+		arg.ChannelID,
+		arg.FromMonthYear,
+		arg.ToMonthYear,
+		arg.Cursor,
+		arg.Done,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const saveOptOut = `-- name: SaveOptOut :exec
+INSERT INTO opt_outs (
+    team_id,
+    slack_uid,
+    count_totals,
+    created_at
+) VALUES (
+    ?, ?, ?, ?
+)
+ON CONFLICT(team_id, slack_uid) DO UPDATE SET
+count_totals = excluded.count_totals
+`
+
+type SaveOptOutParams struct {
+	TeamID      string
+	SlackUid    string
+	CountTotals int64
+	CreatedAt   string
+}
+
+func (q *Queries) SaveOptOut(ctx context.Context, arg SaveOptOutParams) error {
+	_, err := q.db.ExecContext(ctx, saveOptOut,
+		arg.TeamID,
+		arg.SlackUid,
+		arg.CountTotals,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const savePrunedBefore = `-- name: SavePrunedBefore :exec
+INSERT INTO retention (
+    name,
+    pruned_before
+) VALUES (
+    ?, ?
+)
+ON CONFLICT(name) DO UPDATE SET
+pruned_before = MAX(pruned_before, excluded.pruned_before)
+`
+
+type SavePrunedBeforeParams struct {
+	Name         string
+	PrunedBefore string
+}
+
+func (q *Queries) SavePrunedBefore(ctx context.Context, arg SavePrunedBeforeParams) error {
+	_, err := q.db.ExecContext(ctx, savePrunedBefore, arg.Name, arg.PrunedBefore)
+	return err
+}
+
+const saveWorkspace = `-- name: SaveWorkspace :one
+INSERT INTO workspaces (
+    team_id,
+    team_name,
+    bot_user_id,
+    bot_token,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(team_id) DO UPDATE
+SET team_name = excluded.team_name,
+bot_user_id = excluded.bot_user_id,
+bot_token = excluded.bot_token,
+updated_at = excluded.updated_at
+RETURNING team_id, team_name, bot_user_id, bot_token, created_at, updated_at
+`
+
+type SaveWorkspaceParams struct {
+	TeamID    string
+	TeamName  string
+	BotUserID string
+	BotToken  string
+	CreatedAt string
+	UpdatedAt string
+}
+
+func (q *Queries) SaveWorkspace(ctx context.Context, arg SaveWorkspaceParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, saveWorkspace,
+		arg.TeamID,
+		arg.TeamName,
+		arg.BotUserID,
+		arg.BotToken,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Workspace
+	err := row.Scan(
+		&i.TeamID,
+		&i.TeamName,
+		&i.BotUserID,
+		&i.BotToken,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setMemberReactions = `-- name: SetMemberReactions :exec
+INSERT INTO members (
+    team_id,
+    month_year,
+    slack_uid,
+    received_likes,
+    received_dislikes,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(team_id, slack_uid, month_year) DO UPDATE
+SET received_likes = excluded.received_likes,
+received_dislikes = excluded.received_dislikes,
+updated_at = excluded.updated_at
+`
+
+type SetMemberReactionsParams struct {
+	TeamID           string
+	MonthYear        string
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+	CreatedAt        string
+	UpdatedAt        string
+}
+
+func (q *Queries) SetMemberReactions(ctx context.Context, arg SetMemberReactionsParams) error {
+	_, err := q.db.ExecContext(ctx, setMemberReactions,
+		arg.TeamID,
+		arg.MonthYear,
+		arg.SlackUid,
+		arg.ReceivedLikes,
+		arg.ReceivedDislikes,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const sumAdjustments = `-- name: SumAdjustments :many
+SELECT slack_uid,
+CAST(SUM(CASE WHEN metric = 'likes' THEN delta ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN metric = 'dislikes' THEN delta ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM adjustments
+WHERE team_id = ? AND month_year = ? AND reverted_at = ''
+GROUP BY slack_uid
+ORDER BY slack_uid
+`
+
+type SumAdjustmentsParams struct {
+	TeamID    string
+	MonthYear string
+}
+
+type SumAdjustmentsRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) SumAdjustments(ctx context.Context, arg SumAdjustmentsParams) ([]SumAdjustmentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, sumAdjustments, arg.TeamID, arg.MonthYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SumAdjustmentsRow
+	for rows.Next() {
+		var i SumAdjustmentsRow
+		if err := rows.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumMemberReactions = `-- name: SumMemberReactions :many
+SELECT team_id,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE month_year = ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid AND o.count_totals = 0)
+GROUP BY team_id
+ORDER BY team_id
+`
+
+type SumMemberReactionsRow struct {
+	TeamID           string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) SumMemberReactions(ctx context.Context, monthYear string) ([]SumMemberReactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, sumMemberReactions, monthYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SumMemberReactionsRow
+	for rows.Next() {
+		var i SumMemberReactionsRow
+		if err := rows.Scan(&i.TeamID, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const topMembersBetween = `-- name: TopMembersBetween :many
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND (CAST(? AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+HAVING received_likes > 0
+ORDER BY received_likes DESC, received_dislikes ASC, slack_uid ASC
+LIMIT ?
+`
+
+type TopMembersBetweenParams struct {
+	TeamID      string
+	Start       string
+	End         string
+	AllChannels int64
+	ChannelIds  string
+	Limit       int64
+}
+
+type TopMembersBetweenRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) TopMembersBetween(ctx context.Context, arg TopMembersBetweenParams) ([]TopMembersBetweenRow, error) {
+	rows, err := q.db.QueryContext(ctx, topMembersBetween,
+		arg.TeamID,
+		arg.Start,
+		arg.End,
+		arg.AllChannels,
+		arg.ChannelIds,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TopMembersBetweenRow
+	for rows.Next() {
+		var i TopMembersBetweenRow
+		if err := rows.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const topMembersInMonths = `-- name: TopMembersInMonths :many
+SELECT slack_uid,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = ?
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(? AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(? AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+HAVING received_likes > 0
+ORDER BY received_likes DESC, received_dislikes ASC, slack_uid ASC
+LIMIT ?
+`
+
+type TopMembersInMonthsParams struct {
+	TeamID        string
+	FromYearMonth string
+	ToYearMonth   string
+	Limit         int64
+}
+
+type TopMembersInMonthsRow struct {
+	SlackUid         string
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) TopMembersInMonths(ctx context.Context, arg TopMembersInMonthsParams) ([]TopMembersInMonthsRow, error) {
+	rows, err := q.db.QueryContext(ctx, topMembersInMonths,
+		arg.TeamID,
+		arg.FromYearMonth,
+		arg.ToYearMonth,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TopMembersInMonthsRow
+	for rows.Next() {
+		var i TopMembersInMonthsRow
+		if err := rows.Scan(&i.SlackUid, &i.ReceivedLikes, &i.ReceivedDislikes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const totalReactionsBetween = `-- name: TotalReactionsBetween :one
+SELECT CAST(COALESCE(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END), 0) AS INTEGER) AS received_likes,
+CAST(COALESCE(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END), 0) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = ? AND reacted_at >= ? AND reacted_at < ?
+AND (CAST(? AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(? AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid AND o.count_totals = 0)
+`
+
+type TotalReactionsBetweenParams struct {
+	TeamID      string
+	Start       string
+	End         string
+	AllChannels int64
+	ChannelIds  string
+}
+
+type TotalReactionsBetweenRow struct {
+	ReceivedLikes    int64
+	ReceivedDislikes int64
+}
+
+func (q *Queries) TotalReactionsBetween(ctx context.Context, arg TotalReactionsBetweenParams) (TotalReactionsBetweenRow, error) {
+	row := q.db.QueryRowContext(ctx, totalReactionsBetween,
+		arg.TeamID,
+		arg.Start,
+		arg.End,
+		arg.AllChannels,
+		arg.ChannelIds,
+	)
+	var i TotalReactionsBetweenRow
+	err := row.Scan(&i.ReceivedLikes, &i.ReceivedDislikes)
+	return i, err
+}
+
+const updateMember = `-- name: UpdateMember :one
 UPDATE members
 SET received_likes = ?,
 received_dislikes = ?,
 updated_at = ?
 WHERE id = ?
-RETURNING id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at
+RETURNING id, team_id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at
 `
 
 type UpdateMemberParams struct {
@@ -175,6 +2429,7 @@ func (q *Queries) UpdateMember(ctx context.Context, arg UpdateMemberParams) (Mem
 	var i Member
 	err := row.Scan(
 		&i.ID,
+		&i.TeamID,
 		&i.MonthYear,
 		&i.SlackUid,
 		&i.ReceivedLikes,
@@ -184,3 +2439,21 @@ func (q *Queries) UpdateMember(ctx context.Context, arg UpdateMemberParams) (Mem
 	)
 	return i, err
 }
+
+const updateWorkspaceBotToken = `-- name: UpdateWorkspaceBotToken :exec
+UPDATE workspaces
+SET bot_token = ?,
+updated_at = ?
+WHERE team_id = ?
+`
+
+type UpdateWorkspaceBotTokenParams struct {
+	BotToken  string
+	UpdatedAt string
+	TeamID    string
+}
+
+func (q *Queries) UpdateWorkspaceBotToken(ctx context.Context, arg UpdateWorkspaceBotTokenParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceBotToken, arg.BotToken, arg.UpdatedAt, arg.TeamID)
+	return err
+}
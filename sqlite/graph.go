@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.GraphService = (*GraphService)(nil)
+
+// GraphService represents a service for analysing who reacts to whom.
+type GraphService struct {
+	db *DB
+}
+
+// NewGraphService returns a new instance of GraphService.
+func NewGraphService(db *DB) *GraphService {
+	return &GraphService{
+		db: db,
+	}
+}
+
+// FindReactionGraph retrieves the graph of reactions from reactors to authors within a
+// workspace over a period, counted from the recorded Reactions. Members who opted out are
+// left out entirely. The all-time period covers the Reactions which haven't been pruned;
+// ErrInvalid is returned if any other period starts before they have been pruned.
+func (gs *GraphService) FindReactionGraph(ctx context.Context, teamID string, period statsd.Period) (*statsd.ReactionGraph, error) {
+	ctx, span := tracer.Start(ctx, "GraphService.FindReactionGraph")
+	defer span.End()
+
+	start, end, err := reactedBetween(ctx, gs.db.query, period)
+	if err != nil {
+		return nil, fmt.Errorf("FindReactionGraph: %w", err)
+	}
+	genEdges, err := gs.db.query.ReactionEdges(ctx, gen.ReactionEdgesParams{
+		TeamID: teamID,
+		Start:  start,
+		End:    end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FindReactionGraph ReactionEdges: %w", err)
+	}
+	// Every member who ever received a reaction is part of the graph, even if they are isolated
+	// within the period.
+	uids, err := gs.db.query.ListMemberUIDs(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("FindReactionGraph ListMemberUIDs: %w", err)
+	}
+
+	g := &statsd.ReactionGraph{TeamID: teamID, Period: period, Edges: make([]statsd.ReactionEdge, 0, len(genEdges))}
+	members := make(map[string]bool)
+	for _, uid := range uids {
+		members[uid] = true
+	}
+	for _, e := range genEdges {
+		g.Edges = append(g.Edges, statsd.ReactionEdge{
+			ReactorUID: e.ReactorUid,
+			AuthorUID:  e.AuthorUid,
+			Likes:      int(e.Likes),
+			Dislikes:   int(e.Dislikes),
+		})
+		members[e.ReactorUid] = true
+		members[e.AuthorUid] = true
+	}
+	for uid := range members {
+		g.Members = append(g.Members, uid)
+	}
+	sort.Strings(g.Members)
+	return g, nil
+}
@@ -0,0 +1,56 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestGraphService_FindReactionGraph(t *testing.T) {
+	// Ensure reactions are summed per reactor and author, leaving out members who opted out.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		gs := sqlite.NewGraphService(db)
+
+		reactedAt := time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC)
+		for i, r := range []struct {
+			reactorUID, authorUID, name string
+		}{
+			{"U1ZN1SE2N", "U2ZN1SE2N", statsd.ThumbsUp},
+			{"U1ZN1SE2N", "U2ZN1SE2N", statsd.ThumbsDown},
+			{"U2ZN1SE2N", "U1ZN1SE2N", statsd.ThumbsUp},
+			{"U4ZN1SE2N", "U1ZN1SE2N", statsd.ThumbsUp},
+		} {
+			MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: "C1ZN1SE2N", MessageTS: fmt.Sprintf("1147683600.%06d", i+1), ReactorUID: r.reactorUID, AuthorUID: r.authorUID, Name: r.name, ReactedAt: reactedAt})
+		}
+		// A member who only received reactions in the previous month is isolated.
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("04-2006"), SlackUID: "U3ZN1SE2N", ReceivedLikes: 1})
+		if err := sqlite.NewPrivacyService(db).OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U4ZN1SE2N"}); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := statsd.NewMonthPeriod(statsd.MonthYear("05-2006"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := gs.FindReactionGraph(context.Background(), "T1ZN1SE2N", p)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := g.Members, []string{"U1ZN1SE2N", "U2ZN1SE2N", "U3ZN1SE2N"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Members=%v, want %v", got, want)
+		} else if got, want := g.Edges, []statsd.ReactionEdge{
+			{ReactorUID: "U1ZN1SE2N", AuthorUID: "U2ZN1SE2N", Likes: 1, Dislikes: 1},
+			{ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Likes: 1},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Edges=%#v, want %#v", got, want)
+		} else if got, want := g.Isolated(), []string{"U3ZN1SE2N"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Isolated=%v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.HeatmapService = (*HeatmapService)(nil)
+
+// HeatmapService represents a service for analysing when members react.
+type HeatmapService struct {
+	db *DB
+}
+
+// NewHeatmapService returns a new instance of HeatmapService.
+func NewHeatmapService(db *DB) *HeatmapService {
+	return &HeatmapService{
+		db: db,
+	}
+}
+
+// FindHeatmap retrieves the Heatmap of the recorded Reactions within a workspace over a period.
+// The reactions may be restricted to the given channels; all channels are included if none
+// are given. Reactions to members who opted out of the totals are left out. The all-time period
+// covers the Reactions which haven't been pruned; ErrInvalid is returned if any other period
+// starts before they have been pruned.
+func (hs *HeatmapService) FindHeatmap(ctx context.Context, teamID string, channelIDs []string, period statsd.Period) (*statsd.Heatmap, error) {
+	ctx, span := tracer.Start(ctx, "HeatmapService.FindHeatmap")
+	defer span.End()
+
+	rawChannelIDs, err := json.Marshal(channelIDs)
+	if err != nil {
+		return nil, fmt.Errorf("FindHeatmap: %w", err)
+	}
+	start, end, err := reactedBetween(ctx, hs.db.query, period)
+	if err != nil {
+		return nil, fmt.Errorf("FindHeatmap: %w", err)
+	}
+	arg := gen.CountReactionsPerQuarterHourParams{
+		TeamID:     teamID,
+		Start:      start,
+		End:        end,
+		ChannelIds: string(rawChannelIDs),
+	}
+	if len(channelIDs) == 0 {
+		arg.AllChannels = 1
+	}
+	// Reactions are counted per quarter of an hour in UTC and only then assigned to the hours of
+	// TimeZone, whose offset may change within the period and need not be whole hours. Offsets
+	// are multiples of 15 minutes, so every quarter falls within a single hour of TimeZone.
+	rows, err := hs.db.query.CountReactionsPerQuarterHour(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("FindHeatmap CountReactionsPerQuarterHour: %w", err)
+	}
+
+	h := &statsd.Heatmap{TeamID: teamID, Period: period, ChannelIDs: channelIDs}
+	for _, row := range rows {
+		t, err := time.Parse("2006-01-02T15:04", row.QuarterHour)
+		if err != nil {
+			return nil, fmt.Errorf("FindHeatmap: %w", err)
+		}
+		h.Add(t, int(row.Reactions))
+	}
+	return h, nil
+}
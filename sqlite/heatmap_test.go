@@ -0,0 +1,79 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestHeatmapService_FindHeatmap(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	hs := sqlite.NewHeatmapService(db)
+
+	for i, r := range []struct {
+		channelID, authorUID string
+		reactedAt            time.Time
+	}{
+		// Monday, May 15, 2006.
+		{"C1ZN1SE2N", "U1ZN1SE2N", time.Date(2006, time.May, 15, 9, 10, 0, 0, time.UTC)},
+		{"C1ZN1SE2N", "U1ZN1SE2N", time.Date(2006, time.May, 15, 9, 10, 30, 0, time.UTC)},
+		{"C2ZN1SE2N", "U1ZN1SE2N", time.Date(2006, time.May, 15, 9, 50, 0, 0, time.UTC)},
+		// Sunday, May 21, 2006.
+		{"C1ZN1SE2N", "U1ZN1SE2N", time.Date(2006, time.May, 21, 23, 0, 0, 0, time.UTC)},
+		// Reactions to members who opted out and from the previous month are left out.
+		{"C1ZN1SE2N", "U2ZN1SE2N", time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC)},
+		{"C1ZN1SE2N", "U1ZN1SE2N", time.Date(2006, time.April, 17, 9, 0, 0, 0, time.UTC)},
+	} {
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(r.reactedAt), ChannelID: r.channelID, MessageTS: fmt.Sprintf("1147683600.%06d", i+1), ReactorUID: "U3ZN1SE2N", AuthorUID: r.authorUID, Name: statsd.ThumbsUp, ReactedAt: r.reactedAt})
+	}
+	if err := sqlite.NewPrivacyService(db).OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U2ZN1SE2N"}); err != nil {
+		t.Fatal(err)
+	}
+	p := MustParsePeriod(t, "05-2006")
+
+	// Ensure reactions are counted by weekday and hour.
+	t.Run("OK", func(t *testing.T) {
+		h, err := hs.FindHeatmap(context.Background(), "T1ZN1SE2N", nil, p)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := h.Counts[0][9], 3; got != want {
+			t.Fatalf("Counts[Monday][9]=%d, want %d", got, want)
+		} else if got, want := h.Counts[6][23], 1; got != want {
+			t.Fatalf("Counts[Sunday][23]=%d, want %d", got, want)
+		} else if got, want := h.Total(), 4; got != want {
+			t.Fatalf("Total=%d, want %d", got, want)
+		}
+	})
+
+	// Ensure reactions are assigned to the hours of time zones whose offset isn't whole hours.
+	t.Run("TimeZone", func(t *testing.T) {
+		MustSetTimeZone(t, "Asia/Kathmandu")
+		h, err := hs.FindHeatmap(context.Background(), "T1ZN1SE2N", nil, p)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := h.Counts[0][14], 2; got != want {
+			t.Fatalf("Counts[Monday][14]=%d, want %d", got, want)
+		} else if got, want := h.Counts[0][15], 1; got != want {
+			t.Fatalf("Counts[Monday][15]=%d, want %d", got, want)
+		} else if got, want := h.Counts[0][4], 1; got != want {
+			t.Fatalf("Counts[Monday][4]=%d, want %d", got, want)
+		}
+	})
+
+	// Ensure the reactions may be restricted to channels.
+	t.Run("Channels", func(t *testing.T) {
+		h, err := hs.FindHeatmap(context.Background(), "T1ZN1SE2N", []string{"C2ZN1SE2N"}, p)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := h.Counts[0][9], 1; got != want {
+			t.Fatalf("Counts[Monday][9]=%d, want %d", got, want)
+		} else if got, want := h.Total(), 1; got != want {
+			t.Fatalf("Total=%d, want %d", got, want)
+		}
+	})
+}
@@ -2,8 +2,15 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
 )
 
 // Ensure service implements interface.
@@ -21,11 +28,19 @@ func NewLeaderboardService(db *DB) *LeaderboardService {
 	}
 }
 
-// FindLeaderboard retrives a Leadboard by its date (year and month).
-// Returns ErrNotFound if no matches are found.
-func (ls *LeaderboardService) FindLeaderboard(date statsd.MonthYear) (*statsd.Leaderboard, error) {
-	genMostReceivedLikesMember, err := ls.db.query.MostLikesReceived(context.TODO(), date.String())
-	if err != nil {
+// FindLeaderboard retrives the Leadboard of a workspace by its date (year and month).
+// Members who opted out are left out. Returns ErrNotFound if no matches are found.
+func (ls *LeaderboardService) FindLeaderboard(ctx context.Context, teamID string, date statsd.MonthYear) (*statsd.Leaderboard, error) {
+	ctx, span := tracer.Start(ctx, "LeaderboardService.FindLeaderboard")
+	defer span.End()
+
+	genMostReceivedLikesMember, err := ls.db.query.MostLikesReceived(ctx, gen.MostLikesReceivedParams{
+		TeamID:    teamID,
+		MonthYear: date.String(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, statsd.ErrNotFound
+	} else if err != nil {
 		return nil, err
 	}
 	mostReceivedLikesMember, err := genMemberToMember(&genMostReceivedLikesMember)
@@ -33,8 +48,13 @@ func (ls *LeaderboardService) FindLeaderboard(date statsd.MonthYear) (*statsd.Le
 		return nil, err
 	}
 
-	genMostReceivedDislikesMember, err := ls.db.query.MostDislikesReceived(context.TODO(), date.String())
-	if err != nil {
+	genMostReceivedDislikesMember, err := ls.db.query.MostDislikesReceived(ctx, gen.MostDislikesReceivedParams{
+		TeamID:    teamID,
+		MonthYear: date.String(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, statsd.ErrNotFound
+	} else if err != nil {
 		return nil, err
 	}
 	mostReceivedDislikesMember, err := genMemberToMember(&genMostReceivedDislikesMember)
@@ -42,9 +62,537 @@ func (ls *LeaderboardService) FindLeaderboard(date statsd.MonthYear) (*statsd.Le
 		return nil, err
 	}
 
+	controversialMembers, err := ls.db.query.ControversialMembersInMonths(ctx, gen.ControversialMembersInMonthsParams{
+		TeamID:        teamID,
+		FromYearMonth: yearMonth(date),
+		ToYearMonth:   yearMonth(date),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := statsd.NewMonthPeriod(date)
+	if err != nil {
+		return nil, err
+	}
+
 	return &statsd.Leaderboard{
+		TeamID:                     teamID,
 		Date:                       date,
+		Period:                     period,
 		MostReceivedLikesMember:    *mostReceivedLikesMember,
 		MostReceivedDislikesMember: *mostReceivedDislikesMember,
+		MostControversialMember:    mostControversialMember(teamID, date, controversialMembers),
 	}, nil
 }
+
+// FindPeriodLeaderboard retrieves the Leaderboard of a workspace over a period. Periods of whole
+// months, such as a year to date, and the all-time period are summed from the monthly counts,
+// including adjustments, while other periods are counted from the recorded Reactions.
+// Members who opted out are left out.
+// Returns ErrNotFound if no matches are found and ErrInvalid if a period counted from the
+// recorded Reactions starts before they have been pruned or overlaps a month with adjustments,
+// which apply to the whole month and can't be counted towards a part of it.
+func (ls *LeaderboardService) FindPeriodLeaderboard(ctx context.Context, teamID string, period statsd.Period) (*statsd.Leaderboard, error) {
+	ctx, span := tracer.Start(ctx, "LeaderboardService.FindPeriodLeaderboard")
+	defer span.End()
+
+	leaderboard := &statsd.Leaderboard{TeamID: teamID, Period: period}
+	var mostLikes, mostDislikes gen.MostLikesReceivedInMonthsRow
+	var controversialMembers []gen.ControversialMembersInMonthsRow
+	var err error
+	first, last, ok := period.Months()
+	if ok || period.Kind == statsd.PeriodAllTime {
+		arg := gen.MostLikesReceivedInMonthsParams{
+			TeamID:        teamID,
+			FromYearMonth: "000001",
+			ToYearMonth:   "999912",
+		}
+		if ok {
+			arg.FromYearMonth, arg.ToYearMonth = yearMonth(first), yearMonth(last)
+			if first == last {
+				leaderboard.Date = first
+			}
+		}
+		if mostLikes, err = ls.db.query.MostLikesReceivedInMonths(ctx, arg); err == nil {
+			var row gen.MostDislikesReceivedInMonthsRow
+			row, err = ls.db.query.MostDislikesReceivedInMonths(ctx, gen.MostDislikesReceivedInMonthsParams(arg))
+			mostDislikes = gen.MostLikesReceivedInMonthsRow(row)
+		}
+		if err == nil {
+			controversialMembers, err = ls.db.query.ControversialMembersInMonths(ctx, gen.ControversialMembersInMonthsParams(arg))
+		}
+	} else {
+		var start, end string
+		if start, end, err = reactedBetween(ctx, ls.db.query, period); err != nil {
+			return nil, fmt.Errorf("FindPeriodLeaderboard: %w", err)
+		} else if err = unadjusted(ctx, ls.db.query, teamID, period); err != nil {
+			return nil, fmt.Errorf("FindPeriodLeaderboard: %w", err)
+		}
+		arg := gen.MostLikesReceivedBetweenParams{
+			TeamID: teamID,
+			Start:  start,
+			End:    end,
+		}
+		var likesRow gen.MostLikesReceivedBetweenRow
+		if likesRow, err = ls.db.query.MostLikesReceivedBetween(ctx, arg); err == nil {
+			mostLikes = gen.MostLikesReceivedInMonthsRow(likesRow)
+			var row gen.MostDislikesReceivedBetweenRow
+			row, err = ls.db.query.MostDislikesReceivedBetween(ctx, gen.MostDislikesReceivedBetweenParams(arg))
+			mostDislikes = gen.MostLikesReceivedInMonthsRow(row)
+		}
+		if err == nil {
+			controversialMembers, err = ls.controversialMembersBetween(ctx, gen.ControversialMembersBetweenParams{
+				TeamID:      teamID,
+				Start:       arg.Start,
+				End:         arg.End,
+				AllChannels: 1,
+			})
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, statsd.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("FindPeriodLeaderboard: %w", err)
+	}
+
+	leaderboard.MostReceivedLikesMember = statsd.Member{TeamID: teamID, Date: leaderboard.Date, SlackUID: mostLikes.SlackUid, ReceivedLikes: int(mostLikes.ReceivedLikes), ReceivedDislikes: int(mostLikes.ReceivedDislikes)}
+	leaderboard.MostReceivedDislikesMember = statsd.Member{TeamID: teamID, Date: leaderboard.Date, SlackUID: mostDislikes.SlackUid, ReceivedLikes: int(mostDislikes.ReceivedLikes), ReceivedDislikes: int(mostDislikes.ReceivedDislikes)}
+	leaderboard.MostControversialMember = mostControversialMember(teamID, leaderboard.Date, controversialMembers)
+	return leaderboard, nil
+}
+
+// FindChannelLeaderboard retrieves the Leaderboard of a workspace over a period, counting only
+// the recorded Reactions within the given channels. Adjustments don't belong to a channel and
+// are left out, as are members who opted out.
+// Returns ErrInvalid if no channel is given or if the period starts before the Reactions have
+// been pruned, and ErrNotFound if no matches are found.
+func (ls *LeaderboardService) FindChannelLeaderboard(ctx context.Context, teamID string, channelIDs []string, period statsd.Period) (*statsd.Leaderboard, error) {
+	ctx, span := tracer.Start(ctx, "LeaderboardService.FindChannelLeaderboard")
+	defer span.End()
+
+	if len(channelIDs) == 0 {
+		return nil, fmt.Errorf("channel required %w", statsd.ErrInvalid)
+	}
+	rawChannelIDs, err := json.Marshal(channelIDs)
+	if err != nil {
+		return nil, fmt.Errorf("FindChannelLeaderboard: %w", err)
+	}
+
+	start, end, err := reactedBetween(ctx, ls.db.query, period)
+	if err != nil {
+		return nil, fmt.Errorf("FindChannelLeaderboard: %w", err)
+	}
+	arg := gen.MostLikesReceivedInChannelsParams{
+		TeamID:     teamID,
+		Start:      start,
+		End:        end,
+		ChannelIds: string(rawChannelIDs),
+	}
+	mostLikes, err := ls.db.query.MostLikesReceivedInChannels(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, statsd.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("FindChannelLeaderboard MostLikesReceivedInChannels: %w", err)
+	}
+	mostDislikes, err := ls.db.query.MostDislikesReceivedInChannels(ctx, gen.MostDislikesReceivedInChannelsParams(arg))
+	if err != nil {
+		return nil, fmt.Errorf("FindChannelLeaderboard MostDislikesReceivedInChannels: %w", err)
+	}
+	controversialMembers, err := ls.controversialMembersBetween(ctx, gen.ControversialMembersBetweenParams{
+		TeamID:     teamID,
+		Start:      start,
+		End:        end,
+		ChannelIds: arg.ChannelIds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FindChannelLeaderboard: %w", err)
+	}
+
+	leaderboard := &statsd.Leaderboard{TeamID: teamID, Period: period, ChannelIDs: channelIDs}
+	if first, last, ok := period.Months(); ok && first == last {
+		leaderboard.Date = first
+	}
+	leaderboard.MostReceivedLikesMember = statsd.Member{TeamID: teamID, Date: leaderboard.Date, SlackUID: mostLikes.SlackUid, ReceivedLikes: int(mostLikes.ReceivedLikes), ReceivedDislikes: int(mostLikes.ReceivedDislikes)}
+	leaderboard.MostReceivedDislikesMember = statsd.Member{TeamID: teamID, Date: leaderboard.Date, SlackUID: mostDislikes.SlackUid, ReceivedLikes: int(mostDislikes.ReceivedLikes), ReceivedDislikes: int(mostDislikes.ReceivedDislikes)}
+	leaderboard.MostControversialMember = mostControversialMember(teamID, leaderboard.Date, controversialMembers)
+	return leaderboard, nil
+}
+
+// controversialMembersBetween returns the members who received both likes and dislikes through
+// the reactions within a period.
+func (ls *LeaderboardService) controversialMembersBetween(ctx context.Context, arg gen.ControversialMembersBetweenParams) ([]gen.ControversialMembersInMonthsRow, error) {
+	rows, err := ls.db.query.ControversialMembersBetween(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("ControversialMembersBetween: %w", err)
+	}
+	members := make([]gen.ControversialMembersInMonthsRow, len(rows))
+	for i, row := range rows {
+		members[i] = gen.ControversialMembersInMonthsRow(row)
+	}
+	return members, nil
+}
+
+// mostControversialMember returns the member with the highest ControversyScore.
+// Returns the zero Member if there are no members.
+func mostControversialMember(teamID string, date statsd.MonthYear, rows []gen.ControversialMembersInMonthsRow) statsd.Member {
+	var member statsd.Member
+	var maxScore float64
+	for _, row := range rows {
+		if score := statsd.ControversyScore(int(row.ReceivedLikes), int(row.ReceivedDislikes)); score > maxScore {
+			member = statsd.Member{TeamID: teamID, Date: date, SlackUID: row.SlackUid, ReceivedLikes: int(row.ReceivedLikes), ReceivedDislikes: int(row.ReceivedDislikes)}
+			maxScore = score
+		}
+	}
+	return member
+}
+
+// FindMessageLeaderboard retrieves the most liked and the most controversial messages of a
+// workspace over a period, counted from the recorded Reactions. The most controversial message
+// has the highest ControversyScore. The messages may be restricted to the given channels;
+// all channels are included if none are given. Messages of members who opted out are left out.
+// Returns ErrInvalid if the period starts before the Reactions have been pruned, unless it is the
+// all-time period, which covers the remaining ones, and ErrNotFound if no message received a reaction.
+func (ls *LeaderboardService) FindMessageLeaderboard(ctx context.Context, teamID string, channelIDs []string, period statsd.Period) (*statsd.MessageLeaderboard, error) {
+	ctx, span := tracer.Start(ctx, "LeaderboardService.FindMessageLeaderboard")
+	defer span.End()
+
+	rawChannelIDs, err := json.Marshal(channelIDs)
+	if err != nil {
+		return nil, fmt.Errorf("FindMessageLeaderboard: %w", err)
+	}
+	start, end, err := reactedBetween(ctx, ls.db.query, period)
+	if err != nil {
+		return nil, fmt.Errorf("FindMessageLeaderboard: %w", err)
+	}
+	arg := gen.MostLikedMessageParams{
+		TeamID:     teamID,
+		Start:      start,
+		End:        end,
+		ChannelIds: string(rawChannelIDs),
+	}
+	if len(channelIDs) == 0 {
+		arg.AllChannels = 1
+	}
+
+	mostLiked, err := ls.db.query.MostLikedMessage(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, statsd.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("FindMessageLeaderboard MostLikedMessage: %w", err)
+	}
+	leaderboard := &statsd.MessageLeaderboard{
+		TeamID:           teamID,
+		Period:           period,
+		ChannelIDs:       channelIDs,
+		MostLikedMessage: genMessageToMessage(teamID, mostLiked),
+	}
+
+	controversialMessages, err := ls.db.query.ControversialMessages(ctx, gen.ControversialMessagesParams(arg))
+	if err != nil {
+		return nil, fmt.Errorf("FindMessageLeaderboard ControversialMessages: %w", err)
+	}
+	var maxScore float64
+	for _, row := range controversialMessages {
+		if score := statsd.ControversyScore(int(row.ReceivedLikes), int(row.ReceivedDislikes)); score > maxScore {
+			leaderboard.MostControversialMessage = genMessageToMessage(teamID, gen.MostLikedMessageRow(row))
+			maxScore = score
+		}
+	}
+	return leaderboard, nil
+}
+
+// FindTopMembers retrieves up to limit members of a workspace who received the most likes over a
+// period, ordered by their likes. Without channels, the likes are counted as by FindPeriodLeaderboard,
+// and otherwise as by FindChannelLeaderboard. Members without likes and members who opted out are left out.
+// Returns ErrInvalid if likes counted from the recorded Reactions start before they have been pruned,
+// or, without channels, if they overlap a month with adjustments.
+func (ls *LeaderboardService) FindTopMembers(ctx context.Context, teamID string, channelIDs []string, period statsd.Period, limit int) ([]statsd.Member, error) {
+	ctx, span := tracer.Start(ctx, "LeaderboardService.FindTopMembers")
+	defer span.End()
+
+	members, err := ls.topMembers(ctx, teamID, channelIDs, period, int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("FindTopMembers: %w", err)
+	}
+	return members, nil
+}
+
+// topMembers returns up to limit members who received the most likes over a period, or all of
+// them if limit is -1.
+func (ls *LeaderboardService) topMembers(ctx context.Context, teamID string, channelIDs []string, period statsd.Period, limit int64) ([]statsd.Member, error) {
+	var date statsd.MonthYear
+	first, last, ok := period.Months()
+	if ok && first == last {
+		date = first
+	}
+	var rows []gen.TopMembersInMonthsRow
+	if len(channelIDs) == 0 && (ok || period.Kind == statsd.PeriodAllTime) {
+		arg := gen.TopMembersInMonthsParams{
+			TeamID:        teamID,
+			FromYearMonth: "000001",
+			ToYearMonth:   "999912",
+			Limit:         limit,
+		}
+		if ok {
+			arg.FromYearMonth, arg.ToYearMonth = yearMonth(first), yearMonth(last)
+		}
+		var err error
+		if rows, err = ls.db.query.TopMembersInMonths(ctx, arg); err != nil {
+			return nil, fmt.Errorf("TopMembersInMonths: %w", err)
+		}
+	} else {
+		rawChannelIDs, err := json.Marshal(channelIDs)
+		if err != nil {
+			return nil, err
+		}
+		start, end, err := reactedBetween(ctx, ls.db.query, period)
+		if err != nil {
+			return nil, err
+		}
+		if len(channelIDs) == 0 {
+			if err := unadjusted(ctx, ls.db.query, teamID, period); err != nil {
+				return nil, err
+			}
+		}
+		arg := gen.TopMembersBetweenParams{
+			TeamID:     teamID,
+			Start:      start,
+			End:        end,
+			ChannelIds: string(rawChannelIDs),
+			Limit:      limit,
+		}
+		if len(channelIDs) == 0 {
+			arg.AllChannels = 1
+		}
+		betweenRows, err := ls.db.query.TopMembersBetween(ctx, arg)
+		if err != nil {
+			return nil, fmt.Errorf("TopMembersBetween: %w", err)
+		}
+		for _, row := range betweenRows {
+			rows = append(rows, gen.TopMembersInMonthsRow(row))
+		}
+	}
+
+	members := make([]statsd.Member, len(rows))
+	for i, row := range rows {
+		members[i] = statsd.Member{TeamID: teamID, Date: date, SlackUID: row.SlackUid, ReceivedLikes: int(row.ReceivedLikes), ReceivedDislikes: int(row.ReceivedDislikes)}
+	}
+	return members, nil
+}
+
+// FindMonthlyTotals retrieves the reactions received within a workspace for each month from first
+// to last, including months without any. Without channels, the totals are summed from the monthly
+// counts, including adjustments, and otherwise counted from the recorded Reactions within the channels.
+// Members who opted out of the totals are left out.
+func (ls *LeaderboardService) FindMonthlyTotals(ctx context.Context, teamID string, channelIDs []string, first statsd.MonthYear, last statsd.MonthYear) ([]statsd.MonthlyTotal, error) {
+	ctx, span := tracer.Start(ctx, "LeaderboardService.FindMonthlyTotals")
+	defer span.End()
+
+	start, err := first.Time()
+	if err != nil {
+		return nil, fmt.Errorf("invalid first month %w", statsd.ErrInvalid)
+	}
+	end, err := last.Time()
+	if err != nil {
+		return nil, fmt.Errorf("invalid last month %w", statsd.ErrInvalid)
+	}
+
+	arg := gen.MonthlyTotalsParams{
+		TeamID:        teamID,
+		FromYearMonth: yearMonth(first),
+		ToYearMonth:   yearMonth(last),
+	}
+	var rows []gen.MonthlyTotalsRow
+	if len(channelIDs) == 0 {
+		if rows, err = ls.db.query.MonthlyTotals(ctx, arg); err != nil {
+			return nil, fmt.Errorf("FindMonthlyTotals MonthlyTotals: %w", err)
+		}
+	} else {
+		rawChannelIDs, err := json.Marshal(channelIDs)
+		if err != nil {
+			return nil, fmt.Errorf("FindMonthlyTotals: %w", err)
+		}
+		channelRows, err := ls.db.query.MonthlyTotalsInChannels(ctx, gen.MonthlyTotalsInChannelsParams{
+			TeamID:        arg.TeamID,
+			FromYearMonth: arg.FromYearMonth,
+			ToYearMonth:   arg.ToYearMonth,
+			ChannelIds:    string(rawChannelIDs),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("FindMonthlyTotals MonthlyTotalsInChannels: %w", err)
+		}
+		for _, row := range channelRows {
+			rows = append(rows, gen.MonthlyTotalsRow(row))
+		}
+	}
+
+	byMonth := make(map[statsd.MonthYear]gen.MonthlyTotalsRow, len(rows))
+	for _, row := range rows {
+		byMonth[statsd.MonthYear(row.MonthYear)] = row
+	}
+	var totals []statsd.MonthlyTotal
+	for t := start; !t.After(end); t = t.AddDate(0, 1, 0) {
+		date := statsd.NewMonthYear(t)
+		row := byMonth[date]
+		totals = append(totals, statsd.MonthlyTotal{Date: date, ReceivedLikes: int(row.ReceivedLikes), ReceivedDislikes: int(row.ReceivedDislikes)})
+	}
+	return totals, nil
+}
+
+// FindLeaderboardTrend retrieves up to limit members of a workspace who received the most likes
+// over a period as FindTopMembers does and compares them, as well as the reactions received within
+// the workspace, with the previous period. Members who opted out of the totals are left out of them.
+// Returns ErrInvalid for the all-time period, which has no previous period, and if either period
+// counted from the recorded Reactions starts before they have been pruned or, without channels,
+// overlaps a month with adjustments.
+func (ls *LeaderboardService) FindLeaderboardTrend(ctx context.Context, teamID string, channelIDs []string, period statsd.Period, limit int) (*statsd.LeaderboardTrend, error) {
+	ctx, span := tracer.Start(ctx, "LeaderboardService.FindLeaderboardTrend")
+	defer span.End()
+
+	if period.Kind == statsd.PeriodAllTime {
+		return nil, fmt.Errorf("all-time period has no previous period %w", statsd.ErrInvalid)
+	}
+	trend := &statsd.LeaderboardTrend{TeamID: teamID, Period: period, PrevPeriod: period.Prev(), ChannelIDs: channelIDs}
+
+	members, err := ls.topMembers(ctx, teamID, channelIDs, trend.Period, int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("FindLeaderboardTrend: %w", err)
+	}
+	// All members of the previous period are needed for the likes of those who weren't among its top members.
+	prevMembers, err := ls.topMembers(ctx, teamID, channelIDs, trend.PrevPeriod, -1)
+	if err != nil {
+		return nil, fmt.Errorf("FindLeaderboardTrend: %w", err)
+	}
+	prevRanks := make(map[string]int, len(prevMembers))
+	prevLikes := make(map[string]int, len(prevMembers))
+	for i, m := range prevMembers {
+		if i < limit {
+			prevRanks[m.SlackUID] = i + 1
+		}
+		prevLikes[m.SlackUID] = m.ReceivedLikes
+	}
+	trend.TopMembers = make([]statsd.MemberTrend, len(members))
+	for i, m := range members {
+		trend.TopMembers[i] = statsd.MemberTrend{
+			Member:            m,
+			Rank:              i + 1,
+			PrevRank:          prevRanks[m.SlackUID],
+			PrevReceivedLikes: prevLikes[m.SlackUID],
+			LikesDelta:        m.ReceivedLikes - prevLikes[m.SlackUID],
+			NewEntrant:        prevRanks[m.SlackUID] == 0,
+		}
+	}
+
+	if trend.ReceivedLikes, trend.ReceivedDislikes, err = ls.totals(ctx, teamID, channelIDs, trend.Period); err != nil {
+		return nil, fmt.Errorf("FindLeaderboardTrend: %w", err)
+	}
+	if trend.PrevReceivedLikes, trend.PrevReceivedDislikes, err = ls.totals(ctx, teamID, channelIDs, trend.PrevPeriod); err != nil {
+		return nil, fmt.Errorf("FindLeaderboardTrend: %w", err)
+	}
+	trend.LikesGrowth = statsd.Growth(trend.PrevReceivedLikes, trend.ReceivedLikes)
+	trend.DislikesGrowth = statsd.Growth(trend.PrevReceivedDislikes, trend.ReceivedDislikes)
+	return trend, nil
+}
+
+// totals returns the reactions received within a workspace over a period. Without channels, periods
+// of whole months are summed from the monthly counts like FindMonthlyTotals does.
+func (ls *LeaderboardService) totals(ctx context.Context, teamID string, channelIDs []string, period statsd.Period) (likes int, dislikes int, err error) {
+	if first, last, ok := period.Months(); ok && len(channelIDs) == 0 {
+		rows, err := ls.db.query.MonthlyTotals(ctx, gen.MonthlyTotalsParams{
+			TeamID:        teamID,
+			FromYearMonth: yearMonth(first),
+			ToYearMonth:   yearMonth(last),
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("MonthlyTotals: %w", err)
+		}
+		for _, row := range rows {
+			likes += int(row.ReceivedLikes)
+			dislikes += int(row.ReceivedDislikes)
+		}
+		return likes, dislikes, nil
+	}
+
+	rawChannelIDs, err := json.Marshal(channelIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+	start, end, err := reactedBetween(ctx, ls.db.query, period)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(channelIDs) == 0 {
+		if err := unadjusted(ctx, ls.db.query, teamID, period); err != nil {
+			return 0, 0, err
+		}
+	}
+	arg := gen.TotalReactionsBetweenParams{
+		TeamID:     teamID,
+		Start:      start,
+		End:        end,
+		ChannelIds: string(rawChannelIDs),
+	}
+	if len(channelIDs) == 0 {
+		arg.AllChannels = 1
+	}
+	row, err := ls.db.query.TotalReactionsBetween(ctx, arg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("TotalReactionsBetween: %w", err)
+	}
+	return int(row.ReceivedLikes), int(row.ReceivedDislikes), nil
+}
+
+// genMessageToMessage converts the reactions counted for a message to the stats message type.
+func genMessageToMessage(teamID string, row gen.MostLikedMessageRow) *statsd.Message {
+	return &statsd.Message{
+		TeamID:           teamID,
+		ChannelID:        row.ChannelID,
+		MessageTS:        row.MessageTs,
+		AuthorUID:        row.AuthorUid,
+		ReceivedLikes:    int(row.ReceivedLikes),
+		ReceivedDislikes: int(row.ReceivedDislikes),
+	}
+}
+
+// reactedBetween returns the bounds of the period in the format reaction times are stored in.
+// The all-time period is unbounded and covers the reactions which haven't been pruned.
+// Returns ErrInvalid if any other period starts before the reactions have been pruned, since
+// counting it from the remaining reactions would be partial.
+func reactedBetween(ctx context.Context, query *gen.Queries, period statsd.Period) (start string, end string, err error) {
+	if period.Kind == statsd.PeriodAllTime {
+		return "", "9999", nil
+	}
+	if cutoff, err := prunedBefore(ctx, query, retentionReactions); err != nil {
+		return "", "", err
+	} else if period.Start.Before(cutoff) {
+		return "", "", fmt.Errorf("reactions before %s have been pruned %w", cutoff.In(statsd.TimeZone).Format(time.DateOnly), statsd.ErrInvalid)
+	}
+	return period.Start.UTC().Format(time.RFC3339), period.End.UTC().Format(time.RFC3339), nil
+}
+
+// unadjusted returns ErrInvalid if a month overlapped by the period has adjustments which haven't
+// been reverted. Adjustments apply to a whole month, so a period counted from the recorded Reactions
+// can't include them and would disagree with the monthly counts.
+func unadjusted(ctx context.Context, query *gen.Queries, teamID string, period statsd.Period) error {
+	months, err := query.AdjustedMonths(ctx, gen.AdjustedMonthsParams{
+		TeamID:        teamID,
+		FromYearMonth: yearMonth(statsd.NewMonthYear(period.Start)),
+		ToYearMonth:   yearMonth(statsd.NewMonthYear(period.End.Add(-time.Nanosecond))),
+	})
+	if err != nil {
+		return fmt.Errorf("AdjustedMonths: %w", err)
+	} else if len(months) > 0 {
+		return fmt.Errorf("%s adjusted, which only periods of whole months include %w", strings.Join(months, ", "), statsd.ErrInvalid)
+	}
+	return nil
+}
+
+// yearMonth returns the month as `YYYYMM`, which unlike MonthYear sorts chronologically.
+func yearMonth(my statsd.MonthYear) string {
+	s := my.String()
+	if len(s) != len("01-2006") {
+		return s
+	}
+	return s[3:] + s[:2]
+}
@@ -0,0 +1,499 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestLeaderboardService_FindPeriodLeaderboard(t *testing.T) {
+	// Ensure periods of whole months are summed from the monthly counts.
+	t.Run("Quarter", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("04-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 3, ReceivedDislikes: 1})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 3})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U2ZN1SE2N", ReceivedLikes: 5, ReceivedDislikes: 2})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("07-2006"), SlackUID: "U2ZN1SE2N", ReceivedLikes: 9})
+		MustCreateAdjustment(t, db, &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("06-2006"), SlackUID: "U2ZN1SE2N", Metric: statsd.MetricDislikes, Delta: 1, Reason: "missed while offline", Actor: "cli:alice"})
+
+		p, err := statsd.ParsePeriod("2006-Q2", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaderboard, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", p); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.SlackUID, "U1ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedLikesMember=%v, want %v", got, want)
+		} else if got, want := leaderboard.MostReceivedLikesMember.ReceivedLikes, 6; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		} else if got, want := leaderboard.MostReceivedDislikesMember.SlackUID, "U2ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedDislikesMember=%v, want %v", got, want)
+		} else if got, want := leaderboard.MostReceivedDislikesMember.ReceivedDislikes, 3; got != want {
+			t.Fatalf("ReceivedDislikes=%v, want %v", got, want)
+		} else if leaderboard.Date != "" {
+			t.Fatalf("unexpected Date: %v", leaderboard.Date)
+		}
+	})
+
+	// Ensure the most controversial member is the one whose reactions are most divisive.
+	t.Run("MostControversialMember", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 2, ReceivedDislikes: 50})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U2ZN1SE2N", ReceivedLikes: 40, ReceivedDislikes: 35})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U3ZN1SE2N", ReceivedLikes: 60})
+
+		if leaderboard, err := ls.FindLeaderboard(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedDislikesMember.SlackUID, "U1ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedDislikesMember=%v, want %v", got, want)
+		} else if got, want := leaderboard.MostControversialMember.SlackUID, "U2ZN1SE2N"; got != want {
+			t.Fatalf("MostControversialMember=%v, want %v", got, want)
+		}
+
+		// Nobody received both likes and dislikes in the previous month.
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("04-2006"), SlackUID: "U3ZN1SE2N", ReceivedLikes: 1})
+		if leaderboard, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", MustParsePeriod(t, "04-2006")); err != nil {
+			t.Fatal(err)
+		} else if leaderboard.MostControversialMember.SlackUID != "" {
+			t.Fatalf("unexpected MostControversialMember: %#v", leaderboard.MostControversialMember)
+		}
+	})
+
+	// Ensure the all-time period sums every month.
+	t.Run("AllTime", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("12-2005"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 4})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 3, ReceivedDislikes: 1})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U2ZN1SE2N", ReceivedLikes: 6})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T2ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U3ZN1SE2N", ReceivedLikes: 9})
+
+		if leaderboard, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", statsd.NewAllTimePeriod()); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.SlackUID, "U1ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedLikesMember=%v, want %v", got, want)
+		} else if got, want := leaderboard.MostReceivedLikesMember.ReceivedLikes, 7; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+
+		// The year to date leaves out the previous year.
+		ytd := statsd.NewYearToDatePeriod(time.Date(2006, time.May, 20, 0, 0, 0, 0, time.UTC))
+		if leaderboard, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", ytd); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.SlackUID, "U2ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedLikesMember=%v, want %v", got, want)
+		}
+
+		if _, err := ls.FindPeriodLeaderboard(context.Background(), "T9ZN1SE2N", statsd.NewAllTimePeriod()); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure other periods are counted from the reactions given within them.
+	t.Run("Week", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		monday := time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(monday), ChannelID: "C1ZN1SE2N", MessageTS: "1147683600.000100", ReactorUID: "U3ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: monday})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(monday), ChannelID: "C1ZN1SE2N", MessageTS: "1147683600.000200", ReactorUID: "U3ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsDown, ReactedAt: monday})
+		// A reaction of the previous week, which is not included.
+		sunday := monday.AddDate(0, 0, -1)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(sunday), ChannelID: "C1ZN1SE2N", MessageTS: "1147597200.000100", ReactorUID: "U3ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: sunday})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(sunday), ChannelID: "C1ZN1SE2N", MessageTS: "1147597200.000100", ReactorUID: "U4ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: sunday})
+
+		p, err := statsd.NewPeriod(statsd.PeriodWeek, monday)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaderboard, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", p); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.SlackUID, "U1ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedLikesMember=%v, want %v", got, want)
+		} else if got, want := leaderboard.MostReceivedDislikesMember.SlackUID, "U2ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedDislikesMember=%v, want %v", got, want)
+		}
+
+		if _, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", p.Prev().Prev()); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure periods counted from reactions are refused if they overlap a month with adjustments, which
+	// only periods of whole months include, unless the adjustments have been reverted.
+	t.Run("Adjusted", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		// The week of Monday, May 29, 2006 overlaps May and June.
+		monday := time.Date(2006, time.May, 29, 9, 0, 0, 0, time.UTC)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(monday), ChannelID: "C1ZN1SE2N", MessageTS: "1148893200.000100", ReactorUID: "U3ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: monday})
+		adj := MustCreateAdjustment(t, db, &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("06-2006"), SlackUID: "U2ZN1SE2N", Metric: statsd.MetricLikes, Delta: 3, Reason: "missed while offline", Actor: "cli:alice"})
+
+		p, err := statsd.NewPeriod(statsd.PeriodWeek, monday)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", p); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := ls.FindTopMembers(context.Background(), "T1ZN1SE2N", nil, p, 5); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// Whole months include the adjustments and channels exclude them, so both are still counted.
+		if leaderboard, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", MustParsePeriod(t, "06-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.ReceivedLikes, 3; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+		if _, err := ls.FindChannelLeaderboard(context.Background(), "T1ZN1SE2N", []string{"C1ZN1SE2N"}, p); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := sqlite.NewAdjustmentService(db).RevertAdjustment(context.Background(), adj.ID, "cli:bob"); err != nil {
+			t.Fatal(err)
+		}
+		if leaderboard, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", p); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.SlackUID, "U1ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedLikesMember=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure periods counted from reactions which have been pruned are refused rather than partial.
+	t.Run("Pruned", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+		db.SetNow(func() time.Time { return time.Date(2006, time.May, 15, 0, 0, 0, 0, time.UTC) })
+
+		monday := time.Date(2006, time.March, 6, 9, 0, 0, 0, time.UTC)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(monday), ChannelID: "C1ZN1SE2N", MessageTS: "1141635600.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: monday})
+		if _, err := db.Prune(context.Background(), statsd.RetentionPolicy{ReactionMonths: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := statsd.NewPeriod(statsd.PeriodWeek, monday)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", p); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := ls.FindChannelLeaderboard(context.Background(), "T1ZN1SE2N", []string{"C1ZN1SE2N"}, p); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := ls.FindMessageLeaderboard(context.Background(), "T1ZN1SE2N", nil, p); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := sqlite.NewHeatmapService(db).FindHeatmap(context.Background(), "T1ZN1SE2N", nil, p); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// Weeks after the cutoff are still counted from the remaining reactions.
+		p, err = statsd.NewPeriod(statsd.PeriodWeek, time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ls.FindPeriodLeaderboard(context.Background(), "T1ZN1SE2N", p); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestLeaderboardService_FindChannelLeaderboard(t *testing.T) {
+	// Ensure only the reactions within the given channels are counted.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		reactedAt := time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: "C1ZN1SE2N", MessageTS: "1147683600.000100", ReactorUID: "U3ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: reactedAt})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: "C2ZN1SE2N", MessageTS: "1147683600.000200", ReactorUID: "U3ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsDown, ReactedAt: reactedAt})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: "C3ZN1SE2N", MessageTS: "1147683600.000300", ReactorUID: "U3ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: reactedAt})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: "C3ZN1SE2N", MessageTS: "1147683600.000300", ReactorUID: "U4ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: reactedAt})
+
+		p, err := statsd.NewMonthPeriod(statsd.MonthYear("05-2006"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaderboard, err := ls.FindChannelLeaderboard(context.Background(), "T1ZN1SE2N", []string{"C1ZN1SE2N", "C2ZN1SE2N"}, p); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.SlackUID, "U1ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedLikesMember=%v, want %v", got, want)
+		} else if got, want := leaderboard.MostReceivedDislikesMember.SlackUID, "U2ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedDislikesMember=%v, want %v", got, want)
+		} else if got, want := leaderboard.Date, statsd.MonthYear("05-2006"); got != want {
+			t.Fatalf("Date=%v, want %v", got, want)
+		}
+
+		if leaderboard, err := ls.FindChannelLeaderboard(context.Background(), "T1ZN1SE2N", []string{"C3ZN1SE2N"}, statsd.NewAllTimePeriod()); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.ReceivedLikes, 2; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+
+		if _, err := ls.FindChannelLeaderboard(context.Background(), "T1ZN1SE2N", []string{"C9ZN1SE2N"}, p); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure a channel is required.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		if _, err := sqlite.NewLeaderboardService(db).FindChannelLeaderboard(context.Background(), "T1ZN1SE2N", nil, statsd.NewAllTimePeriod()); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestLeaderboardService_FindMessageLeaderboard(t *testing.T) {
+	// Ensure the most liked and most controversial messages are found.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		reactedAt := time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC)
+		for _, r := range []struct {
+			channelID, messageTS, reactorUID, authorUID, name string
+		}{
+			// Liked by three members.
+			{"C1ZN1SE2N", "1147683600.000100", "U3ZN1SE2N", "U1ZN1SE2N", statsd.ThumbsUp},
+			{"C1ZN1SE2N", "1147683600.000100", "U4ZN1SE2N", "U1ZN1SE2N", statsd.ThumbsUp},
+			{"C1ZN1SE2N", "1147683600.000100", "U5ZN1SE2N", "U1ZN1SE2N", statsd.ThumbsUp},
+			// Divisive.
+			{"C2ZN1SE2N", "1147683600.000200", "U3ZN1SE2N", "U2ZN1SE2N", statsd.ThumbsUp},
+			{"C2ZN1SE2N", "1147683600.000200", "U4ZN1SE2N", "U2ZN1SE2N", statsd.ThumbsUp},
+			{"C2ZN1SE2N", "1147683600.000200", "U5ZN1SE2N", "U2ZN1SE2N", statsd.ThumbsDown},
+			{"C2ZN1SE2N", "1147683600.000200", "U6ZN1SE2N", "U2ZN1SE2N", statsd.ThumbsDown},
+			// Only disliked.
+			{"C2ZN1SE2N", "1147683600.000300", "U3ZN1SE2N", "U1ZN1SE2N", statsd.ThumbsDown},
+			{"C2ZN1SE2N", "1147683600.000300", "U4ZN1SE2N", "U1ZN1SE2N", statsd.ThumbsDown},
+			{"C2ZN1SE2N", "1147683600.000300", "U5ZN1SE2N", "U1ZN1SE2N", statsd.ThumbsDown},
+		} {
+			MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: r.channelID, MessageTS: r.messageTS, ReactorUID: r.reactorUID, AuthorUID: r.authorUID, Name: r.name, ReactedAt: reactedAt})
+		}
+
+		p, err := statsd.NewMonthPeriod(statsd.MonthYear("05-2006"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaderboard, err := ls.FindMessageLeaderboard(context.Background(), "T1ZN1SE2N", nil, p); err != nil {
+			t.Fatal(err)
+		} else if got, want := *leaderboard.MostLikedMessage, (statsd.Message{TeamID: "T1ZN1SE2N", ChannelID: "C1ZN1SE2N", MessageTS: "1147683600.000100", AuthorUID: "U1ZN1SE2N", ReceivedLikes: 3}); got != want {
+			t.Fatalf("MostLikedMessage=%#v, want %#v", got, want)
+		} else if leaderboard.MostControversialMessage == nil {
+			t.Fatal("expected MostControversialMessage")
+		} else if got, want := leaderboard.MostControversialMessage.MessageTS, "1147683600.000200"; got != want {
+			t.Fatalf("MostControversialMessage=%v, want %v", got, want)
+		}
+
+		// Restricted to a channel without any divisive message.
+		if leaderboard, err := ls.FindMessageLeaderboard(context.Background(), "T1ZN1SE2N", []string{"C1ZN1SE2N"}, p); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostLikedMessage.MessageTS, "1147683600.000100"; got != want {
+			t.Fatalf("MostLikedMessage=%v, want %v", got, want)
+		} else if leaderboard.MostControversialMessage != nil {
+			t.Fatalf("unexpected MostControversialMessage: %#v", leaderboard.MostControversialMessage)
+		}
+
+		if _, err := ls.FindMessageLeaderboard(context.Background(), "T1ZN1SE2N", nil, p.Prev()); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestLeaderboardService_FindTopMembers(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	ls := sqlite.NewLeaderboardService(db)
+
+	MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 5, ReceivedDislikes: 1})
+	MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U2ZN1SE2N", ReceivedLikes: 7})
+	MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U3ZN1SE2N", ReceivedLikes: 5})
+	MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U4ZN1SE2N", ReceivedDislikes: 9})
+	MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("04-2006"), SlackUID: "U4ZN1SE2N", ReceivedLikes: 20})
+
+	// Ensure members are ordered by their likes, then by fewer dislikes, leaving out those without likes.
+	t.Run("OK", func(t *testing.T) {
+		members, err := ls.FindTopMembers(context.Background(), "T1ZN1SE2N", nil, MustParsePeriod(t, "05-2006"), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, m := range members {
+			got = append(got, m.SlackUID)
+		}
+		if want := []string{"U2ZN1SE2N", "U3ZN1SE2N", "U1ZN1SE2N"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("SlackUIDs=%v, want %v", got, want)
+		} else if got, want := members[0].Date, statsd.MonthYear("05-2006"); got != want {
+			t.Fatalf("Date=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure the number of members is limited.
+	t.Run("Limit", func(t *testing.T) {
+		members, err := ls.FindTopMembers(context.Background(), "T1ZN1SE2N", nil, statsd.NewAllTimePeriod(), 1)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(members), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := members[0], (statsd.Member{TeamID: "T1ZN1SE2N", SlackUID: "U4ZN1SE2N", ReceivedLikes: 20, ReceivedDislikes: 9}); got != want {
+			t.Fatalf("Member=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure likes within channels are counted from the recorded reactions.
+	t.Run("Channels", func(t *testing.T) {
+		reactedAt := time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: "C1ZN1SE2N", MessageTS: "1147683600.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U3ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: reactedAt})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: "C2ZN1SE2N", MessageTS: "1147683600.000200", ReactorUID: "U3ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: reactedAt})
+
+		members, err := ls.FindTopMembers(context.Background(), "T1ZN1SE2N", []string{"C1ZN1SE2N"}, MustParsePeriod(t, "05-2006"), 10)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := members, []statsd.Member{{TeamID: "T1ZN1SE2N", Date: "05-2006", SlackUID: "U3ZN1SE2N", ReceivedLikes: 1}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Members=%#v, want %#v", got, want)
+		}
+	})
+}
+
+func TestLeaderboardService_FindMonthlyTotals(t *testing.T) {
+	// Ensure every month is included, even those without reactions.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("12-2005"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 2, ReceivedDislikes: 1})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("12-2005"), SlackUID: "U2ZN1SE2N", ReceivedLikes: 3})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("02-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 4})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("03-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 8})
+
+		totals, err := ls.FindMonthlyTotals(context.Background(), "T1ZN1SE2N", nil, "12-2005", "02-2006")
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := totals, []statsd.MonthlyTotal{
+			{Date: "12-2005", ReceivedLikes: 5, ReceivedDislikes: 1},
+			{Date: "01-2006"},
+			{Date: "02-2006", ReceivedLikes: 4},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Totals=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure the months must be valid.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		if _, err := sqlite.NewLeaderboardService(db).FindMonthlyTotals(context.Background(), "T1ZN1SE2N", nil, "2006-01", "02-2006"); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestLeaderboardService_FindLeaderboardTrend(t *testing.T) {
+	// Ensure top members are compared with the previous month.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		for _, m := range []*statsd.Member{
+			{TeamID: "T1ZN1SE2N", Date: "04-2006", SlackUID: "U1ZN1SE2N", ReceivedLikes: 10, ReceivedDislikes: 2},
+			{TeamID: "T1ZN1SE2N", Date: "04-2006", SlackUID: "U2ZN1SE2N", ReceivedLikes: 6},
+			{TeamID: "T1ZN1SE2N", Date: "04-2006", SlackUID: "U3ZN1SE2N", ReceivedLikes: 4},
+			{TeamID: "T1ZN1SE2N", Date: "05-2006", SlackUID: "U1ZN1SE2N", ReceivedLikes: 12, ReceivedDislikes: 1},
+			{TeamID: "T1ZN1SE2N", Date: "05-2006", SlackUID: "U3ZN1SE2N", ReceivedLikes: 8},
+			{TeamID: "T1ZN1SE2N", Date: "05-2006", SlackUID: "U2ZN1SE2N", ReceivedLikes: 3},
+		} {
+			MustCreateMember(t, db, m)
+		}
+
+		trend, err := ls.FindLeaderboardTrend(context.Background(), "T1ZN1SE2N", nil, MustParsePeriod(t, "05-2006"), 2)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := trend.PrevPeriod, MustParsePeriod(t, "04-2006"); !got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+			t.Fatalf("PrevPeriod=%v, want %v", got, want)
+		} else if got, want := trend.TopMembers, []statsd.MemberTrend{
+			{Member: statsd.Member{TeamID: "T1ZN1SE2N", Date: "05-2006", SlackUID: "U1ZN1SE2N", ReceivedLikes: 12, ReceivedDislikes: 1}, Rank: 1, PrevRank: 1, PrevReceivedLikes: 10, LikesDelta: 2},
+			// Third within the previous month, so a new entrant to the top two.
+			{Member: statsd.Member{TeamID: "T1ZN1SE2N", Date: "05-2006", SlackUID: "U3ZN1SE2N", ReceivedLikes: 8}, Rank: 2, PrevReceivedLikes: 4, LikesDelta: 4, NewEntrant: true},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("TopMembers=%#v, want %#v", got, want)
+		} else if got, want := [4]int{trend.ReceivedLikes, trend.ReceivedDislikes, trend.PrevReceivedLikes, trend.PrevReceivedDislikes}, [4]int{23, 1, 20, 2}; got != want {
+			t.Fatalf("totals=%v, want %v", got, want)
+		} else if trend.LikesGrowth == nil || *trend.LikesGrowth != 15 {
+			t.Fatalf("LikesGrowth=%v, want 15", trend.LikesGrowth)
+		} else if trend.DislikesGrowth == nil || *trend.DislikesGrowth != -50 {
+			t.Fatalf("DislikesGrowth=%v, want -50", trend.DislikesGrowth)
+		}
+	})
+
+	// Ensure weeks are compared from the recorded reactions.
+	t.Run("Week", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		// Week 20 of 2006 began on Monday, May 15.
+		for i, r := range []struct {
+			authorUID string
+			reactedAt time.Time
+		}{
+			{"U1ZN1SE2N", time.Date(2006, time.May, 15, 9, 0, 0, 0, time.UTC)},
+			{"U1ZN1SE2N", time.Date(2006, time.May, 16, 9, 0, 0, 0, time.UTC)},
+			{"U2ZN1SE2N", time.Date(2006, time.May, 10, 9, 0, 0, 0, time.UTC)},
+		} {
+			MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(r.reactedAt), ChannelID: "C1ZN1SE2N", MessageTS: fmt.Sprintf("1147683600.%06d", i+1), ReactorUID: "U3ZN1SE2N", AuthorUID: r.authorUID, Name: statsd.ThumbsUp, ReactedAt: r.reactedAt})
+		}
+
+		trend, err := ls.FindLeaderboardTrend(context.Background(), "T1ZN1SE2N", nil, MustParsePeriod(t, "2006-W20"), 5)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(trend.TopMembers), 1; got != want {
+			t.Fatalf("len(TopMembers)=%v, want %v", got, want)
+		} else if got, want := trend.TopMembers[0].NewEntrant, true; got != want {
+			t.Fatalf("NewEntrant=%v, want %v", got, want)
+		} else if got, want := trend.PrevReceivedLikes, 1; got != want {
+			t.Fatalf("PrevReceivedLikes=%v, want %v", got, want)
+		} else if trend.LikesGrowth == nil || *trend.LikesGrowth != 100 {
+			t.Fatalf("LikesGrowth=%v, want 100", trend.LikesGrowth)
+		}
+	})
+
+	// Ensure the all-time period can't be compared.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		if _, err := sqlite.NewLeaderboardService(db).FindLeaderboardTrend(context.Background(), "T1ZN1SE2N", nil, statsd.NewAllTimePeriod(), 5); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// MustParsePeriod parses a period or fails the test.
+func MustParsePeriod(tb testing.TB, s string) statsd.Period {
+	tb.Helper()
+	p, err := statsd.ParsePeriod(s, time.Now())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return p
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/ddritzenhoff/statsd"
@@ -28,15 +29,18 @@ func NewMemberService(db *DB) *MemberService {
 
 // FindMemberByID retrieves a Member by ID.
 // Returns ErrNotFound if the ID does not exist.
-func (ms *MemberService) FindMemberByID(id int) (*statsd.Member, error) {
-	tx, err := ms.db.BeginTx(context.TODO(), nil)
+func (ms *MemberService) FindMemberByID(ctx context.Context, id int) (*statsd.Member, error) {
+	ctx, span := tracer.Start(ctx, "MemberService.FindMemberByID")
+	defer span.End()
+
+	tx, err := ms.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
 	// fetch member
-	genMember, err := ms.db.query.FindMemberByID(context.TODO(), int64(id))
+	genMember, err := ms.db.query.FindMemberByID(ctx, int64(id))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, statsd.ErrNotFound
 	} else if err != nil {
@@ -46,16 +50,20 @@ func (ms *MemberService) FindMemberByID(id int) (*statsd.Member, error) {
 	return genMemberToMember(&genMember)
 }
 
-// FindMember retrives a Member by his Slack User ID, the Month, and the Year.
+// FindMember retrives a Member by his Slack team ID, Slack User ID, the Month, and the Year.
 // Returns ErrNotFound if not matches found.
-func (ms *MemberService) FindMember(SlackUID string, date statsd.MonthYear) (*statsd.Member, error) {
-	tx, err := ms.db.BeginTx(context.TODO(), nil)
+func (ms *MemberService) FindMember(ctx context.Context, teamID string, SlackUID string, date statsd.MonthYear) (*statsd.Member, error) {
+	ctx, span := tracer.Start(ctx, "MemberService.FindMember")
+	defer span.End()
+
+	tx, err := ms.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	genMember, err := ms.db.query.FindMember(context.TODO(), gen.FindMemberParams{
+	genMember, err := ms.db.query.FindMember(ctx, gen.FindMemberParams{
+		TeamID:    teamID,
 		SlackUid:  SlackUID,
 		MonthYear: date.String(),
 	})
@@ -69,8 +77,11 @@ func (ms *MemberService) FindMember(SlackUID string, date statsd.MonthYear) (*st
 }
 
 // CreateMember creates a new Member.
-func (ms *MemberService) CreateMember(m *statsd.Member) error {
-	tx, err := ms.db.BeginTx(context.TODO(), nil)
+func (ms *MemberService) CreateMember(ctx context.Context, m *statsd.Member) error {
+	ctx, span := tracer.Start(ctx, "MemberService.CreateMember")
+	defer span.End()
+
+	tx, err := ms.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -87,9 +98,10 @@ func (ms *MemberService) CreateMember(m *statsd.Member) error {
 	m.CreatedAt = tx.now
 	m.UpdatedAt = m.CreatedAt
 
-	genMem, err := ms.db.query.CreateMember(context.TODO(), gen.CreateMemberParams{
-		SlackUid:  m.SlackUID,
+	genMem, err := ms.db.query.CreateMember(ctx, gen.CreateMemberParams{
+		TeamID:    m.TeamID,
 		MonthYear: m.Date.String(),
+		SlackUid:  m.SlackUID,
 		CreatedAt: m.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt: m.UpdatedAt.UTC().Format(time.RFC3339),
 	})
@@ -106,14 +118,17 @@ func (ms *MemberService) CreateMember(m *statsd.Member) error {
 
 // UpdateMember updates a Member.
 // Returns ErrNotFound if the member does not exist.
-func (ms *MemberService) UpdateMember(id int, upd statsd.MemberUpdate) (*statsd.Member, error) {
-	tx, err := ms.db.BeginTx(context.TODO(), nil)
+func (ms *MemberService) UpdateMember(ctx context.Context, id int, upd statsd.MemberUpdate) (*statsd.Member, error) {
+	ctx, span := tracer.Start(ctx, "MemberService.UpdateMember")
+	defer span.End()
+
+	tx, err := ms.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("UpdateMember db.Begin: %w", err)
 	}
 	defer tx.Rollback()
 
-	m, err := ms.FindMemberByID(id)
+	m, err := ms.FindMemberByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +140,7 @@ func (ms *MemberService) UpdateMember(id int, upd statsd.MemberUpdate) (*statsd.
 		m.ReceivedDislikes = *v
 	}
 
-	genMem, err := ms.db.query.UpdateMember(context.TODO(), gen.UpdateMemberParams{
+	genMem, err := ms.db.query.UpdateMember(ctx, gen.UpdateMemberParams{
 		ReceivedLikes:    int64(m.ReceivedLikes),
 		ReceivedDislikes: int64(m.ReceivedDislikes),
 		UpdatedAt:        time.Now().UTC().Format(time.RFC3339),
@@ -142,20 +157,127 @@ func (ms *MemberService) UpdateMember(id int, upd statsd.MemberUpdate) (*statsd.
 }
 
 // DeleteMember permanently deletes a Member.
-func (ms *MemberService) DeleteMember(id int) error {
-	tx, err := ms.db.BeginTx(context.TODO(), nil)
+func (ms *MemberService) DeleteMember(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "MemberService.DeleteMember")
+	defer span.End()
+
+	tx, err := ms.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	err = ms.db.query.DeleteMember(context.TODO(), int64(id))
+	err = ms.db.query.DeleteMember(ctx, int64(id))
 	if err != nil {
 		return fmt.Errorf("DeleteMember: %w", err)
 	}
 	return nil
 }
 
+// RecomputeMembers rebuilds the received likes and dislikes of every Member of a workspace within a month from the recorded Reactions
+// and Adjustments. Returns the Members whose counts changed. If dryRun is set, the changes are reported but not saved.
+// Returns ErrInvalid if the reactions of the month have been pruned.
+func (ms *MemberService) RecomputeMembers(ctx context.Context, teamID string, date statsd.MonthYear, dryRun bool) ([]*statsd.MemberDiff, error) {
+	ctx, span := tracer.Start(ctx, "MemberService.RecomputeMembers")
+	defer span.End()
+
+	tx, err := ms.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("RecomputeMembers db.Begin: %w", err)
+	}
+	defer tx.Rollback()
+	query := newQueries(tx.Tx)
+
+	// The counts of pruned months can't be rebuilt since their reactions are gone.
+	if cutoff, err := prunedBefore(ctx, query, retentionReactions); err != nil {
+		return nil, fmt.Errorf("RecomputeMembers: %w", err)
+	} else if t, err := date.Time(); err != nil {
+		return nil, fmt.Errorf("valid date required %w", statsd.ErrInvalid)
+	} else if t.Before(cutoff) {
+		return nil, fmt.Errorf("reactions of %s have been pruned %w", date, statsd.ErrInvalid)
+	}
+
+	genMembers, err := query.ListMembers(ctx, gen.ListMembersParams{
+		TeamID:    teamID,
+		MonthYear: date.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RecomputeMembers ListMembers: %w", err)
+	}
+	counts, err := query.CountReactions(ctx, gen.CountReactionsParams{
+		TeamID:    teamID,
+		MonthYear: date.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RecomputeMembers CountReactions: %w", err)
+	}
+	adjustments, err := query.SumAdjustments(ctx, gen.SumAdjustmentsParams{
+		TeamID:    teamID,
+		MonthYear: date.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RecomputeMembers SumAdjustments: %w", err)
+	}
+
+	// Members without any recorded reactions are reset to zero.
+	diffs := make(map[string]*statsd.MemberDiff)
+	for _, m := range genMembers {
+		diffs[m.SlackUid] = &statsd.MemberDiff{
+			SlackUID:            m.SlackUid,
+			OldReceivedLikes:    int(m.ReceivedLikes),
+			OldReceivedDislikes: int(m.ReceivedDislikes),
+		}
+	}
+	for _, c := range counts {
+		d, ok := diffs[c.AuthorUid]
+		if !ok {
+			d = &statsd.MemberDiff{SlackUID: c.AuthorUid}
+			diffs[c.AuthorUid] = d
+		}
+		d.NewReceivedLikes = int(c.ReceivedLikes)
+		d.NewReceivedDislikes = int(c.ReceivedDislikes)
+	}
+	// Manual adjustments which haven't been reverted are applied on top of the reactions.
+	for _, a := range adjustments {
+		d, ok := diffs[a.SlackUid]
+		if !ok {
+			d = &statsd.MemberDiff{SlackUID: a.SlackUid}
+			diffs[a.SlackUid] = d
+		}
+		d.NewReceivedLikes = max(d.NewReceivedLikes+int(a.ReceivedLikes), 0)
+		d.NewReceivedDislikes = max(d.NewReceivedDislikes+int(a.ReceivedDislikes), 0)
+	}
+
+	var changed []*statsd.MemberDiff
+	for _, d := range diffs {
+		if d.OldReceivedLikes == d.NewReceivedLikes && d.OldReceivedDislikes == d.NewReceivedDislikes {
+			continue
+		}
+		changed = append(changed, d)
+		err := query.SetMemberReactions(ctx, gen.SetMemberReactionsParams{
+			TeamID:           teamID,
+			MonthYear:        date.String(),
+			SlackUid:         d.SlackUID,
+			ReceivedLikes:    int64(d.NewReceivedLikes),
+			ReceivedDislikes: int64(d.NewReceivedDislikes),
+			CreatedAt:        tx.now.Format(time.RFC3339),
+			UpdatedAt:        tx.now.Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("RecomputeMembers SetMemberReactions: %w", err)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].SlackUID < changed[j].SlackUID })
+
+	if dryRun {
+		return changed, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
 // genMemberToMember converts the sqlite member type to the stats member type.
 func genMemberToMember(mem *gen.Member) (*statsd.Member, error) {
 	date, err := statsd.NewMonthYearString(mem.MonthYear)
@@ -170,5 +292,5 @@ func genMemberToMember(mem *gen.Member) (*statsd.Member, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &statsd.Member{ID: int(mem.ID), Date: date, SlackUID: mem.SlackUid, ReceivedLikes: int(mem.ReceivedLikes), ReceivedDislikes: int(mem.ReceivedDislikes), CreatedAt: createdAt, UpdatedAt: updatedAt}, nil
+	return &statsd.Member{ID: int(mem.ID), TeamID: mem.TeamID, Date: date, SlackUID: mem.SlackUid, ReceivedLikes: int(mem.ReceivedLikes), ReceivedDislikes: int(mem.ReceivedDislikes), CreatedAt: createdAt, UpdatedAt: updatedAt}, nil
 }
@@ -1,6 +1,7 @@
 package sqlite_test
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -16,17 +17,18 @@ func TestMemberService_CreateMember(t *testing.T) {
 		defer MustCloseDB(t, db)
 		ms := sqlite.NewMemberService(db)
 
-		monthYear, err := statsd.NewMonthYearString("2006-05")
+		monthYear, err := statsd.NewMonthYearString("05-2006")
 		if err != nil {
 			t.Fatal(err)
 		}
 		m := &statsd.Member{
+			TeamID:   "T1ZN1SE2N",
 			Date:     monthYear,
 			SlackUID: "U1ZN1SE2N",
 		}
 
 		// Create new user & verify ID and timestamps are set.
-		if err := ms.CreateMember(m); err != nil {
+		if err := ms.CreateMember(context.Background(), m); err != nil {
 			t.Fatal(err)
 		} else if got, want := m.ID, 1; got != want {
 			t.Fatalf("ID=%v, want %v", got, want)
@@ -41,22 +43,23 @@ func TestMemberService_CreateMember(t *testing.T) {
 		}
 
 		// Create second user with email.
-		monthYear, err = statsd.NewMonthYearString("2006-05")
+		monthYear, err = statsd.NewMonthYearString("05-2006")
 		if err != nil {
 			t.Fatal(err)
 		}
 		m2 := &statsd.Member{
+			TeamID:   "T1ZN1SE2N",
 			Date:     monthYear,
 			SlackUID: "U2ZN1SE2N",
 		}
-		if err := ms.CreateMember(m2); err != nil {
+		if err := ms.CreateMember(context.Background(), m2); err != nil {
 			t.Fatal(err)
 		} else if got, want := m2.ID, 2; got != want {
 			t.Fatalf("ID=%v, want %v", got, want)
 		}
 
 		// Fetch user from database & compare.
-		if other, err := ms.FindMemberByID(1); err != nil {
+		if other, err := ms.FindMemberByID(context.Background(), 1); err != nil {
 			t.Fatal(err)
 		} else if !reflect.DeepEqual(m, other) {
 			t.Fatalf("mismatch: %#v != %#v", m, other)
@@ -68,7 +71,7 @@ func TestMemberService_CreateMember(t *testing.T) {
 		defer MustCloseDB(t, db)
 		ms := sqlite.NewMemberService(db)
 
-		if err := ms.CreateMember(&statsd.Member{}); err == nil {
+		if err := ms.CreateMember(context.Background(), &statsd.Member{TeamID: "T1ZN1SE2N"}); err == nil {
 			t.Fatal("expected error")
 		} else if !errors.Is(err, statsd.ErrInvalid) {
 			t.Fatalf("unexpected error: %#v", err)
@@ -84,13 +87,14 @@ func TestMemberService_UpdateMember(t *testing.T) {
 
 		ms := sqlite.NewMemberService(db)
 		m1 := MustCreateMember(t, db, &statsd.Member{
-			Date:     statsd.MonthYear("2006-05"),
+			TeamID:   "T1ZN1SE2N",
+			Date:     statsd.MonthYear("05-2006"),
 			SlackUID: "U2ZN1SE2N",
 		})
 
 		newReceivedLikes := 5
 		newReceivedDislikes := 23
-		m2, err := ms.UpdateMember(m1.ID, statsd.MemberUpdate{
+		m2, err := ms.UpdateMember(context.Background(), m1.ID, statsd.MemberUpdate{
 			ReceivedLikes:    &newReceivedLikes,
 			ReceivedDislikes: &newReceivedDislikes,
 		})
@@ -104,7 +108,7 @@ func TestMemberService_UpdateMember(t *testing.T) {
 		}
 
 		// Fetch user from database & compare.
-		if other, err := ms.FindMemberByID(1); err != nil {
+		if other, err := ms.FindMemberByID(context.Background(), 1); err != nil {
 			t.Fatal(err)
 		} else if !reflect.DeepEqual(m2, other) {
 			t.Fatalf("mismatch: %#v != %#v", m2, other)
@@ -118,7 +122,7 @@ func TestMemberService_FindMember(t *testing.T) {
 		db := MustOpenDB(t)
 		defer MustCloseDB(t, db)
 		ms := sqlite.NewMemberService(db)
-		if _, err := ms.FindMemberByID(1); !errors.Is(err, statsd.ErrNotFound) {
+		if _, err := ms.FindMemberByID(context.Background(), 1); !errors.Is(err, statsd.ErrNotFound) {
 			t.Fatalf("unexpected error: %#v", err)
 		}
 	})
@@ -126,7 +130,23 @@ func TestMemberService_FindMember(t *testing.T) {
 		db := MustOpenDB(t)
 		defer MustCloseDB(t, db)
 		ms := sqlite.NewMemberService(db)
-		if _, err := ms.FindMember("abc123", statsd.MonthYear("hey")); !errors.Is(err, statsd.ErrNotFound) {
+		if _, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "abc123", statsd.MonthYear("hey")); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	// Ensure a canceled context aborts the query.
+	t.Run("ErrCanceled", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ms := sqlite.NewMemberService(db)
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := ms.FindMember(ctx, "T1ZN1SE2N", "U1ZN1SE2N", statsd.MonthYear("05-2006")); !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if _, err := sqlite.NewLeaderboardService(db).FindLeaderboard(ctx, "T1ZN1SE2N", statsd.MonthYear("05-2006")); !errors.Is(err, context.Canceled) {
 			t.Fatalf("unexpected error: %#v", err)
 		}
 	})
@@ -135,8 +155,84 @@ func TestMemberService_FindMember(t *testing.T) {
 // MustCreateMember creates a member in the database. Fatal on error.
 func MustCreateMember(tb testing.TB, db *sqlite.DB, m *statsd.Member) *statsd.Member {
 	tb.Helper()
-	if err := sqlite.NewMemberService(db).CreateMember(m); err != nil {
+	// Members are created without reactions, so the given counts are set afterwards.
+	likes, dislikes := m.ReceivedLikes, m.ReceivedDislikes
+	ms := sqlite.NewMemberService(db)
+	if err := ms.CreateMember(context.Background(), m); err != nil {
 		tb.Fatal(err)
 	}
+	if likes != 0 || dislikes != 0 {
+		updated, err := ms.UpdateMember(context.Background(), m.ID, statsd.MemberUpdate{ReceivedLikes: &likes, ReceivedDislikes: &dislikes})
+		if err != nil {
+			tb.Fatal(err)
+		}
+		*m = *updated
+	}
 	return m
 }
+
+func TestMemberService_RecomputeMembers(t *testing.T) {
+	// Ensure drifted counts are rebuilt from the recorded reactions.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ms := sqlite.NewMemberService(db)
+
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000100", ReactorUID: "U1ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000100", ReactorUID: "U3ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp})
+		m := MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N"})
+
+		// Drift both the member with reactions and the member without any.
+		m2, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", statsd.MonthYear("05-2006"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		likes, dislikes := 7, 3
+		if _, err := ms.UpdateMember(context.Background(), m2.ID, statsd.MemberUpdate{ReceivedLikes: &likes}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ms.UpdateMember(context.Background(), m.ID, statsd.MemberUpdate{ReceivedDislikes: &dislikes}); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []*statsd.MemberDiff{
+			{SlackUID: "U1ZN1SE2N", OldReceivedDislikes: 3},
+			{SlackUID: "U2ZN1SE2N", OldReceivedLikes: 7, NewReceivedLikes: 2},
+		}
+
+		// A dry run reports the changes without saving them.
+		if diffs, err := ms.RecomputeMembers(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006"), true); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(diffs, want) {
+			t.Fatalf("mismatch: %#v != %#v", diffs, want)
+		}
+		if other, err := ms.FindMemberByID(context.Background(), m2.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.ReceivedLikes, 7; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+
+		if diffs, err := ms.RecomputeMembers(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006"), false); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(diffs, want) {
+			t.Fatalf("mismatch: %#v != %#v", diffs, want)
+		}
+		if other, err := ms.FindMemberByID(context.Background(), m2.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.ReceivedLikes, 2; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+		if other, err := ms.FindMemberByID(context.Background(), m.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.ReceivedDislikes, 0; got != want {
+			t.Fatalf("ReceivedDislikes=%v, want %v", got, want)
+		}
+
+		// Nothing is left to change once the counts are rebuilt.
+		if diffs, err := ms.RecomputeMembers(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006"), false); err != nil {
+			t.Fatal(err)
+		} else if len(diffs) != 0 {
+			t.Fatalf("unexpected diffs: %#v", diffs)
+		}
+	})
+}
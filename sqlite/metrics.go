@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryDuration records the latency of every generated query by its name. For queries returning rows,
+// only the time until the first row is available is measured; see instrumentedDBTX.QueryContext.
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "statsd_db_query_duration_seconds",
+	Help:    "Latency of SQLite queries until their first row.",
+	Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+}, []string{"query"})
+
+// prunedRowsTotal counts the rows deleted by the retention policy by their table.
+var prunedRowsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "statsd_pruned_rows_total",
+	Help: "Rows deleted by the retention policy.",
+}, []string{"table"})
+
+// instrumentedDBTX wraps a database connection or transaction to trace every query and observe its latency.
+type instrumentedDBTX struct {
+	gen.DBTX
+}
+
+func (t *instrumentedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, done := startQuery(ctx, query)
+	result, err := t.DBTX.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
+}
+
+// QueryContext ends the span and observes the latency as soon as the query returns, before its rows are read.
+// The generated queries need a *sql.Rows, which can't be wrapped to report when the rows are closed, so the
+// span and latency only cover the time until the first row is available and not the scan of the result.
+func (t *instrumentedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, done := startQuery(ctx, query)
+	rows, err := t.DBTX.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (t *instrumentedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, done := startQuery(ctx, query)
+	row := t.DBTX.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
+}
+
+// startQuery starts a span for a query. The returned function ends the span and records the latency of the query.
+func startQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	name := queryName(query)
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "sqlite."+name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "sqlite"),
+		attribute.String("db.operation", name),
+	))
+	return ctx, func(err error) {
+		queryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// queryName returns the name of a generated query, which sqlc puts in the first line as `-- name: <name> :<kind>`.
+func queryName(query string) string {
+	line, _, _ := strings.Cut(query, "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return "unknown"
+	}
+	return fields[2]
+}
+
+// newQueries returns the generated queries for db with tracing and latency metrics.
+func newQueries(db gen.DBTX) *gen.Queries {
+	return gen.New(&instrumentedDBTX{DBTX: db})
+}
+
+// MonthlyTotalsCollector exposes the reactions received within the current month per workspace as Prometheus gauges.
+type MonthlyTotalsCollector struct {
+	db     *DB
+	logger *slog.Logger
+	desc   *prometheus.Desc
+}
+
+// NewMonthlyTotalsCollector returns a new instance of MonthlyTotalsCollector.
+func NewMonthlyTotalsCollector(logger *slog.Logger, db *DB) *MonthlyTotalsCollector {
+	return &MonthlyTotalsCollector{
+		db:     db,
+		logger: logger,
+		desc: prometheus.NewDesc(
+			"statsd_current_month_reactions",
+			"Reactions received by all members within the current month.",
+			[]string{"team", "metric"},
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *MonthlyTotalsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector. The totals are read from the database on every scrape.
+// Scrapes carry no context, so the query is bounded by a timeout instead.
+func (c *MonthlyTotalsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rows, err := c.db.query.SumMemberReactions(ctx, string(statsd.NewMonthYear(c.db.now())))
+	if err != nil {
+		c.logger.Error("collect monthly totals", slog.String("error", err.Error()))
+		return
+	}
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(row.ReceivedLikes), row.TeamID, "likes")
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(row.ReceivedDislikes), row.TeamID, "dislikes")
+	}
+}
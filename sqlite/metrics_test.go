@@ -0,0 +1,69 @@
+package sqlite_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMonthlyTotalsCollector(t *testing.T) {
+	// Ensure the reactions of the current month are summed per workspace.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		now := time.Now().UTC()
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(now), ChannelID: "C1", MessageTS: "1.1", ReactorUID: "U1", AuthorUID: "U2", Name: statsd.ThumbsUp, ReactedAt: now})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(now), ChannelID: "C1", MessageTS: "1.1", ReactorUID: "U3", AuthorUID: "U2", Name: statsd.ThumbsUp, ReactedAt: now})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(now), ChannelID: "C1", MessageTS: "1.2", ReactorUID: "U2", AuthorUID: "U1", Name: statsd.ThumbsDown, ReactedAt: now})
+
+		// Reactions of previous months are not included.
+		then := now.AddDate(0, -2, 0)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(then), ChannelID: "C1", MessageTS: "0.1", ReactorUID: "U1", AuthorUID: "U2", Name: statsd.ThumbsUp, ReactedAt: then})
+
+		c := sqlite.NewMonthlyTotalsCollector(slog.New(slog.NewTextHandler(io.Discard, nil)), db)
+		want := `
+# HELP statsd_current_month_reactions Reactions received by all members within the current month.
+# TYPE statsd_current_month_reactions gauge
+statsd_current_month_reactions{metric="dislikes",team="T1ZN1SE2N"} 1
+statsd_current_month_reactions{metric="likes",team="T1ZN1SE2N"} 2
+`
+		if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// Ensure members who opted out of the totals are left out.
+	t.Run("OptOut", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		now := time.Now().UTC()
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(now), ChannelID: "C1", MessageTS: "1.1", ReactorUID: "U1", AuthorUID: "U2", Name: statsd.ThumbsUp, ReactedAt: now})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(now), ChannelID: "C1", MessageTS: "1.2", ReactorUID: "U2", AuthorUID: "U1", Name: statsd.ThumbsUp, ReactedAt: now})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(now), ChannelID: "C1", MessageTS: "1.3", ReactorUID: "U2", AuthorUID: "U3", Name: statsd.ThumbsUp, ReactedAt: now})
+		ps := sqlite.NewPrivacyService(db)
+		if err := ps.OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U1", CountTotals: true}); err != nil {
+			t.Fatal(err)
+		} else if err := ps.OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U2"}); err != nil {
+			t.Fatal(err)
+		}
+
+		c := sqlite.NewMonthlyTotalsCollector(slog.New(slog.NewTextHandler(io.Discard, nil)), db)
+		want := `
+# HELP statsd_current_month_reactions Reactions received by all members within the current month.
+# TYPE statsd_current_month_reactions gauge
+statsd_current_month_reactions{metric="dislikes",team="T1ZN1SE2N"} 0
+statsd_current_month_reactions{metric="likes",team="T1ZN1SE2N"} 2
+`
+		if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
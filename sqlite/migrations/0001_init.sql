@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS members (
+    id INTEGER PRIMARY KEY,
+    month_year TEXT NOT NULL,
+    slack_uid TEXT NOT NULL,
+    received_likes INTEGER NOT NULL DEFAULT 0,
+    received_dislikes INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    UNIQUE(slack_uid, month_year)
+);
+
+CREATE TABLE IF NOT EXISTS reactions (
+    id INTEGER PRIMARY KEY,
+    month_year TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    message_ts TEXT NOT NULL,
+    reactor_uid TEXT NOT NULL,
+    author_uid TEXT NOT NULL,
+    name TEXT NOT NULL,
+    reacted_at TEXT NOT NULL,
+    UNIQUE(channel_id, message_ts, reactor_uid, name)
+);
+
+CREATE TABLE IF NOT EXISTS backfill_checkpoints (
+    channel_id TEXT NOT NULL,
+    from_month_year TEXT NOT NULL,
+    to_month_year TEXT NOT NULL,
+    cursor TEXT NOT NULL DEFAULT '',
+    done INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY(channel_id, from_month_year, to_month_year)
+);
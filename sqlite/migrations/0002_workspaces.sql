@@ -0,0 +1,67 @@
+CREATE TABLE workspaces (
+    team_id TEXT PRIMARY KEY,
+    team_name TEXT NOT NULL,
+    bot_user_id TEXT NOT NULL,
+    bot_token TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+-- Partition the existing tables by workspace. Rows recorded before multiple
+-- workspaces were supported have an empty team ID until a workspace adopts them.
+ALTER TABLE members RENAME TO members_old;
+
+CREATE TABLE members (
+    id INTEGER PRIMARY KEY,
+    team_id TEXT NOT NULL DEFAULT '',
+    month_year TEXT NOT NULL,
+    slack_uid TEXT NOT NULL,
+    received_likes INTEGER NOT NULL DEFAULT 0,
+    received_dislikes INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    UNIQUE(team_id, slack_uid, month_year)
+);
+
+INSERT INTO members (id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at)
+SELECT id, month_year, slack_uid, received_likes, received_dislikes, created_at, updated_at FROM members_old;
+
+DROP TABLE members_old;
+
+ALTER TABLE reactions RENAME TO reactions_old;
+
+CREATE TABLE reactions (
+    id INTEGER PRIMARY KEY,
+    team_id TEXT NOT NULL DEFAULT '',
+    month_year TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    message_ts TEXT NOT NULL,
+    reactor_uid TEXT NOT NULL,
+    author_uid TEXT NOT NULL,
+    name TEXT NOT NULL,
+    reacted_at TEXT NOT NULL,
+    UNIQUE(team_id, channel_id, message_ts, reactor_uid, name)
+);
+
+INSERT INTO reactions (id, month_year, channel_id, message_ts, reactor_uid, author_uid, name, reacted_at)
+SELECT id, month_year, channel_id, message_ts, reactor_uid, author_uid, name, reacted_at FROM reactions_old;
+
+DROP TABLE reactions_old;
+
+ALTER TABLE backfill_checkpoints RENAME TO backfill_checkpoints_old;
+
+CREATE TABLE backfill_checkpoints (
+    team_id TEXT NOT NULL DEFAULT '',
+    channel_id TEXT NOT NULL,
+    from_month_year TEXT NOT NULL,
+    to_month_year TEXT NOT NULL,
+    cursor TEXT NOT NULL DEFAULT '',
+    done INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY(team_id, channel_id, from_month_year, to_month_year)
+);
+
+INSERT INTO backfill_checkpoints (channel_id, from_month_year, to_month_year, cursor, done, updated_at)
+SELECT channel_id, from_month_year, to_month_year, cursor, done, updated_at FROM backfill_checkpoints_old;
+
+DROP TABLE backfill_checkpoints_old;
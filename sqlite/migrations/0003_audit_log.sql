@@ -0,0 +1,12 @@
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY,
+    team_id TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target TEXT NOT NULL DEFAULT '',
+    before_value TEXT NOT NULL DEFAULT '',
+    after_value TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX audit_log_created_at ON audit_log (created_at);
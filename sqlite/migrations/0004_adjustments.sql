@@ -0,0 +1,15 @@
+CREATE TABLE adjustments (
+    id INTEGER PRIMARY KEY,
+    team_id TEXT NOT NULL,
+    month_year TEXT NOT NULL,
+    slack_uid TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    delta INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    reverted_by TEXT NOT NULL DEFAULT '',
+    reverted_at TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX adjustments_team_id_month_year ON adjustments (team_id, month_year);
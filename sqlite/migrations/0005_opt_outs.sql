@@ -0,0 +1,7 @@
+CREATE TABLE opt_outs (
+    team_id TEXT NOT NULL,
+    slack_uid TEXT NOT NULL,
+    count_totals INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY(team_id, slack_uid)
+);
@@ -0,0 +1,6 @@
+-- Tracks up to which instant a table has been pruned so that pruned months are
+-- neither recomputed from the remaining rows nor counted twice when re-imported.
+CREATE TABLE retention (
+    name TEXT PRIMARY KEY,
+    pruned_before TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Channel leaderboards are counted from the reactions within a set of channels.
+CREATE INDEX reactions_team_id_channel_id_reacted_at ON reactions (team_id, channel_id, reacted_at);
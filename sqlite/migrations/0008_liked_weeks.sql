@@ -0,0 +1,17 @@
+-- Weekly streaks are derived from the weeks in which members received likes, which are kept
+-- apart from the reactions so that they survive pruning. Weeks are identified by the date of
+-- their Monday within the time zone at the time the likes were recorded.
+CREATE TABLE liked_weeks (
+    team_id TEXT NOT NULL,
+    slack_uid TEXT NOT NULL,
+    week TEXT NOT NULL,
+    likes INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY(team_id, slack_uid, week)
+);
+
+-- Likes recorded before are assigned to the weeks of UTC.
+INSERT INTO liked_weeks (team_id, slack_uid, week, likes)
+SELECT team_id, author_uid, date(reacted_at, '-6 days', 'weekday 1') AS week, COUNT(*)
+FROM reactions
+WHERE name = '+1'
+GROUP BY team_id, author_uid, week;
@@ -0,0 +1,199 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.PrivacyService = (*PrivacyService)(nil)
+
+// PrivacyService represents a service for managing the privacy controls of Members.
+type PrivacyService struct {
+	db *DB
+}
+
+// NewPrivacyService returns a new instance of PrivacyService.
+func NewPrivacyService(db *DB) *PrivacyService {
+	return &PrivacyService{
+		db: db,
+	}
+}
+
+// OptOut excludes a Member from leaderboards. Opting out again replaces the previous choice.
+func (ps *PrivacyService) OptOut(ctx context.Context, o *statsd.OptOut) error {
+	ctx, span := tracer.Start(ctx, "PrivacyService.OptOut")
+	defer span.End()
+
+	if o == nil {
+		return fmt.Errorf("OptOut: o reference is nil")
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+
+	o.CreatedAt = ps.db.now().UTC().Truncate(time.Second)
+	var countTotals int64
+	if o.CountTotals {
+		countTotals = 1
+	}
+	err := ps.db.query.SaveOptOut(ctx, gen.SaveOptOutParams{
+		TeamID:      o.TeamID,
+		SlackUid:    o.SlackUID,
+		CountTotals: countTotals,
+		CreatedAt:   o.CreatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("OptOut: %w", err)
+	}
+	return nil
+}
+
+// OptIn includes a Member in leaderboards again.
+// Returns ErrNotFound if the member hasn't opted out.
+func (ps *PrivacyService) OptIn(ctx context.Context, teamID string, slackUID string) error {
+	ctx, span := tracer.Start(ctx, "PrivacyService.OptIn")
+	defer span.End()
+
+	n, err := ps.db.query.DeleteOptOut(ctx, gen.DeleteOptOutParams{
+		TeamID:   teamID,
+		SlackUid: slackUID,
+	})
+	if err != nil {
+		return fmt.Errorf("OptIn: %w", err)
+	}
+	if n == 0 {
+		return statsd.ErrNotFound
+	}
+	return nil
+}
+
+// FindOptOuts retrieves the opt-outs of a workspace.
+func (ps *PrivacyService) FindOptOuts(ctx context.Context, teamID string) ([]*statsd.OptOut, error) {
+	ctx, span := tracer.Start(ctx, "PrivacyService.FindOptOuts")
+	defer span.End()
+
+	genOptOuts, err := ps.db.query.ListOptOuts(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("FindOptOuts: %w", err)
+	}
+	optOuts := make([]*statsd.OptOut, 0, len(genOptOuts))
+	for i := range genOptOuts {
+		o, err := genOptOutToOptOut(&genOptOuts[i])
+		if err != nil {
+			return nil, err
+		}
+		optOuts = append(optOuts, o)
+	}
+	return optOuts, nil
+}
+
+// ForgetMember permanently erases every row pertaining to a Member of a workspace, including
+// the reactions they gave, which are also removed from the counts of their recipients. The
+// member's Slack user ID is replaced by a pseudonym within the audit log.
+func (ps *PrivacyService) ForgetMember(ctx context.Context, teamID string, slackUID string) (*statsd.ForgetResult, error) {
+	ctx, span := tracer.Start(ctx, "PrivacyService.ForgetMember")
+	defer span.End()
+
+	if teamID == "" || slackUID == "" {
+		return nil, fmt.Errorf("slack team ID and user ID required %w", statsd.ErrInvalid)
+	}
+
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	query := newQueries(tx.Tx)
+
+	// Keep the counts of other members consistent with the reactions which remain so a
+	// later recompute doesn't change them.
+	given, err := query.CountGivenReactions(ctx, gen.CountGivenReactionsParams{
+		TeamID:   teamID,
+		SlackUid: slackUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember CountGivenReactions: %w", err)
+	}
+	for _, g := range given {
+		err := query.DecrementMemberReactions(ctx, gen.DecrementMemberReactionsParams{
+			ReceivedLikes:    g.ReceivedLikes,
+			ReceivedDislikes: g.ReceivedDislikes,
+			UpdatedAt:        tx.now.Format(time.RFC3339),
+			TeamID:           teamID,
+			SlackUid:         g.AuthorUid,
+			MonthYear:        g.MonthYear,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ForgetMember DecrementMemberReactions: %w", err)
+		}
+	}
+	likes, err := query.ListGivenLikes(ctx, gen.ListGivenLikesParams{TeamID: teamID, SlackUid: slackUID})
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember ListGivenLikes: %w", err)
+	}
+	for _, like := range likes {
+		if err := decrementLikedWeek(ctx, query, teamID, like.AuthorUid, like.ReactedAt); err != nil {
+			return nil, fmt.Errorf("ForgetMember: %w", err)
+		}
+	}
+
+	var result statsd.ForgetResult
+	reactions, err := query.DeleteMemberReactions(ctx, gen.DeleteMemberReactionsParams{TeamID: teamID, SlackUid: slackUID})
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember DeleteMemberReactions: %w", err)
+	}
+	result.Reactions = int(reactions)
+	members, err := query.DeleteMemberRows(ctx, gen.DeleteMemberRowsParams{TeamID: teamID, SlackUid: slackUID})
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember DeleteMemberRows: %w", err)
+	}
+	result.Members = int(members)
+	likedWeeks, err := query.DeleteMemberLikedWeeks(ctx, gen.DeleteMemberLikedWeeksParams{TeamID: teamID, SlackUid: slackUID})
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember DeleteMemberLikedWeeks: %w", err)
+	}
+	result.LikedWeeks = int(likedWeeks)
+	adjustments, err := query.DeleteMemberAdjustments(ctx, gen.DeleteMemberAdjustmentsParams{TeamID: teamID, SlackUid: slackUID})
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember DeleteMemberAdjustments: %w", err)
+	}
+	result.Adjustments = int(adjustments)
+	optOuts, err := query.DeleteOptOut(ctx, gen.DeleteOptOutParams{TeamID: teamID, SlackUid: slackUID})
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember DeleteOptOut: %w", err)
+	}
+	result.OptOuts = int(optOuts)
+
+	// The audit log is kept as a record of past actions, so the member is only redacted from it.
+	// Only whole occurrences of their ID are replaced, i.e. the actor slack:<uid>, the target and
+	// quoted strings within the JSON values, so that IDs which merely contain it are left alone.
+	key, err := ps.db.primaryKey()
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember: %w", err)
+	}
+	result.Pseudonym = pseudonym(key, teamID, slackUID)
+	auditEntries, err := query.RedactAuditEntries(ctx, gen.RedactAuditEntriesParams{TeamID: teamID, SlackUid: slackUID, Pseudonym: result.Pseudonym})
+	if err != nil {
+		return nil, fmt.Errorf("ForgetMember RedactAuditEntries: %w", err)
+	}
+	result.AuditEntries = int(auditEntries)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// genOptOutToOptOut converts the sqlite opt-out type to the statsd opt-out type.
+func genOptOutToOptOut(o *gen.OptOut) (*statsd.OptOut, error) {
+	createdAt, err := time.Parse(time.RFC3339, o.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &statsd.OptOut{TeamID: o.TeamID, SlackUID: o.SlackUid, CountTotals: o.CountTotals != 0, CreatedAt: createdAt}, nil
+}
@@ -0,0 +1,169 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestPrivacyService_OptOut(t *testing.T) {
+	// Ensure members who opted out are left out of the leaderboard until they opt in again.
+	t.Run("Leaderboard", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ps := sqlite.NewPrivacyService(db)
+		ls := sqlite.NewLeaderboardService(db)
+
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 9, ReceivedDislikes: 9})
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U2ZN1SE2N", ReceivedLikes: 1, ReceivedDislikes: 2})
+
+		if err := ps.OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U1ZN1SE2N", CountTotals: true}); err != nil {
+			t.Fatal(err)
+		}
+		if leaderboard, err := ls.FindLeaderboard(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.SlackUID, "U2ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedLikesMember=%v, want %v", got, want)
+		} else if got, want := leaderboard.MostReceivedDislikesMember.SlackUID, "U2ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedDislikesMember=%v, want %v", got, want)
+		}
+
+		if optOuts, err := ps.FindOptOuts(context.Background(), "T1ZN1SE2N"); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(optOuts), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if !optOuts[0].CountTotals {
+			t.Fatal("expected CountTotals")
+		}
+
+		if err := ps.OptIn(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N"); err != nil {
+			t.Fatal(err)
+		} else if err := ps.OptIn(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if leaderboard, err := ls.FindLeaderboard(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := leaderboard.MostReceivedLikesMember.SlackUID, "U1ZN1SE2N"; got != want {
+			t.Fatalf("MostReceivedLikesMember=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure the leaderboard is not found if every member opted out.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", ReceivedLikes: 1})
+		if err := sqlite.NewPrivacyService(db).OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U1ZN1SE2N"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sqlite.NewLeaderboardService(db).FindLeaderboard(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006")); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure an opt-out requires a member.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		if err := sqlite.NewPrivacyService(db).OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N"}); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestPrivacyService_ForgetMember(t *testing.T) {
+	// Ensure every row of the member is erased and the reactions they gave no longer count.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ps := sqlite.NewPrivacyService(db)
+		ms := sqlite.NewMemberService(db)
+
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000200", ReactorUID: "U1ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsDown})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000200", ReactorUID: "U3ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp})
+		MustCreateAdjustment(t, db, &statsd.Adjustment{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N", Metric: statsd.MetricLikes, Delta: 2, Reason: "missed while offline", Actor: "cli:alice"})
+		if err := ps.OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U1ZN1SE2N"}); err != nil {
+			t.Fatal(err)
+		}
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "slack:U1ZN1SE2N", Action: statsd.AuditActionOptOut, Target: "U1ZN1SE2N"})
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "http:127.0.0.1", Action: statsd.AuditActionMonthlyUpdate, Target: "C1ZN1SE2N", After: []byte(`{"mostLikes":"U2ZN1SE2N","mostDislikes":"U1ZN1SE2N"}`)})
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "cli:alice", Action: statsd.AuditActionRecompute, Target: "05-2006"})
+
+		result, err := ps.ForgetMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N")
+		if err != nil {
+			t.Fatal(err)
+		} else if want := (statsd.ForgetResult{Members: 1, Reactions: 2, LikedWeeks: 1, Adjustments: 1, OptOuts: 1, AuditEntries: 2, Pseudonym: result.Pseudonym}); *result != want {
+			t.Fatalf("mismatch: %#v != %#v", *result, want)
+		} else if !strings.HasPrefix(result.Pseudonym, "forgotten:") {
+			t.Fatalf("Pseudonym=%v", result.Pseudonym)
+		}
+
+		// No row anywhere names the member any longer, while the audit log names their pseudonym.
+		if tables, err := db.TablesContaining(context.Background(), "U1ZN1SE2N"); err != nil {
+			t.Fatal(err)
+		} else if len(tables) != 0 {
+			t.Fatalf("unexpected tables: %v", tables)
+		}
+		if entries, err := sqlite.NewAuditService(db).FindAuditEntries(context.Background(), statsd.AuditFilter{Actor: "slack:" + result.Pseudonym}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := entries[0].Target, result.Pseudonym; got != want {
+			t.Fatalf("Target=%v, want %v", got, want)
+		}
+
+		if _, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", statsd.MonthYear("05-2006")); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if m, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := m.ReceivedDislikes, 0; got != want {
+			t.Fatalf("ReceivedDislikes=%v, want %v", got, want)
+		} else if got, want := m.ReceivedLikes, 1; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+
+		// The remaining counts agree with the remaining reactions.
+		if diffs, err := ms.RecomputeMembers(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006"), true); err != nil {
+			t.Fatal(err)
+		} else if len(diffs) != 0 {
+			t.Fatalf("unexpected diffs: %#v", diffs)
+		}
+	})
+
+	// Ensure audit entries naming other members whose IDs contain the ID of the forgotten member are left alone.
+	t.Run("SimilarID", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		as := sqlite.NewAuditService(db)
+
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "slack:U1ZN1SE2N", Action: statsd.AuditActionOptOut, Target: "U1ZN1SE2N"})
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "slack:U1ZN1SE2NX", Action: statsd.AuditActionOptOut, Target: "U1ZN1SE2NX"})
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{TeamID: "T1ZN1SE2N", Actor: "http:127.0.0.1", Action: statsd.AuditActionMonthlyUpdate, Target: "C1ZN1SE2N", After: []byte(`{"mostLikes":"U1ZN1SE2NX","mostDislikes":"U1ZN1SE2N"}`)})
+
+		result, err := sqlite.NewPrivacyService(db).ForgetMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N")
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := result.AuditEntries, 2; got != want {
+			t.Fatalf("AuditEntries=%v, want %v", got, want)
+		}
+
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{Actor: "slack:U1ZN1SE2NX"}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := entries[0].Target, "U1ZN1SE2NX"; got != want {
+			t.Fatalf("Target=%v, want %v", got, want)
+		}
+		if entries, err := as.FindAuditEntries(context.Background(), statsd.AuditFilter{Action: statsd.AuditActionMonthlyUpdate}); err != nil {
+			t.Fatal(err)
+		} else if got, want := string(entries[0].After), `{"mostLikes":"U1ZN1SE2NX","mostDislikes":"`+result.Pseudonym+`"}`; got != want {
+			t.Fatalf("After=%v, want %v", got, want)
+		}
+	})
+}
@@ -4,30 +4,33 @@ WHERE id = ? LIMIT 1;
 
 -- name: FindMember :one
 SELECT * FROM members
-WHERE slack_uid = ? AND month_year = ? LIMIT 1;
+WHERE team_id = ? AND slack_uid = ? AND month_year = ? LIMIT 1;
 
 -- name: CreateMember :one
 INSERT INTO members (
+    team_id,
     month_year,
     slack_uid,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?
+    ?, ?, ?, ?, ?
 )
 RETURNING *;
 
 -- name: MostLikesReceived :one
 SELECT m.*
 FROM members m
-WHERE month_year = ?
+WHERE team_id = ? AND month_year = ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
 ORDER BY received_likes DESC
 LIMIT 1;
 
 -- name: MostDislikesReceived :one
 SELECT m.*
 FROM members m
-WHERE month_year = ?
+WHERE team_id = ? AND month_year = ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
 ORDER BY received_dislikes DESC
 LIMIT 1;
 
@@ -42,3 +45,571 @@ RETURNING *;
 -- name: DeleteMember :exec
 DELETE FROM members
 WHERE id = ?;
+
+-- name: IncrementMemberReactions :exec
+INSERT INTO members (
+    team_id,
+    month_year,
+    slack_uid,
+    received_likes,
+    received_dislikes,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(team_id, slack_uid, month_year) DO UPDATE
+SET received_likes = received_likes + excluded.received_likes,
+received_dislikes = received_dislikes + excluded.received_dislikes,
+updated_at = excluded.updated_at;
+
+-- name: DecrementMemberReactions :exec
+UPDATE members
+SET received_likes = MAX(received_likes - ?, 0),
+received_dislikes = MAX(received_dislikes - ?, 0),
+updated_at = ?
+WHERE team_id = ? AND slack_uid = ? AND month_year = ?;
+
+-- name: IncrementLikedWeek :exec
+INSERT INTO liked_weeks (
+    team_id,
+    slack_uid,
+    week,
+    likes
+) VALUES (
+    ?, ?, ?, 1
+)
+ON CONFLICT(team_id, slack_uid, week) DO UPDATE
+SET likes = likes + 1;
+
+-- name: DecrementLikedWeek :exec
+UPDATE liked_weeks
+SET likes = MAX(likes - 1, 0)
+WHERE team_id = ? AND slack_uid = ? AND week = ?;
+
+-- name: CreateReaction :one
+INSERT INTO reactions (
+    team_id,
+    month_year,
+    channel_id,
+    message_ts,
+    reactor_uid,
+    author_uid,
+    name,
+    reacted_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: DeleteReaction :one
+DELETE FROM reactions
+WHERE team_id = ? AND channel_id = ? AND message_ts = ? AND reactor_uid = ? AND name = ?
+RETURNING *;
+
+-- name: FindBackfillCheckpoint :one
+SELECT * FROM backfill_checkpoints
+WHERE team_id = ? AND channel_id = ? AND from_month_year = ? AND to_month_year = ? LIMIT 1;
+
+-- name: SaveBackfillCheckpoint :exec
+INSERT INTO backfill_checkpoints (
+    team_id,
+    channel_id,
+    from_month_year,
+    to_month_year,
+    cursor,
+    done,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(team_id, channel_id, from_month_year, to_month_year) DO UPDATE
+SET cursor = excluded.cursor,
+done = excluded.done,
+updated_at = excluded.updated_at;
+
+-- name: ListMembers :many
+SELECT * FROM members
+WHERE team_id = ? AND month_year = ?
+ORDER BY slack_uid;
+
+-- name: CountReactions :many
+SELECT author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions
+WHERE team_id = ? AND month_year = ?
+GROUP BY author_uid
+ORDER BY author_uid;
+
+-- name: SetMemberReactions :exec
+INSERT INTO members (
+    team_id,
+    month_year,
+    slack_uid,
+    received_likes,
+    received_dislikes,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(team_id, slack_uid, month_year) DO UPDATE
+SET received_likes = excluded.received_likes,
+received_dislikes = excluded.received_dislikes,
+updated_at = excluded.updated_at;
+
+-- name: FindWorkspace :one
+SELECT * FROM workspaces
+WHERE team_id = ? LIMIT 1;
+
+-- name: ListWorkspaces :many
+SELECT * FROM workspaces
+ORDER BY team_id;
+
+-- name: CountWorkspaces :one
+SELECT COUNT(*) FROM workspaces;
+
+-- name: SaveWorkspace :one
+INSERT INTO workspaces (
+    team_id,
+    team_name,
+    bot_user_id,
+    bot_token,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT(team_id) DO UPDATE
+SET team_name = excluded.team_name,
+bot_user_id = excluded.bot_user_id,
+bot_token = excluded.bot_token,
+updated_at = excluded.updated_at
+RETURNING *;
+
+-- name: DeleteWorkspace :exec
+DELETE FROM workspaces
+WHERE team_id = ?;
+
+-- name: AdoptMembers :exec
+UPDATE members
+SET team_id = ?
+WHERE team_id = '';
+
+-- name: AdoptReactions :exec
+UPDATE reactions
+SET team_id = ?
+WHERE team_id = '';
+
+-- name: AdoptBackfillCheckpoints :exec
+UPDATE backfill_checkpoints
+SET team_id = ?
+WHERE team_id = '';
+
+-- name: UpdateWorkspaceBotToken :exec
+UPDATE workspaces
+SET bot_token = ?,
+updated_at = ?
+WHERE team_id = ?;
+
+-- name: SumMemberReactions :many
+SELECT team_id,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE month_year = ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid AND o.count_totals = 0)
+GROUP BY team_id
+ORDER BY team_id;
+
+-- name: CreateAuditEntry :one
+INSERT INTO audit_log (
+    team_id,
+    actor,
+    action,
+    target,
+    before_value,
+    after_value,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ListAuditEntries :many
+SELECT * FROM audit_log
+WHERE created_at >= sqlc.arg(from_time) AND created_at < sqlc.arg(to_time)
+AND (team_id = sqlc.arg(team_id) OR sqlc.arg(team_id) = '')
+AND (actor = sqlc.arg(actor) OR sqlc.arg(actor) = '')
+AND (action = sqlc.arg(action) OR sqlc.arg(action) = '')
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+    team_id,
+    month_year,
+    slack_uid,
+    metric,
+    delta,
+    reason,
+    actor,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: FindAdjustmentByID :one
+SELECT * FROM adjustments
+WHERE id = ? LIMIT 1;
+
+-- name: ListAdjustments :many
+SELECT * FROM adjustments
+WHERE team_id = sqlc.arg(team_id)
+AND (month_year = sqlc.arg(month_year) OR sqlc.arg(month_year) = '')
+AND (slack_uid = sqlc.arg(slack_uid) OR sqlc.arg(slack_uid) = '')
+ORDER BY id;
+
+-- name: RevertAdjustment :one
+UPDATE adjustments
+SET reverted_by = ?,
+reverted_at = ?
+WHERE id = ? AND reverted_at = ''
+RETURNING *;
+
+-- name: SumAdjustments :many
+SELECT slack_uid,
+CAST(SUM(CASE WHEN metric = 'likes' THEN delta ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN metric = 'dislikes' THEN delta ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM adjustments
+WHERE team_id = ? AND month_year = ? AND reverted_at = ''
+GROUP BY slack_uid
+ORDER BY slack_uid;
+
+-- name: AdjustedMonths :many
+SELECT month_year
+FROM adjustments
+WHERE team_id = sqlc.arg(team_id) AND reverted_at = ''
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(sqlc.arg(from_year_month) AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(sqlc.arg(to_year_month) AS TEXT)
+GROUP BY month_year
+ORDER BY substr(month_year, 4, 4) || substr(month_year, 1, 2);
+
+-- name: SaveOptOut :exec
+INSERT INTO opt_outs (
+    team_id,
+    slack_uid,
+    count_totals,
+    created_at
+) VALUES (
+    ?, ?, ?, ?
+)
+ON CONFLICT(team_id, slack_uid) DO UPDATE SET
+count_totals = excluded.count_totals;
+
+-- name: DeleteOptOut :execrows
+DELETE FROM opt_outs
+WHERE team_id = ? AND slack_uid = ?;
+
+-- name: ListOptOuts :many
+SELECT * FROM opt_outs
+WHERE team_id = ?
+ORDER BY slack_uid;
+
+-- name: CountGivenReactions :many
+SELECT month_year, author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions
+WHERE team_id = sqlc.arg(team_id) AND reactor_uid = sqlc.arg(slack_uid) AND author_uid != sqlc.arg(slack_uid)
+GROUP BY month_year, author_uid
+ORDER BY month_year, author_uid;
+
+-- name: ListGivenLikes :many
+SELECT author_uid, reacted_at
+FROM reactions
+WHERE team_id = sqlc.arg(team_id) AND reactor_uid = sqlc.arg(slack_uid) AND author_uid != sqlc.arg(slack_uid) AND name = '+1'
+ORDER BY reacted_at;
+
+-- name: DeleteMemberLikedWeeks :execrows
+DELETE FROM liked_weeks
+WHERE team_id = ? AND slack_uid = ?;
+
+-- name: DeleteMemberRows :execrows
+DELETE FROM members
+WHERE team_id = ? AND slack_uid = ?;
+
+-- name: DeleteMemberReactions :execrows
+DELETE FROM reactions
+WHERE team_id = sqlc.arg(team_id) AND (author_uid = sqlc.arg(slack_uid) OR reactor_uid = sqlc.arg(slack_uid));
+
+-- name: RedactAuditEntries :execrows
+UPDATE audit_log SET
+    actor = CASE WHEN actor = 'slack:' || CAST(sqlc.arg(slack_uid) AS TEXT) THEN 'slack:' || CAST(sqlc.arg(pseudonym) AS TEXT) ELSE actor END,
+    target = CASE WHEN target = CAST(sqlc.arg(slack_uid) AS TEXT) THEN CAST(sqlc.arg(pseudonym) AS TEXT) ELSE target END,
+    before_value = replace(before_value, '"' || CAST(sqlc.arg(slack_uid) AS TEXT) || '"', '"' || CAST(sqlc.arg(pseudonym) AS TEXT) || '"'),
+    after_value = replace(after_value, '"' || CAST(sqlc.arg(slack_uid) AS TEXT) || '"', '"' || CAST(sqlc.arg(pseudonym) AS TEXT) || '"')
+WHERE team_id = sqlc.arg(team_id) AND (
+    actor = 'slack:' || CAST(sqlc.arg(slack_uid) AS TEXT) OR target = CAST(sqlc.arg(slack_uid) AS TEXT)
+    OR instr(before_value, '"' || CAST(sqlc.arg(slack_uid) AS TEXT) || '"') > 0 OR instr(after_value, '"' || CAST(sqlc.arg(slack_uid) AS TEXT) || '"') > 0
+);
+
+-- name: DeleteMemberAdjustments :execrows
+DELETE FROM adjustments
+WHERE team_id = ? AND slack_uid = ?;
+
+-- name: PruneReactions :execrows
+DELETE FROM reactions
+WHERE id IN (
+    SELECT id FROM reactions
+    WHERE substr(month_year, 4, 4) || substr(month_year, 1, 2) < CAST(sqlc.arg(before_year_month) AS TEXT)
+    LIMIT sqlc.arg(limit)
+);
+
+-- name: PruneAuditEntries :execrows
+DELETE FROM audit_log
+WHERE id IN (SELECT id FROM audit_log WHERE created_at < ? LIMIT ?);
+
+-- name: SavePrunedBefore :exec
+INSERT INTO retention (
+    name,
+    pruned_before
+) VALUES (
+    ?, ?
+)
+ON CONFLICT(name) DO UPDATE SET
+pruned_before = MAX(pruned_before, excluded.pruned_before);
+
+-- name: FindPrunedBefore :one
+SELECT pruned_before FROM retention
+WHERE name = ?;
+
+-- name: MostLikesReceivedInMonths :one
+SELECT slack_uid,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = sqlc.arg(team_id)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(sqlc.arg(from_year_month) AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(sqlc.arg(to_year_month) AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+ORDER BY received_likes DESC
+LIMIT 1;
+
+-- name: MostDislikesReceivedInMonths :one
+SELECT slack_uid,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = sqlc.arg(team_id)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(sqlc.arg(from_year_month) AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(sqlc.arg(to_year_month) AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+ORDER BY received_dislikes DESC
+LIMIT 1;
+
+-- name: MostLikesReceivedBetween :one
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+ORDER BY received_likes DESC
+LIMIT 1;
+
+-- name: MostDislikesReceivedBetween :one
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+ORDER BY received_dislikes DESC
+LIMIT 1;
+
+-- name: MostLikesReceivedInChannels :one
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT)))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+ORDER BY received_likes DESC
+LIMIT 1;
+
+-- name: MostDislikesReceivedInChannels :one
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT)))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+ORDER BY received_dislikes DESC
+LIMIT 1;
+
+-- name: MostLikedMessage :one
+SELECT channel_id, message_ts, author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND (CAST(sqlc.arg(all_channels) AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY channel_id, message_ts, author_uid
+ORDER BY received_likes DESC, received_dislikes ASC, message_ts ASC
+LIMIT 1;
+
+-- name: ControversialMessages :many
+SELECT channel_id, message_ts, author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND (CAST(sqlc.arg(all_channels) AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY channel_id, message_ts, author_uid
+HAVING received_likes > 0 AND received_dislikes > 0
+ORDER BY message_ts ASC, channel_id ASC;
+
+-- name: ControversialMembersInMonths :many
+SELECT slack_uid,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = sqlc.arg(team_id)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(sqlc.arg(from_year_month) AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(sqlc.arg(to_year_month) AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+HAVING received_likes > 0 AND received_dislikes > 0
+ORDER BY slack_uid ASC;
+
+-- name: ControversialMembersBetween :many
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND (CAST(sqlc.arg(all_channels) AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+HAVING received_likes > 0 AND received_dislikes > 0
+ORDER BY slack_uid ASC;
+
+-- name: ReactionEdges :many
+SELECT reactor_uid, author_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid IN (r.author_uid, r.reactor_uid))
+GROUP BY reactor_uid, author_uid
+ORDER BY reactor_uid ASC, author_uid ASC;
+
+-- name: ListMemberUIDs :many
+SELECT slack_uid
+FROM members m
+WHERE team_id = ?
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+ORDER BY slack_uid ASC;
+
+-- name: CountReactionsPerQuarterHour :many
+SELECT CAST(substr(reacted_at, 1, 14) || printf('%02d', CAST(substr(reacted_at, 15, 2) AS INTEGER) / 15 * 15) AS TEXT) AS quarter_hour,
+CAST(COUNT(*) AS INTEGER) AS reactions
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND (CAST(sqlc.arg(all_channels) AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid AND o.count_totals = 0)
+GROUP BY quarter_hour
+ORDER BY quarter_hour ASC;
+
+-- name: TopMembersInMonths :many
+SELECT slack_uid,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = sqlc.arg(team_id)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(sqlc.arg(from_year_month) AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(sqlc.arg(to_year_month) AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+GROUP BY slack_uid
+HAVING received_likes > 0
+ORDER BY received_likes DESC, received_dislikes ASC, slack_uid ASC
+LIMIT sqlc.arg(limit);
+
+-- name: TopMembersBetween :many
+SELECT author_uid AS slack_uid,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND (CAST(sqlc.arg(all_channels) AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid)
+GROUP BY author_uid
+HAVING received_likes > 0
+ORDER BY received_likes DESC, received_dislikes ASC, slack_uid ASC
+LIMIT sqlc.arg(limit);
+
+-- name: MonthlyTotals :many
+SELECT month_year,
+CAST(SUM(received_likes) AS INTEGER) AS received_likes,
+CAST(SUM(received_dislikes) AS INTEGER) AS received_dislikes
+FROM members m
+WHERE team_id = sqlc.arg(team_id)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(sqlc.arg(from_year_month) AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(sqlc.arg(to_year_month) AS TEXT)
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid AND o.count_totals = 0)
+GROUP BY month_year;
+
+-- name: MonthlyTotalsInChannels :many
+SELECT month_year,
+CAST(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END) AS INTEGER) AS received_likes,
+CAST(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) >= CAST(sqlc.arg(from_year_month) AS TEXT)
+AND substr(month_year, 4, 4) || substr(month_year, 1, 2) <= CAST(sqlc.arg(to_year_month) AS TEXT)
+AND channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT)))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid AND o.count_totals = 0)
+GROUP BY month_year;
+
+-- name: TotalReactionsBetween :one
+SELECT CAST(COALESCE(SUM(CASE WHEN name = '+1' THEN 1 ELSE 0 END), 0) AS INTEGER) AS received_likes,
+CAST(COALESCE(SUM(CASE WHEN name = '-1' THEN 1 ELSE 0 END), 0) AS INTEGER) AS received_dislikes
+FROM reactions r
+WHERE team_id = sqlc.arg(team_id) AND reacted_at >= sqlc.arg(start) AND reacted_at < sqlc.arg(end)
+AND (CAST(sqlc.arg(all_channels) AS INTEGER) = 1 OR channel_id IN (SELECT value FROM json_each(CAST(sqlc.arg(channel_ids) AS TEXT))))
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = r.team_id AND o.slack_uid = r.author_uid AND o.count_totals = 0);
+
+-- name: ListLikedMembers :many
+SELECT month_year, slack_uid
+FROM members m
+WHERE team_id = ? AND received_likes > 0
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = m.team_id AND o.slack_uid = m.slack_uid)
+ORDER BY substr(month_year, 4, 4) || substr(month_year, 1, 2) ASC, received_likes DESC, received_dislikes ASC, slack_uid ASC;
+
+-- name: ListLikedWeeks :many
+SELECT slack_uid, week
+FROM liked_weeks w
+WHERE team_id = ? AND likes > 0
+AND NOT EXISTS (SELECT 1 FROM opt_outs o WHERE o.team_id = w.team_id AND o.slack_uid = w.slack_uid)
+ORDER BY week ASC;
+
+-- name: ListMemberLikedWeeks :many
+SELECT week
+FROM liked_weeks
+WHERE team_id = ? AND slack_uid = ? AND likes > 0
+ORDER BY week ASC;
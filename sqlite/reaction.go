@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.ReactionService = (*ReactionService)(nil)
+
+// ReactionService represents a service for recording Reactions.
+type ReactionService struct {
+	db *DB
+}
+
+// NewReactionService returns a new instance of ReactionService.
+func NewReactionService(db *DB) *ReactionService {
+	return &ReactionService{
+		db: db,
+	}
+}
+
+// CreateReaction records a Reaction and adds it to the received likes or dislikes of its author,
+// as well as to the likes of the week in which it was given. Returns ErrConflict if the reaction has already been recorded or falls within a pruned month,
+// whose reactions are only kept within the aggregated counts.
+func (rs *ReactionService) CreateReaction(ctx context.Context, r *statsd.Reaction) error {
+	ctx, span := tracer.Start(ctx, "ReactionService.CreateReaction")
+	defer span.End()
+
+	if r == nil {
+		return fmt.Errorf("CreateReaction: r reference is nil")
+	}
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := rs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	query := newQueries(tx.Tx)
+
+	if cutoff, err := prunedBefore(ctx, query, retentionReactions); err != nil {
+		return fmt.Errorf("CreateReaction: %w", err)
+	} else if t, err := r.Date.Time(); err == nil && t.Before(cutoff) {
+		return statsd.ErrConflict
+	}
+
+	genReaction, err := query.CreateReaction(ctx, gen.CreateReactionParams{
+		TeamID:     r.TeamID,
+		MonthYear:  r.Date.String(),
+		ChannelID:  r.ChannelID,
+		MessageTs:  r.MessageTS,
+		ReactorUid: r.ReactorUID,
+		AuthorUid:  r.AuthorUID,
+		Name:       r.Name,
+		ReactedAt:  r.ReactedAt.UTC().Format(time.RFC3339),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return statsd.ErrConflict
+	} else if err != nil {
+		return fmt.Errorf("CreateReaction: %w", err)
+	}
+
+	likes, dislikes := reactionCounts(r.Name)
+	err = query.IncrementMemberReactions(ctx, gen.IncrementMemberReactionsParams{
+		TeamID:           r.TeamID,
+		MonthYear:        r.Date.String(),
+		SlackUid:         r.AuthorUID,
+		ReceivedLikes:    likes,
+		ReceivedDislikes: dislikes,
+		CreatedAt:        tx.now.Format(time.RFC3339),
+		UpdatedAt:        tx.now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("CreateReaction IncrementMemberReactions: %w", err)
+	}
+	if r.Name == statsd.ThumbsUp {
+		week, err := likedWeek(r.ReactedAt)
+		if err != nil {
+			return fmt.Errorf("CreateReaction: %w", err)
+		}
+		err = query.IncrementLikedWeek(ctx, gen.IncrementLikedWeekParams{TeamID: r.TeamID, SlackUid: r.AuthorUID, Week: week})
+		if err != nil {
+			return fmt.Errorf("CreateReaction IncrementLikedWeek: %w", err)
+		}
+	}
+
+	r.ID = int(genReaction.ID)
+	return tx.Commit()
+}
+
+// DeleteReaction removes a Reaction and subtracts it from the received likes or dislikes of its author.
+// Returns ErrNotFound if the reaction has not been recorded.
+func (rs *ReactionService) DeleteReaction(ctx context.Context, teamID string, channelID string, messageTS string, reactorUID string, name string) error {
+	ctx, span := tracer.Start(ctx, "ReactionService.DeleteReaction")
+	defer span.End()
+
+	tx, err := rs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	query := newQueries(tx.Tx)
+
+	genReaction, err := query.DeleteReaction(ctx, gen.DeleteReactionParams{
+		TeamID:     teamID,
+		ChannelID:  channelID,
+		MessageTs:  messageTS,
+		ReactorUid: reactorUID,
+		Name:       name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return statsd.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("DeleteReaction: %w", err)
+	}
+
+	likes, dislikes := reactionCounts(genReaction.Name)
+	err = query.DecrementMemberReactions(ctx, gen.DecrementMemberReactionsParams{
+		ReceivedLikes:    likes,
+		ReceivedDislikes: dislikes,
+		UpdatedAt:        tx.now.Format(time.RFC3339),
+		TeamID:           genReaction.TeamID,
+		SlackUid:         genReaction.AuthorUid,
+		MonthYear:        genReaction.MonthYear,
+	})
+	if err != nil {
+		return fmt.Errorf("DeleteReaction DecrementMemberReactions: %w", err)
+	}
+	if genReaction.Name == statsd.ThumbsUp {
+		if err := decrementLikedWeek(ctx, query, genReaction.TeamID, genReaction.AuthorUid, genReaction.ReactedAt); err != nil {
+			return fmt.Errorf("DeleteReaction: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// decrementLikedWeek subtracts a like given at reactedAt, as stored within the reactions table,
+// from the likes of its week.
+func decrementLikedWeek(ctx context.Context, query *gen.Queries, teamID string, slackUID string, reactedAt string) error {
+	t, err := time.Parse(time.RFC3339, reactedAt)
+	if err != nil {
+		return err
+	}
+	week, err := likedWeek(t)
+	if err != nil {
+		return err
+	}
+	if err := query.DecrementLikedWeek(ctx, gen.DecrementLikedWeekParams{TeamID: teamID, SlackUid: slackUID, Week: week}); err != nil {
+		return fmt.Errorf("DecrementLikedWeek: %w", err)
+	}
+	return nil
+}
+
+// reactionCounts returns the number of likes and dislikes a reaction with the given name is worth.
+func reactionCounts(name string) (likes int64, dislikes int64) {
+	switch name {
+	case statsd.ThumbsUp:
+		return 1, 0
+	case statsd.ThumbsDown:
+		return 0, 1
+	}
+	return 0, 0
+}
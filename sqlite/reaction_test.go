@@ -0,0 +1,158 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestReactionService_CreateReaction(t *testing.T) {
+	// Ensure a reaction is recorded and counted towards its author.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		rs := sqlite.NewReactionService(db)
+		ms := sqlite.NewMemberService(db)
+
+		r := &statsd.Reaction{
+			TeamID:     "T1ZN1SE2N",
+			Date:       statsd.MonthYear("05-2006"),
+			ChannelID:  "C1ZN1SE2N",
+			MessageTS:  "1147651200.000100",
+			ReactorUID: "U1ZN1SE2N",
+			AuthorUID:  "U2ZN1SE2N",
+			Name:       statsd.ThumbsUp,
+			ReactedAt:  time.Date(2006, time.May, 15, 0, 0, 0, 0, time.UTC),
+		}
+		if err := rs.CreateReaction(context.Background(), r); err != nil {
+			t.Fatal(err)
+		} else if got, want := r.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		}
+		MustCreateReaction(t, db, &statsd.Reaction{
+			TeamID:     "T1ZN1SE2N",
+			Date:       statsd.MonthYear("05-2006"),
+			ChannelID:  "C1ZN1SE2N",
+			MessageTS:  "1147651200.000100",
+			ReactorUID: "U3ZN1SE2N",
+			AuthorUID:  "U2ZN1SE2N",
+			Name:       statsd.ThumbsDown,
+			ReactedAt:  time.Date(2006, time.May, 15, 0, 0, 0, 0, time.UTC),
+		})
+
+		// The author is created on the first reaction and updated on the second.
+		if m, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := m.ReceivedLikes, 1; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		} else if got, want := m.ReceivedDislikes, 1; got != want {
+			t.Fatalf("ReceivedDislikes=%v, want %v", got, want)
+		}
+	})
+	// Ensure recording the same reaction twice does not count it twice.
+	t.Run("ErrConflict", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		rs := sqlite.NewReactionService(db)
+		ms := sqlite.NewMemberService(db)
+
+		r := statsd.Reaction{
+			TeamID:     "T1ZN1SE2N",
+			Date:       statsd.MonthYear("05-2006"),
+			ChannelID:  "C1ZN1SE2N",
+			MessageTS:  "1147651200.000100",
+			ReactorUID: "U1ZN1SE2N",
+			AuthorUID:  "U2ZN1SE2N",
+			Name:       statsd.ThumbsUp,
+		}
+		MustCreateReaction(t, db, &r)
+		if err := rs.CreateReaction(context.Background(), &r); !errors.Is(err, statsd.ErrConflict) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if m, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := m.ReceivedLikes, 1; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+	})
+	// Ensure reactions which aren't counted are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		rs := sqlite.NewReactionService(db)
+
+		if err := rs.CreateReaction(context.Background(), &statsd.Reaction{TeamID: "T1ZN1SE2N", AuthorUID: "U2ZN1SE2N", ReactorUID: "U1ZN1SE2N", Name: "tada"}); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	// Ensure a canceled context aborts the reaction without recording it.
+	t.Run("ErrCanceled", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		rs := sqlite.NewReactionService(db)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		r := &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1147651200.000100", ReactorUID: "U1ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp}
+		if err := rs.CreateReaction(ctx, r); !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if _, err := sqlite.NewMemberService(db).FindMember(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", statsd.MonthYear("05-2006")); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestReactionService_DeleteReaction(t *testing.T) {
+	// Ensure a deleted reaction is subtracted from its author.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		rs := sqlite.NewReactionService(db)
+		ms := sqlite.NewMemberService(db)
+
+		r := MustCreateReaction(t, db, &statsd.Reaction{
+			TeamID:     "T1ZN1SE2N",
+			Date:       statsd.MonthYear("05-2006"),
+			ChannelID:  "C1ZN1SE2N",
+			MessageTS:  "1147651200.000100",
+			ReactorUID: "U1ZN1SE2N",
+			AuthorUID:  "U2ZN1SE2N",
+			Name:       statsd.ThumbsDown,
+		})
+		if err := rs.DeleteReaction(context.Background(), r.TeamID, r.ChannelID, r.MessageTS, r.ReactorUID, r.Name); err != nil {
+			t.Fatal(err)
+		}
+
+		if m, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := m.ReceivedDislikes, 0; got != want {
+			t.Fatalf("ReceivedDislikes=%v, want %v", got, want)
+		}
+
+		// The reaction may be recorded again once removed.
+		MustCreateReaction(t, db, r)
+	})
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		rs := sqlite.NewReactionService(db)
+		if err := rs.DeleteReaction(context.Background(), "T1ZN1SE2N", "C1ZN1SE2N", "1147651200.000100", "U1ZN1SE2N", statsd.ThumbsUp); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// MustCreateReaction records a reaction in the database. Fatal on error.
+func MustCreateReaction(tb testing.TB, db *sqlite.DB, r *statsd.Reaction) *statsd.Reaction {
+	tb.Helper()
+	if err := sqlite.NewReactionService(db).CreateReaction(context.Background(), r); err != nil {
+		tb.Fatal(err)
+	}
+	return r
+}
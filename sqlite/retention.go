@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Names of the pruned tables tracked within the retention table.
+const (
+	retentionReactions = "reactions"
+	retentionAuditLog  = "audit_log"
+)
+
+// Prune deletes the Reactions and audit log entries older than the retention policy allows.
+// Rows are deleted in batches of policy.BatchSize, each within its own transaction, and the
+// WAL is checkpointed in between so that other writers are only blocked briefly. Once rows
+// have been deleted, the database is vacuumed to give the freed space back.
+func (db *DB) Prune(ctx context.Context, policy statsd.RetentionPolicy) (*statsd.PruneResult, error) {
+	ctx, span := tracer.Start(ctx, "DB.Prune")
+	defer span.End()
+
+	batchSize := policy.BatchSize
+	if batchSize <= 0 {
+		batchSize = statsd.DefaultPruneBatchSize
+	}
+	now := db.now()
+
+	var result statsd.PruneResult
+	var err error
+	if cutoff := statsd.RetentionCutoff(now, policy.ReactionMonths); !cutoff.IsZero() {
+		// Reactions are pruned by whole months so that they match the monthly aggregates.
+		pruneReactions := func(ctx context.Context, limit int64) (int64, error) {
+			return db.query.PruneReactions(ctx, gen.PruneReactionsParams{BeforeYearMonth: cutoff.Format("200601"), Limit: limit})
+		}
+		if result.Reactions, err = db.prune(ctx, retentionReactions, cutoff, batchSize, pruneReactions); err != nil {
+			return nil, err
+		}
+	}
+	if cutoff := statsd.RetentionCutoff(now, policy.AuditMonths); !cutoff.IsZero() {
+		pruneAuditEntries := func(ctx context.Context, limit int64) (int64, error) {
+			return db.query.PruneAuditEntries(ctx, gen.PruneAuditEntriesParams{CreatedAt: cutoff.UTC().Format(time.RFC3339), Limit: limit})
+		}
+		if result.AuditEntries, err = db.prune(ctx, retentionAuditLog, cutoff, batchSize, pruneAuditEntries); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Reactions+result.AuditEntries > 0 {
+		if err := db.Vacuum(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return &result, nil
+}
+
+// prune records cutoff as the retention of table and deletes its older rows by calling
+// deleteBatch until fewer than batchSize rows are deleted. Returns the number of deleted rows.
+func (db *DB) prune(ctx context.Context, table string, cutoff time.Time, batchSize int, deleteBatch func(ctx context.Context, limit int64) (int64, error)) (int, error) {
+	// The cutoff is recorded first so that rows of pruned months can't be recorded again
+	// while the older rows are still being deleted.
+	err := db.query.SavePrunedBefore(ctx, gen.SavePrunedBeforeParams{
+		Name:         table,
+		PrunedBefore: cutoff.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Prune SavePrunedBefore: %w", err)
+	}
+
+	var total int
+	for {
+		n, err := deleteBatch(ctx, int64(batchSize))
+		if err != nil {
+			return total, fmt.Errorf("Prune %s: %w", table, err)
+		}
+		total += int(n)
+		prunedRowsTotal.WithLabelValues(table).Add(float64(n))
+		if n < int64(batchSize) {
+			return total, nil
+		}
+		if _, err := db.db.ExecContext(ctx, `PRAGMA wal_checkpoint(PASSIVE);`); err != nil {
+			return total, fmt.Errorf("Prune wal_checkpoint: %w", err)
+		}
+	}
+}
+
+// Vacuum rebuilds the database to release unused pages and truncates the WAL afterwards.
+func (db *DB) Vacuum(ctx context.Context) error {
+	if _, err := db.db.ExecContext(ctx, `VACUUM;`); err != nil {
+		return fmt.Errorf("Vacuum: %w", err)
+	}
+	if _, err := db.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE);`); err != nil {
+		return fmt.Errorf("Vacuum wal_checkpoint: %w", err)
+	}
+	return nil
+}
+
+// prunedBefore returns the instant before which the rows of table have been pruned.
+// Returns the zero time if the table has never been pruned.
+func prunedBefore(ctx context.Context, query *gen.Queries, table string) (time.Time, error) {
+	v, err := query.FindPrunedBefore(ctx, table)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, v)
+}
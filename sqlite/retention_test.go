@@ -0,0 +1,106 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestDB_Prune(t *testing.T) {
+	// Ensure reactions and audit entries older than the retention are deleted in batches
+	// while the monthly aggregates are kept.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		db.SetNow(func() time.Time { return time.Date(2006, time.May, 15, 0, 0, 0, 0, time.UTC) })
+
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("03-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1141171200.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("03-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1141171200.000100", ReactorUID: "U3ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("03-2005"), ChannelID: "C1ZN1SE2N", MessageTS: "1109635200.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsDown})
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("04-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1143849600.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp})
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{Actor: "cli:alice", Action: statsd.AuditActionRecompute})
+
+		// Keep April and May 2006.
+		if result, err := db.Prune(context.Background(), statsd.RetentionPolicy{ReactionMonths: 2, BatchSize: 1}); err != nil {
+			t.Fatal(err)
+		} else if got, want := *result, (statsd.PruneResult{Reactions: 3}); got != want {
+			t.Fatalf("mismatch: %#v != %#v", got, want)
+		}
+
+		ms := sqlite.NewMemberService(db)
+		if m, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", statsd.MonthYear("03-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := m.ReceivedLikes, 2; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+		rs := sqlite.NewReactionService(db)
+		if err := rs.DeleteReaction(context.Background(), "T1ZN1SE2N", "C1ZN1SE2N", "1141171200.000100", "U2ZN1SE2N", statsd.ThumbsUp); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if err := rs.DeleteReaction(context.Background(), "T1ZN1SE2N", "C1ZN1SE2N", "1143849600.000100", "U2ZN1SE2N", statsd.ThumbsUp); err != nil {
+			t.Fatal(err)
+		}
+
+		// Audit entries are only pruned once they are older than the retention, which keeps May 2006.
+		db.SetNow(func() time.Time { return time.Date(2006, time.April, 30, 23, 59, 59, 0, time.UTC) })
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{Actor: "cli:alice", Action: statsd.AuditActionRecompute, Target: "outside"})
+		db.SetNow(func() time.Time { return time.Date(2006, time.May, 1, 0, 0, 0, 0, time.UTC) })
+		MustCreateAuditEntry(t, db, &statsd.AuditEntry{Actor: "cli:alice", Action: statsd.AuditActionRecompute, Target: "inside"})
+		db.SetNow(func() time.Time { return time.Date(2006, time.May, 15, 0, 0, 0, 0, time.UTC) })
+		if result, err := db.Prune(context.Background(), statsd.RetentionPolicy{AuditMonths: 1}); err != nil {
+			t.Fatal(err)
+		} else if got, want := result.AuditEntries, 1; got != want {
+			t.Fatalf("AuditEntries=%v, want %v", got, want)
+		}
+		entries, err := sqlite.NewAuditService(db).FindAuditEntries(context.Background(), statsd.AuditFilter{Action: statsd.AuditActionRecompute})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 2; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+		for _, e := range entries {
+			if e.Target == "outside" {
+				t.Fatalf("entry of %v not pruned", e.CreatedAt)
+			}
+		}
+	})
+
+	// Ensure pruned months are neither recomputed nor counted again.
+	t.Run("PrunedMonth", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		db.SetNow(func() time.Time { return time.Date(2006, time.May, 15, 0, 0, 0, 0, time.UTC) })
+
+		r := &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("03-2006"), ChannelID: "C1ZN1SE2N", MessageTS: "1141171200.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsUp}
+		MustCreateReaction(t, db, r)
+		if _, err := db.Prune(context.Background(), statsd.RetentionPolicy{ReactionMonths: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := sqlite.NewReactionService(db).CreateReaction(context.Background(), r); !errors.Is(err, statsd.ErrConflict) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if _, err := sqlite.NewMemberService(db).RecomputeMembers(context.Background(), "T1ZN1SE2N", statsd.MonthYear("03-2006"), false); !errors.Is(err, statsd.ErrInvalid) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if _, err := sqlite.NewMemberService(db).RecomputeMembers(context.Background(), "T1ZN1SE2N", statsd.MonthYear("05-2006"), false); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure nothing is pruned without a retention.
+	t.Run("KeepForever", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustCreateReaction(t, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("03-2005"), ChannelID: "C1ZN1SE2N", MessageTS: "1109635200.000100", ReactorUID: "U2ZN1SE2N", AuthorUID: "U1ZN1SE2N", Name: statsd.ThumbsDown})
+		if result, err := db.Prune(context.Background(), statsd.RetentionPolicy{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := *result, (statsd.PruneResult{}); got != want {
+			t.Fatalf("mismatch: %#v != %#v", got, want)
+		}
+	})
+}
@@ -3,20 +3,22 @@ package sqlite
 import (
 	"context"
 	"database/sql"
-	_ "embed"
+	"embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/ddritzenhoff/statsd/sqlite/gen"
 )
 
-// embed the sqlite schema within the binary to create the tables at runtime.
+// embed the sqlite migrations within the binary to create and update the tables at runtime.
 //
-//go:embed schema.sql
-var Schema string
+//go:embed migrations/*.sql
+var migrationFS embed.FS
 
 // DB represents the database connection.
 type DB struct {
@@ -26,6 +28,11 @@ type DB struct {
 	// Datasource name
 	dsn string
 
+	// Keys used to encrypt secrets, such as bot tokens, at rest. New secrets are
+	// encrypted with the first key while any of the keys may decrypt them, which
+	// allows keys to be rotated. Each key must be 32 bytes long.
+	EncryptionKeys [][]byte
+
 	// Returns the current time. Defaults to time.now().
 	// Can be mocked for tests.
 	now func() time.Time
@@ -61,7 +68,7 @@ func (db *DB) Open() (err error) {
 		return fmt.Errorf("ping: %w", err)
 	}
 
-	db.query = gen.New(db.db)
+	db.query = newQueries(db.db)
 
 	// Enable WAL as it allows multiple readers to operate while data is being written.
 	if _, err := db.db.Exec(`PRAGMA journal_mode = wal;`); err != nil {
@@ -73,14 +80,83 @@ func (db *DB) Open() (err error) {
 		return fmt.Errorf("foreign keys pragma: %w", err)
 	}
 
-	// Create tables if they don't exist.
-	if _, err := db.db.Exec(Schema); err != nil {
-		return fmt.Errorf("create tables: %w", err)
+	// Create tables if they don't exist and bring existing ones up to date.
+	if err := db.migrate(); err != nil {
+		return fmt.Errorf("migrate: %w", err)
 	}
 
 	return nil
 }
 
+// migrate applies the migrations which have not been applied yet. The number of
+// applied migrations is tracked within the user_version pragma of the database.
+func (db *DB) migrate() error {
+	names, err := migrationNames()
+	if err != nil {
+		return err
+	}
+
+	var version int
+	if err := db.db.QueryRow(`PRAGMA user_version;`).Scan(&version); err != nil {
+		return err
+	}
+	if version > len(names) {
+		return fmt.Errorf("database version %d is newer than the latest migration %d", version, len(names))
+	}
+
+	for i := version; i < len(names); i++ {
+		if err := db.applyMigration(names[i], i+1); err != nil {
+			return fmt.Errorf("%s: %w", names[i], err)
+		}
+	}
+	return nil
+}
+
+// migrationNames returns the names of the embedded migrations in the order they are applied.
+func migrationNames() ([]string, error) {
+	names, err := fs.Glob(migrationFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SchemaVersion returns the version of the database schema and the latest version known to this build.
+// Both are equal once all migrations have been applied.
+func (db *DB) SchemaVersion(ctx context.Context) (version int, latest int, err error) {
+	names, err := migrationNames()
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := db.db.QueryRowContext(ctx, `PRAGMA user_version;`).Scan(&version); err != nil {
+		return 0, 0, err
+	}
+	return version, len(names), nil
+}
+
+// applyMigration executes a single migration and records the resulting version within one transaction.
+func (db *DB) applyMigration(name string, version int) error {
+	buf, err := fs.ReadFile(migrationFS, name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(string(buf)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d;`, version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // Close closes the database connection.
 func (db *DB) Close() error {
 	// close connection.
@@ -1,11 +1,14 @@
 package sqlite_test
 
 import (
+	"context"
 	"flag"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/ddritzenhoff/statsd"
 	"github.com/ddritzenhoff/statsd/sqlite"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -18,6 +21,20 @@ func TestDB(t *testing.T) {
 	MustCloseDB(t, db)
 }
 
+// Ensure all migrations are applied when the database is opened.
+func TestDB_SchemaVersion(t *testing.T) {
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+
+	if version, latest, err := db.SchemaVersion(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if latest == 0 {
+		t.Fatal("expected migrations")
+	} else if got, want := version, latest; got != want {
+		t.Fatalf("version=%v, want %v", got, want)
+	}
+}
+
 // MustOpenDB returns a new, open DB. Fatal on error.
 func MustOpenDB(tb testing.TB) *sqlite.DB {
 	tb.Helper()
@@ -35,6 +52,7 @@ func MustOpenDB(tb testing.TB) *sqlite.DB {
 	}
 
 	db := sqlite.NewDB(dsn)
+	db.EncryptionKeys = [][]byte{[]byte("0123456789abcdef0123456789abcdef")}
 	if err := db.Open(); err != nil {
 		tb.Fatal(err)
 	}
@@ -48,3 +66,15 @@ func MustCloseDB(tb testing.TB, db *sqlite.DB) {
 		tb.Fatal(err)
 	}
 }
+
+// MustSetTimeZone sets the time zone of the organisation for the duration of a test. Fatal on error.
+func MustSetTimeZone(tb testing.TB, name string) {
+	tb.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	prev := statsd.TimeZone
+	statsd.TimeZone = loc
+	tb.Cleanup(func() { statsd.TimeZone = prev })
+}
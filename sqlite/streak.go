@@ -0,0 +1,167 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.StreakService = (*StreakService)(nil)
+
+// StreakService represents a service for recognising members who are consistently appreciated.
+// Streaks are derived from the monthly counts and the weeks in which members received likes,
+// both of which are kept when reactions are pruned, rather than stored.
+type StreakService struct {
+	db *DB
+}
+
+// NewStreakService returns a new instance of StreakService.
+func NewStreakService(db *DB) *StreakService {
+	return &StreakService{
+		db: db,
+	}
+}
+
+// FindMemberStreaks retrieves the streaks of a member of a workspace up to the month and the
+// week containing at. Top months are ranked as by LeaderboardService.FindTopMembers and weeks are
+// those in which the member received likes. Members who opted out aren't ranked, so they have no top months.
+func (ss *StreakService) FindMemberStreaks(ctx context.Context, teamID string, slackUID string, at time.Time) (*statsd.MemberStreaks, error) {
+	ctx, span := tracer.Start(ctx, "StreakService.FindMemberStreaks")
+	defer span.End()
+
+	topMonths, err := ss.topMonths(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("FindMemberStreaks: %w", err)
+	}
+	rawWeeks, err := ss.db.query.ListMemberLikedWeeks(ctx, gen.ListMemberLikedWeeksParams{TeamID: teamID, SlackUid: slackUID})
+	if err != nil {
+		return nil, fmt.Errorf("FindMemberStreaks ListMemberLikedWeeks: %w", err)
+	}
+	weeks := make([]statsd.Period, len(rawWeeks))
+	for i, rawWeek := range rawWeeks {
+		if weeks[i], err = parseLikedWeek(rawWeek); err != nil {
+			return nil, fmt.Errorf("FindMemberStreaks: %w", err)
+		}
+	}
+
+	latestMonth, latestWeek, err := latestPeriods(at)
+	if err != nil {
+		return nil, fmt.Errorf("FindMemberStreaks: %w", err)
+	}
+	return &statsd.MemberStreaks{
+		TeamID:     teamID,
+		SlackUID:   slackUID,
+		TopMonths:  statsd.NewStreak(topMonths[slackUID], latestMonth),
+		LikedWeeks: statsd.NewStreak(weeks, latestWeek),
+	}, nil
+}
+
+// FindStreaks retrieves the streaks of every member of a workspace who has one up to the month
+// and the week containing at, ordered by Slack user ID. Members who opted out are left out.
+func (ss *StreakService) FindStreaks(ctx context.Context, teamID string, at time.Time) ([]*statsd.MemberStreaks, error) {
+	ctx, span := tracer.Start(ctx, "StreakService.FindStreaks")
+	defer span.End()
+
+	topMonths, err := ss.topMonths(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("FindStreaks: %w", err)
+	}
+	rows, err := ss.db.query.ListLikedWeeks(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("FindStreaks ListLikedWeeks: %w", err)
+	}
+	weeks := make(map[string][]statsd.Period)
+	for _, row := range rows {
+		week, err := parseLikedWeek(row.Week)
+		if err != nil {
+			return nil, fmt.Errorf("FindStreaks: %w", err)
+		}
+		weeks[row.SlackUid] = append(weeks[row.SlackUid], week)
+	}
+
+	latestMonth, latestWeek, err := latestPeriods(at)
+	if err != nil {
+		return nil, fmt.Errorf("FindStreaks: %w", err)
+	}
+	slackUIDs := make(map[string]struct{}, len(topMonths)+len(weeks))
+	for slackUID := range topMonths {
+		slackUIDs[slackUID] = struct{}{}
+	}
+	for slackUID := range weeks {
+		slackUIDs[slackUID] = struct{}{}
+	}
+	var streaks []*statsd.MemberStreaks
+	for slackUID := range slackUIDs {
+		m := &statsd.MemberStreaks{
+			TeamID:     teamID,
+			SlackUID:   slackUID,
+			TopMonths:  statsd.NewStreak(topMonths[slackUID], latestMonth),
+			LikedWeeks: statsd.NewStreak(weeks[slackUID], latestWeek),
+		}
+		// Members may only have months or weeks after at.
+		if m.TopMonths.Longest > 0 || m.LikedWeeks.Longest > 0 {
+			streaks = append(streaks, m)
+		}
+	}
+	sort.Slice(streaks, func(i, j int) bool { return streaks[i].SlackUID < streaks[j].SlackUID })
+	return streaks, nil
+}
+
+// topMonths returns the months in which members were among the statsd.StreakTopMembers members
+// who received the most likes, keyed by their Slack user ID.
+func (ss *StreakService) topMonths(ctx context.Context, teamID string) (map[string][]statsd.Period, error) {
+	rows, err := ss.db.query.ListLikedMembers(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("ListLikedMembers: %w", err)
+	}
+	months := make(map[string][]statsd.Period)
+	var month string
+	var rank int
+	for _, row := range rows {
+		if row.MonthYear != month {
+			month, rank = row.MonthYear, 0
+		}
+		if rank++; rank > statsd.StreakTopMembers {
+			continue
+		}
+		p, err := statsd.NewMonthPeriod(statsd.MonthYear(row.MonthYear))
+		if err != nil {
+			return nil, err
+		}
+		months[row.SlackUid] = append(months[row.SlackUid], p)
+	}
+	return months, nil
+}
+
+// likedWeek returns the week containing t as stored within the liked_weeks table: the date of its
+// Monday within TimeZone.
+func likedWeek(t time.Time) (string, error) {
+	week, err := statsd.NewPeriod(statsd.PeriodWeek, t)
+	if err != nil {
+		return "", err
+	}
+	return week.Start.Format(time.DateOnly), nil
+}
+
+// parseLikedWeek returns the week stored within the liked_weeks table.
+func parseLikedWeek(s string) (statsd.Period, error) {
+	t, err := time.ParseInLocation(time.DateOnly, s, statsd.TimeZone)
+	if err != nil {
+		return statsd.Period{}, err
+	}
+	return statsd.NewPeriod(statsd.PeriodWeek, t)
+}
+
+// latestPeriods returns the month and the week containing at.
+func latestPeriods(at time.Time) (month statsd.Period, week statsd.Period, err error) {
+	if month, err = statsd.NewPeriod(statsd.PeriodMonth, at); err != nil {
+		return month, week, err
+	}
+	week, err = statsd.NewPeriod(statsd.PeriodWeek, at)
+	return month, week, err
+}
@@ -0,0 +1,143 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+// MustCreateStreakFixtures records three months of members, of whom U1ZN1SE2N stays in the top
+// members, and likes for U2ZN1SE2N within weeks 18, 19 and 21 of 2006 in UTC.
+func MustCreateStreakFixtures(tb testing.TB, db *sqlite.DB) {
+	tb.Helper()
+	for _, date := range []statsd.MonthYear{"03-2006", "04-2006", "05-2006"} {
+		MustCreateMember(tb, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: date, SlackUID: "U1ZN1SE2N", ReceivedLikes: 100})
+		// Six others, so that the last two of them fall out of the top five.
+		for i := 3; i <= 8; i++ {
+			MustCreateMember(tb, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: date, SlackUID: fmt.Sprintf("U%dZN1SE2N", i), ReceivedLikes: 10 - i})
+		}
+	}
+	for i, reactedAt := range []time.Time{
+		time.Date(2006, time.May, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2006, time.May, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2006, time.May, 14, 23, 0, 0, 0, time.UTC),
+		time.Date(2006, time.May, 22, 9, 0, 0, 0, time.UTC),
+	} {
+		MustCreateReaction(tb, db, &statsd.Reaction{TeamID: "T1ZN1SE2N", Date: statsd.NewMonthYear(reactedAt), ChannelID: "C1ZN1SE2N", MessageTS: fmt.Sprintf("1147683600.%06d", i+1), ReactorUID: "U3ZN1SE2N", AuthorUID: "U2ZN1SE2N", Name: statsd.ThumbsUp, ReactedAt: reactedAt})
+	}
+}
+
+func TestStreakService_FindMemberStreaks(t *testing.T) {
+	// Ensure consecutive months among the top members are counted.
+	t.Run("TopMonths", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ss := sqlite.NewStreakService(db)
+		MustCreateStreakFixtures(t, db)
+
+		streaks, err := ss.FindMemberStreaks(context.Background(), "T1ZN1SE2N", "U1ZN1SE2N", time.Date(2006, time.May, 31, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := streaks.TopMonths.Current, 3; got != want {
+			t.Fatalf("TopMonths.Current=%v, want %v", got, want)
+		} else if got, want := streaks.TopMonths.LongestEnd.String(), "05-2006"; got != want {
+			t.Fatalf("TopMonths.LongestEnd=%v, want %v", got, want)
+		}
+
+		// The seventh member by likes isn't among the top members.
+		if streaks, err := ss.FindMemberStreaks(context.Background(), "T1ZN1SE2N", "U8ZN1SE2N", time.Date(2006, time.May, 31, 0, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		} else if got, want := streaks.TopMonths, (statsd.Streak{}); got != want {
+			t.Fatalf("TopMonths=%+v, want %+v", got, want)
+		}
+	})
+
+	// Ensure consecutive weeks with likes are counted.
+	t.Run("LikedWeeks", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustCreateStreakFixtures(t, db)
+
+		streaks, err := sqlite.NewStreakService(db).FindMemberStreaks(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", time.Date(2006, time.May, 23, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := streaks.LikedWeeks.Current, 1; got != want {
+			t.Fatalf("LikedWeeks.Current=%v, want %v", got, want)
+		} else if got, want := streaks.LikedWeeks.Longest, 2; got != want {
+			t.Fatalf("LikedWeeks.Longest=%v, want %v", got, want)
+		} else if got, want := streaks.LikedWeeks.LongestEnd.String(), "2006-W19"; got != want {
+			t.Fatalf("LikedWeeks.LongestEnd=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure weeks are determined within the time zone configured when the likes are recorded.
+	t.Run("TimeZone", func(t *testing.T) {
+		MustSetTimeZone(t, "Europe/Berlin")
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustCreateStreakFixtures(t, db)
+
+		// Sunday, May 14, 23:00 UTC is already Monday of week 20 in Berlin, which precedes week 21.
+		streaks, err := sqlite.NewStreakService(db).FindMemberStreaks(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", time.Date(2006, time.May, 23, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := streaks.LikedWeeks.Current, 2; got != want {
+			t.Fatalf("LikedWeeks.Current=%v, want %v", got, want)
+		} else if got, want := streaks.LikedWeeks.LongestEnd.String(), "2006-W21"; got != want {
+			t.Fatalf("LikedWeeks.LongestEnd=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure weeks are kept once their reactions are pruned, but not once their likes are removed.
+	t.Run("Pruned", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		db.SetNow(func() time.Time { return time.Date(2006, time.July, 15, 0, 0, 0, 0, time.UTC) })
+		MustCreateStreakFixtures(t, db)
+		if err := sqlite.NewReactionService(db).DeleteReaction(context.Background(), "T1ZN1SE2N", "C1ZN1SE2N", "1147683600.000004", "U3ZN1SE2N", statsd.ThumbsUp); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Prune(context.Background(), statsd.RetentionPolicy{ReactionMonths: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		streaks, err := sqlite.NewStreakService(db).FindMemberStreaks(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", time.Date(2006, time.May, 23, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := streaks.LikedWeeks, (statsd.Streak{Longest: 2, LongestEnd: MustParsePeriod(t, "2006-W19")}); got != want {
+			t.Fatalf("LikedWeeks=%+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestStreakService_FindStreaks(t *testing.T) {
+	// Ensure every member with a streak is found, leaving out those who opted out.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		MustCreateStreakFixtures(t, db)
+		if err := sqlite.NewPrivacyService(db).OptOut(context.Background(), &statsd.OptOut{TeamID: "T1ZN1SE2N", SlackUID: "U3ZN1SE2N"}); err != nil {
+			t.Fatal(err)
+		}
+
+		streaks, err := sqlite.NewStreakService(db).FindStreaks(context.Background(), "T1ZN1SE2N", time.Date(2006, time.May, 23, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, m := range streaks {
+			got = append(got, m.SlackUID)
+		}
+		// U7ZN1SE2N joins the top five once U3ZN1SE2N opted out.
+		if want := []string{"U1ZN1SE2N", "U2ZN1SE2N", "U4ZN1SE2N", "U5ZN1SE2N", "U6ZN1SE2N", "U7ZN1SE2N"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("SlackUIDs=%v, want %v", got, want)
+		} else if got, want := streaks[1].LikedWeeks.Current, 1; got != want {
+			t.Fatalf("LikedWeeks.Current=%v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,7 @@
+package sqlite
+
+import "go.opentelemetry.io/otel"
+
+// tracer creates the spans of service calls and queries. Spans are only exported if a
+// tracer provider has been registered with otel.SetTracerProvider.
+var tracer = otel.Tracer("github.com/ddritzenhoff/statsd/sqlite")
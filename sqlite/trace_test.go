@@ -0,0 +1,47 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Ensure service calls and their queries are traced within the trace of the caller.
+func TestTracing(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	MustCreateMember(t, db, &statsd.Member{TeamID: "T1ZN1SE2N", Date: statsd.MonthYear("05-2006"), SlackUID: "U1ZN1SE2N"})
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	if _, err := sqlite.NewLeaderboardService(db).FindLeaderboard(ctx, "T1ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range sr.Ended() {
+		spans[span.Name()] = span
+	}
+	service, ok := spans["LeaderboardService.FindLeaderboard"]
+	if !ok {
+		t.Fatal("expected service span")
+	} else if got, want := service.Parent().SpanID(), parent.SpanContext().SpanID(); got != want {
+		t.Fatalf("parent=%v, want %v", got, want)
+	}
+	query, ok := spans["sqlite.MostLikesReceived"]
+	if !ok {
+		t.Fatal("expected query span")
+	} else if got, want := query.Parent().SpanID(), service.SpanContext().SpanID(); got != want {
+		t.Fatalf("parent=%v, want %v", got, want)
+	}
+}
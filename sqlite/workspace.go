@@ -0,0 +1,157 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite/gen"
+)
+
+// Ensure service implements interface.
+var _ statsd.WorkspaceService = (*WorkspaceService)(nil)
+
+// WorkspaceService represents a service for managing Workspaces.
+type WorkspaceService struct {
+	db *DB
+}
+
+// NewWorkspaceService returns a new instance of WorkspaceService.
+func NewWorkspaceService(db *DB) *WorkspaceService {
+	return &WorkspaceService{
+		db: db,
+	}
+}
+
+// FindWorkspace retrieves a Workspace by its Slack team ID.
+// Returns ErrNotFound if the workspace is not installed.
+func (ws *WorkspaceService) FindWorkspace(ctx context.Context, teamID string) (*statsd.Workspace, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.FindWorkspace")
+	defer span.End()
+
+	genWorkspace, err := ws.db.query.FindWorkspace(ctx, teamID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, statsd.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return ws.genWorkspaceToWorkspace(&genWorkspace)
+}
+
+// FindWorkspaces retrieves all installed Workspaces.
+func (ws *WorkspaceService) FindWorkspaces(ctx context.Context) ([]*statsd.Workspace, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.FindWorkspaces")
+	defer span.End()
+
+	genWorkspaces, err := ws.db.query.ListWorkspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspaces := make([]*statsd.Workspace, 0, len(genWorkspaces))
+	for i := range genWorkspaces {
+		w, err := ws.genWorkspaceToWorkspace(&genWorkspaces[i])
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, nil
+}
+
+// SaveWorkspace installs a Workspace or replaces the installation of an existing one.
+// The first Workspace to be installed adopts the data recorded before multiple workspaces were supported.
+func (ws *WorkspaceService) SaveWorkspace(ctx context.Context, w *statsd.Workspace) error {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.SaveWorkspace")
+	defer span.End()
+
+	if w == nil {
+		return fmt.Errorf("SaveWorkspace: w reference is nil")
+	}
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	botToken, err := ws.db.encrypt(w.BotToken)
+	if err != nil {
+		return fmt.Errorf("SaveWorkspace: %w", err)
+	}
+
+	tx, err := ws.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	query := newQueries(tx.Tx)
+
+	count, err := query.CountWorkspaces(ctx)
+	if err != nil {
+		return fmt.Errorf("SaveWorkspace CountWorkspaces: %w", err)
+	}
+	if count == 0 {
+		if err := adoptUnpartitioned(ctx, query, w.TeamID); err != nil {
+			return fmt.Errorf("SaveWorkspace: %w", err)
+		}
+	}
+
+	genWorkspace, err := query.SaveWorkspace(ctx, gen.SaveWorkspaceParams{
+		TeamID:    w.TeamID,
+		TeamName:  w.TeamName,
+		BotUserID: w.BotUserID,
+		BotToken:  botToken,
+		CreatedAt: tx.now.Format(time.RFC3339),
+		UpdatedAt: tx.now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("SaveWorkspace: %w", err)
+	}
+
+	if w.CreatedAt, err = time.Parse(time.RFC3339, genWorkspace.CreatedAt); err != nil {
+		return err
+	}
+	w.UpdatedAt = tx.now
+	return tx.Commit()
+}
+
+// DeleteWorkspace removes the installation of a Workspace. Its recorded data is kept.
+func (ws *WorkspaceService) DeleteWorkspace(ctx context.Context, teamID string) error {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.DeleteWorkspace")
+	defer span.End()
+
+	if err := ws.db.query.DeleteWorkspace(ctx, teamID); err != nil {
+		return fmt.Errorf("DeleteWorkspace: %w", err)
+	}
+	return nil
+}
+
+// adoptUnpartitioned assigns the rows recorded before multiple workspaces were supported to a workspace.
+func adoptUnpartitioned(ctx context.Context, query *gen.Queries, teamID string) error {
+	if err := query.AdoptMembers(ctx, teamID); err != nil {
+		return fmt.Errorf("AdoptMembers: %w", err)
+	}
+	if err := query.AdoptReactions(ctx, teamID); err != nil {
+		return fmt.Errorf("AdoptReactions: %w", err)
+	}
+	if err := query.AdoptBackfillCheckpoints(ctx, teamID); err != nil {
+		return fmt.Errorf("AdoptBackfillCheckpoints: %w", err)
+	}
+	return nil
+}
+
+// genWorkspaceToWorkspace converts the sqlite workspace type to the statsd workspace type, decrypting its bot token.
+func (ws *WorkspaceService) genWorkspaceToWorkspace(w *gen.Workspace) (*statsd.Workspace, error) {
+	botToken, err := ws.db.decrypt(w.BotToken)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := time.Parse(time.RFC3339, w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := time.Parse(time.RFC3339, w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &statsd.Workspace{TeamID: w.TeamID, TeamName: w.TeamName, BotUserID: w.BotUserID, BotToken: botToken, CreatedAt: createdAt, UpdatedAt: updatedAt}, nil
+}
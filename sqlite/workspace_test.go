@@ -0,0 +1,123 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+	"github.com/ddritzenhoff/statsd/sqlite"
+)
+
+func TestWorkspaceService_SaveWorkspace(t *testing.T) {
+	// Ensure a workspace can be installed, reinstalled and found again.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ws := sqlite.NewWorkspaceService(db)
+
+		w := &statsd.Workspace{TeamID: "T1ZN1SE2N", TeamName: "statsd", BotUserID: "U1ZN1SE2N", BotToken: "xoxb-1"}
+		if err := ws.SaveWorkspace(context.Background(), w); err != nil {
+			t.Fatal(err)
+		} else if w.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		w.BotToken = "xoxb-2"
+		if err := ws.SaveWorkspace(context.Background(), w); err != nil {
+			t.Fatal(err)
+		}
+
+		if other, err := ws.FindWorkspace(context.Background(), "T1ZN1SE2N"); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(w, other) {
+			t.Fatalf("mismatch: %#v != %#v", w, other)
+		}
+		if workspaces, err := ws.FindWorkspaces(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(workspaces), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+	})
+	// Ensure secrets can't be stored without an encryption key.
+	t.Run("ErrNoEncryptionKey", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		db.EncryptionKeys = nil
+		ws := sqlite.NewWorkspaceService(db)
+
+		if err := ws.SaveWorkspace(context.Background(), &statsd.Workspace{TeamID: "T1ZN1SE2N", BotToken: "xoxb-1"}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	// Ensure data recorded by a single workspace deployment is adopted by the first installed workspace.
+	t.Run("AdoptUnpartitioned", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "db")
+		MustExec(t, dsn, `
+			CREATE TABLE members (
+				id INTEGER PRIMARY KEY,
+				month_year TEXT NOT NULL,
+				slack_uid TEXT NOT NULL,
+				received_likes INTEGER NOT NULL DEFAULT 0,
+				received_dislikes INTEGER NOT NULL DEFAULT 0,
+				created_at TEXT NOT NULL,
+				updated_at TEXT NOT NULL,
+				UNIQUE(slack_uid, month_year)
+			);
+			INSERT INTO members (month_year, slack_uid, received_likes, created_at, updated_at)
+			VALUES ('05-2006', 'U2ZN1SE2N', 4, '2006-05-15T00:00:00Z', '2006-05-15T00:00:00Z');
+		`)
+
+		db := sqlite.NewDB(dsn)
+		db.EncryptionKeys = [][]byte{[]byte("0123456789abcdef0123456789abcdef")}
+		if err := db.Open(); err != nil {
+			t.Fatal(err)
+		}
+		defer MustCloseDB(t, db)
+		ws := sqlite.NewWorkspaceService(db)
+		ms := sqlite.NewMemberService(db)
+
+		if err := ws.SaveWorkspace(context.Background(), &statsd.Workspace{TeamID: "T1ZN1SE2N", BotToken: "xoxb-1"}); err != nil {
+			t.Fatal(err)
+		}
+		if m, err := ms.FindMember(context.Background(), "T1ZN1SE2N", "U2ZN1SE2N", statsd.MonthYear("05-2006")); err != nil {
+			t.Fatal(err)
+		} else if got, want := m.ReceivedLikes, 4; got != want {
+			t.Fatalf("ReceivedLikes=%v, want %v", got, want)
+		}
+
+		// Later workspaces start out empty.
+		if err := ws.SaveWorkspace(context.Background(), &statsd.Workspace{TeamID: "T2ZN1SE2N", BotToken: "xoxb-2"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ms.FindMember(context.Background(), "T2ZN1SE2N", "U2ZN1SE2N", statsd.MonthYear("05-2006")); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestWorkspaceService_FindWorkspace(t *testing.T) {
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		if _, err := sqlite.NewWorkspaceService(db).FindWorkspace(context.Background(), "T1ZN1SE2N"); !errors.Is(err, statsd.ErrNotFound) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// MustExec executes SQL directly against the database file at dsn. Fatal on error.
+func MustExec(tb testing.TB, dsn string, query string) {
+	tb.Helper()
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(query); err != nil {
+		tb.Fatal(err)
+	}
+}
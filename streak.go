@@ -0,0 +1,73 @@
+package statsd
+
+import (
+	"context"
+	"time"
+)
+
+// StreakTopMembers is the number of members with the most likes within a month that a member must
+// be among for the month to extend their top streak.
+const StreakTopMembers = 5
+
+// Streak represents runs of consecutive months or weeks in which a member achieved something.
+type Streak struct {
+	// Current is the length of the run which includes the latest month or week, or the one before
+	// if the latest doesn't count (yet). Zero if neither counts.
+	Current int `json:"current"`
+
+	// Longest is the length of the longest run, which ended with the month or week LongestEnd.
+	// The earliest run is kept if there are several of the same length.
+	Longest    int    `json:"longest"`
+	LongestEnd Period `json:"longestEnd"`
+}
+
+// NewStreak returns the streak formed by the given months or weeks, which must be sorted and
+// distinct, up to and including latest. Later periods are ignored.
+func NewStreak(periods []Period, latest Period) Streak {
+	var s Streak
+	var run int
+	var last Period
+	for _, p := range periods {
+		if p.Start.After(latest.Start) {
+			break
+		}
+		if run > 0 && p.Start.Equal(last.End) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > s.Longest {
+			s.Longest, s.LongestEnd = run, p
+		}
+		last = p
+	}
+	if run > 0 && (last.Start.Equal(latest.Start) || last.End.Equal(latest.Start)) {
+		s.Current = run
+	}
+	return s
+}
+
+// MemberStreaks represents the streaks of a member of a workspace up to a month and week.
+type MemberStreaks struct {
+	TeamID   string `json:"teamID"`
+	SlackUID string `json:"slackUID"`
+
+	// TopMonths counts consecutive months in which the member was among the StreakTopMembers members
+	// who received the most likes.
+	TopMonths Streak `json:"topMonths"`
+
+	// LikedWeeks counts consecutive weeks in which the member received at least one like.
+	LikedWeeks Streak `json:"likedWeeks"`
+}
+
+// StreakService represents a service for recognising members who are consistently appreciated.
+type StreakService interface {
+	// FindMemberStreaks retrieves the streaks of a member of a workspace up to the month and the
+	// week containing at. Top months are ranked as by LeaderboardService.FindTopMembers and weeks are
+	// counted from the recorded Reactions. Members who opted out aren't ranked, so they have no top months.
+	FindMemberStreaks(ctx context.Context, teamID string, slackUID string, at time.Time) (*MemberStreaks, error)
+
+	// FindStreaks retrieves the streaks of every member of a workspace who has one up to the month
+	// and the week containing at, ordered by Slack user ID. Members who opted out are left out.
+	FindStreaks(ctx context.Context, teamID string, at time.Time) ([]*MemberStreaks, error)
+}
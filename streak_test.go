@@ -0,0 +1,44 @@
+package statsd_test
+
+import (
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+func TestNewStreak(t *testing.T) {
+	periods := func(a ...string) []statsd.Period {
+		p := make([]statsd.Period, len(a))
+		for i, s := range a {
+			p[i] = MustParsePeriod(t, s)
+		}
+		return p
+	}
+
+	for _, tt := range []struct {
+		name    string
+		periods []statsd.Period
+		latest  string
+		want    statsd.Streak
+	}{
+		{"None", nil, "05-2024", statsd.Streak{}},
+		{"Current", periods("03-2024", "04-2024", "05-2024"), "05-2024", statsd.Streak{Current: 3, Longest: 3, LongestEnd: MustParsePeriod(t, "05-2024")}},
+		// The latest month may still extend the streak.
+		{"Previous", periods("03-2024", "04-2024"), "05-2024", statsd.Streak{Current: 2, Longest: 2, LongestEnd: MustParsePeriod(t, "04-2024")}},
+		{"Broken", periods("01-2024", "02-2024", "03-2024", "05-2024"), "06-2024", statsd.Streak{Current: 1, Longest: 3, LongestEnd: MustParsePeriod(t, "03-2024")}},
+		{"Ended", periods("01-2024", "02-2024"), "05-2024", statsd.Streak{Longest: 2, LongestEnd: MustParsePeriod(t, "02-2024")}},
+		// The earliest of the longest runs is kept.
+		{"Tie", periods("01-2024", "02-2024", "04-2024", "05-2024"), "05-2024", statsd.Streak{Current: 2, Longest: 2, LongestEnd: MustParsePeriod(t, "02-2024")}},
+		// Later months are ignored.
+		{"Later", periods("03-2024", "04-2024", "05-2024"), "03-2024", statsd.Streak{Current: 1, Longest: 1, LongestEnd: MustParsePeriod(t, "03-2024")}},
+		{"YearEnd", periods("12-2023", "01-2024"), "01-2024", statsd.Streak{Current: 2, Longest: 2, LongestEnd: MustParsePeriod(t, "01-2024")}},
+		{"Weeks", periods("2024-W52", "2025-W01", "2025-W02"), "2025-W03", statsd.Streak{Current: 3, Longest: 3, LongestEnd: MustParsePeriod(t, "2025-W02")}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statsd.NewStreak(tt.periods, MustParsePeriod(t, tt.latest))
+			if got.Current != tt.want.Current || got.Longest != tt.want.Longest || !got.LongestEnd.Start.Equal(tt.want.LongestEnd.Start) {
+				t.Fatalf("NewStreak=%+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
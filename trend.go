@@ -0,0 +1,56 @@
+package statsd
+
+// MemberTrend represents one of the members of a workspace who received the most likes over a
+// Period, along with their rank and the likes they received within the previous period.
+// PrevRank is 0 if the member wasn't among the top members then, which makes them a new entrant.
+type MemberTrend struct {
+	Member
+	Rank              int  `json:"rank"`
+	PrevRank          int  `json:"prevRank,omitempty"`
+	PrevReceivedLikes int  `json:"prevReceivedLikes"`
+	LikesDelta        int  `json:"likesDelta"`
+	NewEntrant        bool `json:"newEntrant"`
+}
+
+// LeaderboardTrend represents the members of a workspace who received the most likes over a
+// Period compared with the previous period of the same length, e.g. a month with the month before,
+// along with the reactions received within the whole workspace over both periods.
+// The growth is in percent and nil if nothing was received within the previous period.
+type LeaderboardTrend struct {
+	TeamID     string        `json:"teamID"`
+	Period     Period        `json:"period"`
+	PrevPeriod Period        `json:"prevPeriod"`
+	ChannelIDs []string      `json:"channelIDs,omitempty"`
+	TopMembers []MemberTrend `json:"topMembers"`
+
+	ReceivedLikes        int      `json:"receivedLikes"`
+	ReceivedDislikes     int      `json:"receivedDislikes"`
+	PrevReceivedLikes    int      `json:"prevReceivedLikes"`
+	PrevReceivedDislikes int      `json:"prevReceivedDislikes"`
+	LikesGrowth          *float64 `json:"likesGrowth"`
+	DislikesGrowth       *float64 `json:"dislikesGrowth"`
+}
+
+// NewEntrants returns the top members who weren't among the top members within the previous period.
+func (t *LeaderboardTrend) NewEntrants() []MemberTrend {
+	var a []MemberTrend
+	for _, m := range t.TopMembers {
+		if m.NewEntrant {
+			a = append(a, m)
+		}
+	}
+	return a
+}
+
+// Growth returns the change from prev to cur in percent, e.g. 50 from 10 to 15 and -20 from 10 to 8.
+// Returns nil if only prev is 0, from which any growth is unbounded, and 0 if both are.
+func Growth(prev int, cur int) *float64 {
+	if prev == 0 && cur != 0 {
+		return nil
+	}
+	var g float64
+	if prev != 0 {
+		g = float64(cur-prev) / float64(prev) * 100
+	}
+	return &g
+}
@@ -0,0 +1,48 @@
+package statsd_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ddritzenhoff/statsd"
+)
+
+func TestGrowth(t *testing.T) {
+	for _, tt := range []struct {
+		prev, cur int
+		growth    float64
+	}{
+		{10, 15, 50},
+		{10, 8, -20},
+		{10, 10, 0},
+		{4, 0, -100},
+		{3, 12, 300},
+		{0, 0, 0},
+	} {
+		if got := statsd.Growth(tt.prev, tt.cur); got == nil {
+			t.Fatalf("Growth(%d, %d)=nil, want %v", tt.prev, tt.cur, tt.growth)
+		} else if *got != tt.growth {
+			t.Fatalf("Growth(%d, %d)=%v, want %v", tt.prev, tt.cur, *got, tt.growth)
+		}
+	}
+
+	// Ensure growth from nothing is left undefined.
+	if got := statsd.Growth(0, 5); got != nil {
+		t.Fatalf("Growth(0, 5)=%v, want nil", *got)
+	}
+}
+
+func TestLeaderboardTrend_NewEntrants(t *testing.T) {
+	trend := &statsd.LeaderboardTrend{TopMembers: []statsd.MemberTrend{
+		{Member: statsd.Member{SlackUID: "U1ZN1SE2N"}, Rank: 1, PrevRank: 2},
+		{Member: statsd.Member{SlackUID: "U2ZN1SE2N"}, Rank: 2, NewEntrant: true},
+		{Member: statsd.Member{SlackUID: "U3ZN1SE2N"}, Rank: 3, PrevRank: 1},
+	}}
+	var got []string
+	for _, m := range trend.NewEntrants() {
+		got = append(got, m.SlackUID)
+	}
+	if want := []string{"U2ZN1SE2N"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("NewEntrants=%v, want %v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Workspace represents a Slack workspace (team) statsd has been installed to.
+type Workspace struct {
+	TeamID    string    `json:"teamID"`
+	TeamName  string    `json:"teamName"`
+	BotUserID string    `json:"botUserID"`
+	BotToken  string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if the workspace contains invalid fields.
+// This only performs basic validation.
+func (w *Workspace) Validate() error {
+	if w.TeamID == "" {
+		return fmt.Errorf("slack team ID required %w", ErrInvalid)
+	}
+	if w.BotToken == "" {
+		return fmt.Errorf("bot token required %w", ErrInvalid)
+	}
+	return nil
+}
+
+// WorkspaceService represents a service for managing Workspaces.
+type WorkspaceService interface {
+	// FindWorkspace retrieves a Workspace by its Slack team ID.
+	// Returns ErrNotFound if the workspace is not installed.
+	FindWorkspace(ctx context.Context, teamID string) (*Workspace, error)
+
+	// FindWorkspaces retrieves all installed Workspaces.
+	FindWorkspaces(ctx context.Context) ([]*Workspace, error)
+
+	// SaveWorkspace installs a Workspace or replaces the installation of an existing one.
+	// The first Workspace to be installed adopts the data recorded before multiple workspaces were supported.
+	SaveWorkspace(ctx context.Context, w *Workspace) error
+
+	// DeleteWorkspace removes the installation of a Workspace. Its recorded data is kept.
+	DeleteWorkspace(ctx context.Context, teamID string) error
+}